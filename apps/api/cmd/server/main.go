@@ -8,14 +8,18 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Schedule templates resolve IANA timezones even on minimal images
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jatifjr/app-unw-toefl/apps/api/internal/config"
 	"github.com/jatifjr/app-unw-toefl/apps/api/internal/handler"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/job"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
 	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
 	"github.com/jatifjr/app-unw-toefl/apps/api/internal/router"
 	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/mailer"
 )
 
 func main() {
@@ -53,15 +57,100 @@ func main() {
 
 	// Initialize repositories
 	scheduleRepo := repository.NewScheduleRepository(db)
+	scheduleTemplateRepo := repository.NewScheduleTemplateRepository(db)
+	calendarRepo := repository.NewCalendarRepository(db)
+	feedRepo := repository.NewFeedRepository(db)
+	registrationRepo := repository.NewRegistrationRepository(db)
+	waitlistRepo := repository.NewWaitlistRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	eligibilityRepo := repository.NewEligibilityRuleRepository(db)
+	lotteryRepo := repository.NewLotteryRepository(db)
+	participantRepo := repository.NewParticipantRepository(db)
+	groupRegistrationRepo := repository.NewGroupRegistrationRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	voucherRepo := repository.NewVoucherRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	bankStatementRepo := repository.NewBankStatementRepository(db)
+	attendanceRepo := repository.NewAttendanceRepository(db)
+	seatingRepo := repository.NewSeatingRepository(db)
+	proctorRepo := repository.NewProctorRepository(db)
+	testFormRepo := repository.NewTestFormRepository(db)
+	scoreRepo := repository.NewScoreRepository(db)
+	scoreAppealRepo := repository.NewScoreAppealRepository(db)
+	coordinatorRepo := repository.NewCoordinatorRepository(db)
+
+	// Initialize mailer
+	var mail mailer.Mailer = mailer.LogMailer{}
+	if cfg.SMTPHost != "" {
+		mail = mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+	}
 
 	// Initialize services
-	scheduleService := service.NewScheduleService(scheduleRepo)
+	scheduleTemplateService := service.NewScheduleTemplateService(scheduleTemplateRepo, scheduleRepo, calendarRepo)
+	calendarService := service.NewCalendarService(calendarRepo)
+	feedService := service.NewFeedService(feedRepo, scheduleRepo, cfg.AppURL)
+	registrationPolicy := service.RegistrationPolicy{
+		RescheduleCutoff: time.Duration(cfg.RescheduleCutoffHours) * time.Hour,
+		MaxReschedules:   cfg.MaxReschedules,
+		ClaimWindow:      time.Duration(cfg.WaitlistClaimHours) * time.Hour,
+		GroupInvoiceDue:  time.Duration(cfg.GroupInvoiceDueDays) * 24 * time.Hour,
+		PaymentDeadline:  time.Duration(cfg.PaymentDeadlineHours) * time.Hour,
+		CheckInOpens:     time.Duration(cfg.CheckInOpensMinutes) * time.Minute,
+		CheckInCutoff:    time.Duration(cfg.CheckInCutoffMinutes) * time.Minute,
+	}
+	notificationService := service.NewNotificationService(notificationRepo, mail, cfg.AdminEmails)
+	scheduleService := service.NewScheduleService(scheduleRepo, calendarRepo, notificationService)
+	eligibilityService := service.NewEligibilityService(eligibilityRepo)
+	waitlistService := service.NewWaitlistService(waitlistRepo, eligibilityService, notificationService, registrationPolicy)
+	registrationService := service.NewRegistrationService(registrationRepo, eligibilityService, waitlistService, notificationService, registrationPolicy)
+	lotteryService := service.NewLotteryService(lotteryRepo, scheduleRepo, eligibilityService, notificationService, registrationPolicy)
+	participantService := service.NewParticipantService(participantRepo, proctorRepo)
+	coordinatorService := service.NewCoordinatorService(coordinatorRepo)
+	groupRegistrationService := service.NewGroupRegistrationService(groupRegistrationRepo, coordinatorService, eligibilityService, waitlistService, notificationService, registrationPolicy)
+	transferAccount := model.TransferAccount{
+		BankName:      cfg.TransferBankName,
+		AccountNumber: cfg.TransferAccountNumber,
+		AccountName:   cfg.TransferAccountName,
+	}
+	paymentService := service.NewPaymentService(paymentRepo, registrationRepo, participantRepo, registrationPolicy, transferAccount)
+	voucherService := service.NewVoucherService(voucherRepo)
+	refundService := service.NewRefundService(refundRepo, paymentRepo, registrationRepo, waitlistService, notificationService, registrationPolicy)
+	bankStatementService := service.NewBankStatementService(bankStatementRepo)
+	admissionService := service.NewAdmissionService(attendanceRepo, registrationRepo, participantRepo, proctorRepo, registrationPolicy, cfg.AdmissionCardSecret)
+	seatingService := service.NewSeatingService(seatingRepo, proctorRepo)
+	proctorService := service.NewProctorService(proctorRepo, scheduleRepo, seatingRepo, cfg.ParticipantsPerProctor)
+	testFormService := service.NewTestFormService(testFormRepo)
+	scoreService := service.NewScoreService(scoreRepo, registrationRepo, participantRepo, notificationService, cfg.ScoreValidityMonths, cfg.AppURL)
+	scoreAppealService := service.NewScoreAppealService(scoreAppealRepo, scoreService, notificationService)
 
 	// Initialize handlers
-	handlers := handler.NewHandler(scheduleService)
+	handlers := handler.NewHandler(
+		scheduleService,
+		scheduleTemplateService,
+		calendarService,
+		feedService,
+		registrationService,
+		waitlistService,
+		notificationService,
+		eligibilityService,
+		lotteryService,
+		participantService,
+		groupRegistrationService,
+		paymentService,
+		voucherService,
+		refundService,
+		bankStatementService,
+		admissionService,
+		seatingService,
+		proctorService,
+		testFormService,
+		scoreService,
+		scoreAppealService,
+		coordinatorService,
+	)
 
 	// Initialize router
-	r := router.NewRouter(handlers)
+	r := router.NewRouter(handlers, cfg.JWTSecret)
 	engine := r.Setup()
 
 	// Configure CORS
@@ -107,6 +196,15 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Start background jobs
+	go job.Run(ctx, "expire-waitlist-offers", time.Minute, waitlistService.ExpireOffers)
+	go job.Run(ctx, "expire-unpaid-registrations", time.Minute, registrationService.ExpireUnpaidRegistrations)
+	go job.Run(ctx, "close-registration-windows", time.Minute, scheduleService.CloseRegistrationWindows)
+	go job.Run(ctx, "draw-lotteries", time.Minute, lotteryService.DrawDue)
+	go job.Run(ctx, "mark-no-shows", time.Minute, admissionService.MarkNoShows)
+	go job.Run(ctx, "assign-seats", time.Minute, seatingService.AssignDue)
+	go job.Run(ctx, "release-scores", time.Minute, scoreService.ReleaseDue)
+
 	// Start server in a goroutine
 	go func() {
 		log.Printf("Server starting on port %s", port)
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type AdmissionHandler struct {
+	service *service.AdmissionService
+}
+
+func NewAdmissionHandler(service *service.AdmissionService) *AdmissionHandler {
+	return &AdmissionHandler{service: service}
+}
+
+func admissionErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), " not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid "):
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *AdmissionHandler) AdmissionCard(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid registration ID"})
+		return
+	}
+
+	doc, filename, err := h.service.AdmissionCard(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(admissionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writePDF(c, doc, filename)
+}
+
+// CheckIn accepts the code scanned from an admission card at the door of a session
+func (h *AdmissionHandler) CheckIn(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	var req model.CheckIn
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.CheckIn(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(admissionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *AdmissionHandler) Attendance(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	summary, err := h.service.Attendance(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(admissionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func (h *AdmissionHandler) RegisterRoutes(router *gin.RouterGroup) {
+	registrations := router.Group("/registrations", middleware.RequireRole(model.RoleStudent, model.RoleAdmin, model.RoleSuperAdmin))
+	{
+		registrations.GET("/:id/admission-card", h.AdmissionCard)
+	}
+
+	schedules := router.Group("/schedules/:id", middleware.RequireRole(model.RoleProctor, model.RoleAdmin, model.RoleSuperAdmin))
+	{
+		schedules.POST("/check-in", h.CheckIn)
+		schedules.GET("/attendance", h.Attendance)
+	}
+}
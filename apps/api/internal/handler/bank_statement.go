@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+// maxStatementSize bounds an uploaded bank statement
+const maxStatementSize = 10 << 20
+
+type BankStatementHandler struct {
+	service *service.BankStatementService
+}
+
+func NewBankStatementHandler(service *service.BankStatementService) *BankStatementHandler {
+	return &BankStatementHandler{service: service}
+}
+
+func bankStatementErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), " not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid "):
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// ImportStatement accepts the export as a multipart upload in the "file" field or as
+// the raw request body. The format (csv or mt940) is taken from ?format= or detected.
+func (h *BankStatementHandler) ImportStatement(c *gin.Context) {
+	var (
+		body     io.Reader = c.Request.Body
+		filename           = c.Query("filename")
+	)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: missing file"})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+		defer f.Close()
+		body, filename = f, file.Filename
+	}
+
+	content, err := io.ReadAll(io.LimitReader(body, maxStatementSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	if len(content) > maxStatementSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "invalid request: statement is larger than 10 MB"})
+		return
+	}
+
+	statement, err := h.service.ImportStatement(c.Request.Context(), filename, c.Query("format"), content, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(bankStatementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, statement)
+}
+
+func (h *BankStatementHandler) ListStatements(c *gin.Context) {
+	statements, err := h.service.ListStatements(c.Request.Context())
+	if err != nil {
+		c.JSON(bankStatementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statements)
+}
+
+func (h *BankStatementHandler) GetStatement(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bank statement ID"})
+		return
+	}
+
+	statement, err := h.service.GetStatement(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(bankStatementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+func (h *BankStatementHandler) ListLines(c *gin.Context) {
+	lines, err := h.service.ListLines(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		c.JSON(bankStatementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, lines)
+}
+
+func (h *BankStatementHandler) ListUnmatchedPayments(c *gin.Context) {
+	payments, err := h.service.ListUnmatchedPayments(c.Request.Context())
+	if err != nil {
+		c.JSON(bankStatementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, payments)
+}
+
+func (h *BankStatementHandler) MatchLine(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid statement line ID"})
+		return
+	}
+
+	var req model.MatchStatementLine
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	line, err := h.service.MatchLine(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(bankStatementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, line)
+}
+
+func (h *BankStatementHandler) IgnoreLine(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid statement line ID"})
+		return
+	}
+
+	var req model.IgnoreStatementLine
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	line, err := h.service.IgnoreLine(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(bankStatementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, line)
+}
+
+func (h *BankStatementHandler) RegisterRoutes(router *gin.RouterGroup) {
+	statements := router.Group("/bank-statements", middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin))
+	{
+		statements.GET("", h.ListStatements)
+		statements.POST("", h.ImportStatement)
+		statements.GET("/:id", h.GetStatement)
+		statements.GET("/lines", h.ListLines)
+		statements.POST("/lines/:id/match", h.MatchLine)
+		statements.POST("/lines/:id/ignore", h.IgnoreLine)
+		statements.GET("/unmatched-payments", h.ListUnmatchedPayments)
+	}
+}
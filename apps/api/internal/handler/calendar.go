@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type CalendarHandler struct {
+	service *service.CalendarService
+}
+
+func NewCalendarHandler(service *service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{service: service}
+}
+
+func calendarErrorStatus(err error) int {
+	switch {
+	case err.Error() == "calendar event not found":
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid calendar"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *CalendarHandler) CreateEvent(c *gin.Context) {
+	var req model.CreateCalendarEvent
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	event, err := h.service.CreateEvent(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(calendarErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, event)
+}
+
+func (h *CalendarHandler) DeleteEvent(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid calendar event ID"})
+		return
+	}
+
+	if err := h.service.DeleteEvent(c.Request.Context(), id); err != nil {
+		c.JSON(calendarErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CalendarHandler) ListEvents(c *gin.Context) {
+	// Default to the current year
+	now := time.Now()
+	from := model.NewDate(time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC))
+	to := model.NewDate(time.Date(now.Year(), 12, 31, 0, 0, 0, 0, time.UTC))
+
+	var err error
+	if v := c.Query("from"); v != "" {
+		if from, err = model.ParseDate(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from: " + err.Error()})
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = model.ParseDate(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: " + err.Error()})
+			return
+		}
+	}
+
+	events, err := h.service.ListEvents(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(calendarErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// ImportICS accepts either a multipart upload in the "file" field or a raw text/calendar body
+func (h *CalendarHandler) ImportICS(c *gin.Context) {
+	eventType := model.CalendarEventType(c.DefaultQuery("type", string(model.CalendarEventNationalHoliday)))
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: missing file"})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	result, err := h.service.ImportICS(c.Request.Context(), body, eventType)
+	if err != nil {
+		c.JSON(calendarErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *CalendarHandler) RegisterRoutes(router *gin.RouterGroup) {
+	calendar := router.Group("/calendar-events")
+	{
+		calendar.GET("", h.ListEvents)
+
+		admin := calendar.Group("", middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin))
+		admin.POST("", h.CreateEvent)
+		admin.POST("/import", h.ImportICS)
+		admin.DELETE("/:id", h.DeleteEvent)
+	}
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type CoordinatorHandler struct {
+	service *service.CoordinatorService
+}
+
+func NewCoordinatorHandler(service *service.CoordinatorService) *CoordinatorHandler {
+	return &CoordinatorHandler{service: service}
+}
+
+func coordinatorErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), " not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid "):
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *CoordinatorHandler) CreateFaculty(c *gin.Context) {
+	var req model.CreateFaculty
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	faculty, err := h.service.CreateFaculty(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(coordinatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, faculty)
+}
+
+func (h *CoordinatorHandler) ListFaculties(c *gin.Context) {
+	faculties, err := h.service.ListFaculties(c.Request.Context())
+	if err != nil {
+		c.JSON(coordinatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, faculties)
+}
+
+func (h *CoordinatorHandler) UpdateFaculty(c *gin.Context) {
+	var req model.UpdateFaculty
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	faculty, err := h.service.UpdateFaculty(c.Request.Context(), c.Param("code"), &req)
+	if err != nil {
+		c.JSON(coordinatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, faculty)
+}
+
+func (h *CoordinatorHandler) CreateCoordinator(c *gin.Context) {
+	var req model.CreateCoordinator
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	coordinator, err := h.service.CreateCoordinator(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(coordinatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, coordinator)
+}
+
+func (h *CoordinatorHandler) ListCoordinators(c *gin.Context) {
+	coordinators, err := h.service.ListCoordinators(c.Request.Context(), c.Query("faculty"))
+	if err != nil {
+		c.JSON(coordinatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, coordinators)
+}
+
+func (h *CoordinatorHandler) GetCoordinator(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid coordinator ID"})
+		return
+	}
+
+	coordinator, err := h.service.GetCoordinator(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(coordinatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, coordinator)
+}
+
+func (h *CoordinatorHandler) UpdateCoordinator(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid coordinator ID"})
+		return
+	}
+
+	var req model.UpdateCoordinator
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	coordinator, err := h.service.UpdateCoordinator(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(coordinatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, coordinator)
+}
+
+func (h *CoordinatorHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admin := middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin)
+
+	faculties := router.Group("/faculties", admin)
+	{
+		faculties.GET("", h.ListFaculties)
+		faculties.POST("", h.CreateFaculty)
+		faculties.PUT("/:code", h.UpdateFaculty)
+	}
+
+	coordinators := router.Group("/coordinators", middleware.RequireRole(model.RoleCoordinator, model.RoleAdmin, model.RoleSuperAdmin))
+	{
+		coordinators.GET("", admin, h.ListCoordinators)
+		coordinators.POST("", admin, h.CreateCoordinator)
+		coordinators.GET("/:id", h.GetCoordinator)
+		coordinators.PUT("/:id", admin, h.UpdateCoordinator)
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type EligibilityHandler struct {
+	service *service.EligibilityService
+}
+
+func NewEligibilityHandler(service *service.EligibilityService) *EligibilityHandler {
+	return &EligibilityHandler{service: service}
+}
+
+func eligibilityErrorStatus(err error) int {
+	switch {
+	case err.Error() == "eligibility rule not found":
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid eligibility rule"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *EligibilityHandler) CreateRule(c *gin.Context) {
+	var req model.CreateEligibilityRule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	rule, err := h.service.CreateRule(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(eligibilityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *EligibilityHandler) ListRules(c *gin.Context) {
+	rules, err := h.service.ListRules(c.Request.Context())
+	if err != nil {
+		c.JSON(eligibilityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func (h *EligibilityHandler) UpdateRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid eligibility rule ID"})
+		return
+	}
+
+	var req model.UpdateEligibilityRule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	rule, err := h.service.UpdateRule(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(eligibilityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *EligibilityHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid eligibility rule ID"})
+		return
+	}
+
+	if err := h.service.DeleteRule(c.Request.Context(), id); err != nil {
+		c.JSON(eligibilityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *EligibilityHandler) RegisterRoutes(router *gin.RouterGroup) {
+	rules := router.Group("/eligibility-rules", middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin))
+	{
+		rules.GET("", h.ListRules)
+		rules.POST("", h.CreateRule)
+		rules.PUT("/:id", h.UpdateRule)
+		rules.DELETE("/:id", h.DeleteRule)
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/ical"
+)
+
+type FeedHandler struct {
+	service *service.FeedService
+}
+
+func NewFeedHandler(service *service.FeedService) *FeedHandler {
+	return &FeedHandler{service: service}
+}
+
+func writeCalendar(c *gin.Context, cal *ical.Calendar) {
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Header("Cache-Control", "no-cache")
+	c.Status(http.StatusOK)
+	if err := ical.Write(c.Writer, *cal); err != nil {
+		c.Error(err)
+	}
+}
+
+func (h *FeedHandler) PublicScheduleFeed(c *gin.Context) {
+	cal, err := h.service.PublicScheduleFeed(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeCalendar(c, cal)
+}
+
+func (h *FeedHandler) IssueToken(c *gin.Context) {
+	subscription, err := h.service.IssueToken(c.Request.Context(), middleware.CurrentUser(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "personal calendar feeds are only available to students and proctors" {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+func (h *FeedHandler) PersonalFeed(c *gin.Context) {
+	cal, err := h.service.PersonalFeed(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "feed not found" {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeCalendar(c, cal)
+}
+
+func (h *FeedHandler) RegisterRoutes(router *gin.RouterGroup) {
+	feeds := router.Group("/feeds")
+	{
+		feeds.GET("/schedules.ics", h.PublicScheduleFeed)
+		feeds.POST("/token", middleware.RequireRole(model.RoleStudent, model.RoleProctor), h.IssueToken)
+		feeds.GET("/:token/calendar.ics", h.PersonalFeed)
+	}
+}
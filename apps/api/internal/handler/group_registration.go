@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type GroupRegistrationHandler struct {
+	service *service.GroupRegistrationService
+}
+
+func NewGroupRegistrationHandler(service *service.GroupRegistrationService) *GroupRegistrationHandler {
+	return &GroupRegistrationHandler{service: service}
+}
+
+func groupRegistrationErrorStatus(err error) int {
+	switch {
+	case err.Error() == "group registration not found", err.Error() == "group invoice not found", err.Error() == "schedule not found":
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid student list"):
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// CreateGroupRegistration accepts either a JSON body or a multipart upload with the
+// student list in the "file" field (CSV or plain text) and the options as form fields
+func (h *GroupRegistrationHandler) CreateGroupRegistration(c *gin.Context) {
+	scheduleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	var req model.CreateGroupRegistration
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: missing file"})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+		defer f.Close()
+
+		if req.StudentNumbers, err = service.ParseStudentNumbers(f); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.AllOrNone, _ = strconv.ParseBool(c.PostForm("all_or_none"))
+		req.Notes = c.PostForm("notes")
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	group, err := h.service.CreateGroupRegistration(c.Request.Context(), scheduleID, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(groupRegistrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	// An all-or-none batch that was rolled back still returns its outcome report
+	if group.ID == 0 {
+		c.JSON(http.StatusUnprocessableEntity, group)
+		return
+	}
+
+	c.JSON(http.StatusCreated, group)
+}
+
+func (h *GroupRegistrationHandler) GetGroupRegistration(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group registration ID"})
+		return
+	}
+
+	group, err := h.service.GetGroupRegistration(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(groupRegistrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+func (h *GroupRegistrationHandler) ListGroupRegistrations(c *gin.Context) {
+	groups, err := h.service.ListGroupRegistrations(c.Request.Context(), middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(groupRegistrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+func (h *GroupRegistrationHandler) MarkInvoicePaid(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group registration ID"})
+		return
+	}
+
+	invoice, err := h.service.MarkInvoicePaid(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(groupRegistrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+func (h *GroupRegistrationHandler) CancelInvoice(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group registration ID"})
+		return
+	}
+
+	var req model.CancelGroupInvoice
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+	}
+
+	invoice, err := h.service.CancelInvoice(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(groupRegistrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+func (h *GroupRegistrationHandler) RegisterRoutes(router *gin.RouterGroup) {
+	coordinators := middleware.RequireRole(model.RoleCoordinator, model.RoleAdmin, model.RoleSuperAdmin)
+	admin := middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin)
+
+	router.POST("/schedules/:id/group-registrations", coordinators, h.CreateGroupRegistration)
+
+	groups := router.Group("/group-registrations", coordinators)
+	{
+		groups.GET("", h.ListGroupRegistrations)
+		groups.GET("/:id", h.GetGroupRegistration)
+		groups.POST("/:id/invoice/mark-paid", admin, h.MarkInvoicePaid)
+		groups.POST("/:id/invoice/cancel", admin, h.CancelInvoice)
+	}
+}
@@ -6,12 +6,77 @@ import (
 
 // Handler contains all handlers for the application
 type Handler struct {
-	Schedule *ScheduleHandler
+	Schedule          *ScheduleHandler
+	ScheduleTemplate  *ScheduleTemplateHandler
+	Calendar          *CalendarHandler
+	Feed              *FeedHandler
+	Registration      *RegistrationHandler
+	Waitlist          *WaitlistHandler
+	Notification      *NotificationHandler
+	Eligibility       *EligibilityHandler
+	Lottery           *LotteryHandler
+	Participant       *ParticipantHandler
+	GroupRegistration *GroupRegistrationHandler
+	Payment           *PaymentHandler
+	Voucher           *VoucherHandler
+	Refund            *RefundHandler
+	BankStatement     *BankStatementHandler
+	Admission         *AdmissionHandler
+	Seating           *SeatingHandler
+	Proctor           *ProctorHandler
+	TestForm          *TestFormHandler
+	Score             *ScoreHandler
+	ScoreAppeal       *ScoreAppealHandler
+	Coordinator       *CoordinatorHandler
 }
 
 // NewHandler creates a new Handler instance
-func NewHandler(scheduleService *service.ScheduleService) *Handler {
+func NewHandler(
+	scheduleService *service.ScheduleService,
+	scheduleTemplateService *service.ScheduleTemplateService,
+	calendarService *service.CalendarService,
+	feedService *service.FeedService,
+	registrationService *service.RegistrationService,
+	waitlistService *service.WaitlistService,
+	notificationService *service.NotificationService,
+	eligibilityService *service.EligibilityService,
+	lotteryService *service.LotteryService,
+	participantService *service.ParticipantService,
+	groupRegistrationService *service.GroupRegistrationService,
+	paymentService *service.PaymentService,
+	voucherService *service.VoucherService,
+	refundService *service.RefundService,
+	bankStatementService *service.BankStatementService,
+	admissionService *service.AdmissionService,
+	seatingService *service.SeatingService,
+	proctorService *service.ProctorService,
+	testFormService *service.TestFormService,
+	scoreService *service.ScoreService,
+	scoreAppealService *service.ScoreAppealService,
+	coordinatorService *service.CoordinatorService,
+) *Handler {
 	return &Handler{
-		Schedule: NewScheduleHandler(scheduleService),
+		Schedule:          NewScheduleHandler(scheduleService),
+		ScheduleTemplate:  NewScheduleTemplateHandler(scheduleTemplateService),
+		Calendar:          NewCalendarHandler(calendarService),
+		Feed:              NewFeedHandler(feedService),
+		Registration:      NewRegistrationHandler(registrationService),
+		Waitlist:          NewWaitlistHandler(waitlistService),
+		Notification:      NewNotificationHandler(notificationService),
+		Eligibility:       NewEligibilityHandler(eligibilityService),
+		Lottery:           NewLotteryHandler(lotteryService),
+		Participant:       NewParticipantHandler(participantService),
+		GroupRegistration: NewGroupRegistrationHandler(groupRegistrationService),
+		Payment:           NewPaymentHandler(paymentService),
+		Voucher:           NewVoucherHandler(voucherService),
+		Refund:            NewRefundHandler(refundService),
+		BankStatement:     NewBankStatementHandler(bankStatementService),
+		Admission:         NewAdmissionHandler(admissionService),
+		Seating:           NewSeatingHandler(seatingService),
+		Proctor:           NewProctorHandler(proctorService),
+		TestForm:          NewTestFormHandler(testFormService),
+		Score:             NewScoreHandler(scoreService),
+		ScoreAppeal:       NewScoreAppealHandler(scoreAppealService),
+		Coordinator:       NewCoordinatorHandler(coordinatorService),
 	}
 }
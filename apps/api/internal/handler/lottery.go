@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type LotteryHandler struct {
+	service *service.LotteryService
+}
+
+func NewLotteryHandler(service *service.LotteryService) *LotteryHandler {
+	return &LotteryHandler{service: service}
+}
+
+func lotteryErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), " not found"):
+		return http.StatusNotFound
+	case err.Error() == "student_id is required":
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *LotteryHandler) Apply(c *gin.Context) {
+	scheduleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	var req model.ApplyLottery
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+	}
+
+	application, err := h.service.Apply(c.Request.Context(), scheduleID, &req, middleware.CurrentUser(c))
+	var ineligible *service.EligibilityError
+	if errors.As(err, &ineligible) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "failed_rules": ineligible.Violations})
+		return
+	}
+	if err != nil {
+		c.JSON(lotteryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, application)
+}
+
+func (h *LotteryHandler) ListBySchedule(c *gin.Context) {
+	scheduleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	applications, err := h.service.ListBySchedule(c.Request.Context(), scheduleID)
+	if err != nil {
+		c.JSON(lotteryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, applications)
+}
+
+func (h *LotteryHandler) ListMine(c *gin.Context) {
+	applications, err := h.service.ListMine(c.Request.Context(), middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(lotteryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, applications)
+}
+
+func (h *LotteryHandler) Withdraw(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lottery application ID"})
+		return
+	}
+
+	application, err := h.service.Withdraw(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(lotteryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+func (h *LotteryHandler) Draw(c *gin.Context) {
+	scheduleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	draw, err := h.service.Draw(c.Request.Context(), scheduleID, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(lotteryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, draw)
+}
+
+func (h *LotteryHandler) GetDraw(c *gin.Context) {
+	scheduleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	draw, err := h.service.GetDraw(c.Request.Context(), scheduleID)
+	if err != nil {
+		c.JSON(lotteryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, draw)
+}
+
+func (h *LotteryHandler) Verify(c *gin.Context) {
+	scheduleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	verification, err := h.service.Verify(c.Request.Context(), scheduleID)
+	if err != nil {
+		c.JSON(lotteryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+func (h *LotteryHandler) RegisterRoutes(router *gin.RouterGroup) {
+	authenticated := middleware.RequireRole(model.RoleStudent, model.RoleAdmin, model.RoleSuperAdmin)
+	admin := middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin)
+
+	schedules := router.Group("/schedules/:id/lottery")
+	{
+		schedules.POST("/applications", authenticated, h.Apply)
+		schedules.GET("/applications", admin, h.ListBySchedule)
+		schedules.GET("", admin, h.GetDraw)
+		schedules.GET("/verify", admin, h.Verify)
+		schedules.POST("/draw", admin, h.Draw)
+	}
+
+	applications := router.Group("/lottery/applications", authenticated)
+	{
+		// Lists the caller's own applications, which only students have
+		applications.GET("", middleware.RequireRole(model.RoleStudent), h.ListMine)
+		applications.DELETE("/:id", h.Withdraw)
+	}
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type ParticipantHandler struct {
+	service *service.ParticipantService
+}
+
+func NewParticipantHandler(service *service.ParticipantService) *ParticipantHandler {
+	return &ParticipantHandler{service: service}
+}
+
+func participantErrorStatus(err error) int {
+	switch {
+	case err.Error() == "participant not found", err.Error() == "fee tier not found", err.Error() == "photo not found":
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid participant"):
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *ParticipantHandler) CreateParticipant(c *gin.Context) {
+	var req model.CreateParticipant
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	participant, err := h.service.CreateParticipant(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(participantErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, participant)
+}
+
+func (h *ParticipantHandler) GetParticipant(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid participant ID"})
+		return
+	}
+
+	participant, err := h.service.GetParticipant(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(participantErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, participant)
+}
+
+func (h *ParticipantHandler) ListParticipants(c *gin.Context) {
+	participants, err := h.service.ListParticipants(c.Request.Context(), c.Query("type"))
+	if err != nil {
+		c.JSON(participantErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, participants)
+}
+
+func (h *ParticipantHandler) UpdateParticipant(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid participant ID"})
+		return
+	}
+
+	var req model.UpdateParticipant
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	participant, err := h.service.UpdateParticipant(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(participantErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, participant)
+}
+
+// UploadPhoto accepts the photo as a multipart upload in the "photo" field or as the
+// raw request body
+func (h *ParticipantHandler) UploadPhoto(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid participant ID"})
+		return
+	}
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("photo")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: missing photo"})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, model.MaxPhotoSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	photo, err := h.service.UploadPhoto(c.Request.Context(), id, data, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(participantErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, photo)
+}
+
+func (h *ParticipantHandler) GetPhoto(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid participant ID"})
+		return
+	}
+
+	photo, err := h.service.GetPhoto(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(participantErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-cache")
+	c.Data(http.StatusOK, photo.ContentType, photo.Data)
+}
+
+func (h *ParticipantHandler) CreateFeeTier(c *gin.Context) {
+	var req model.CreateFeeTier
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	tier, err := h.service.CreateFeeTier(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(participantErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tier)
+}
+
+func (h *ParticipantHandler) ListFeeTiers(c *gin.Context) {
+	tiers, err := h.service.ListFeeTiers(c.Request.Context())
+	if err != nil {
+		c.JSON(participantErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tiers)
+}
+
+func (h *ParticipantHandler) UpdateFeeTier(c *gin.Context) {
+	var req model.UpdateFeeTier
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	tier, err := h.service.UpdateFeeTier(c.Request.Context(), c.Param("code"), &req)
+	if err != nil {
+		c.JSON(participantErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tier)
+}
+
+func (h *ParticipantHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admin := middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin)
+
+	participants := router.Group("/participants", admin)
+	{
+		participants.GET("", h.ListParticipants)
+		participants.POST("", h.CreateParticipant)
+		participants.GET("/:id", h.GetParticipant)
+		participants.PUT("/:id", h.UpdateParticipant)
+	}
+
+	// Students manage their own photo; proctors view it at check-in
+	photos := router.Group("/participants/:id/photo")
+	{
+		photos.GET("", middleware.RequireRole(model.RoleStudent, model.RoleProctor, model.RoleAdmin, model.RoleSuperAdmin), h.GetPhoto)
+		photos.PUT("", middleware.RequireRole(model.RoleStudent, model.RoleAdmin, model.RoleSuperAdmin), h.UploadPhoto)
+	}
+
+	// Tiers are assigned by admins when they enrol a participant, inactive ones included
+	tiers := router.Group("/fee-tiers", admin)
+	{
+		tiers.GET("", h.ListFeeTiers)
+		tiers.POST("", h.CreateFeeTier)
+		tiers.PUT("/:code", h.UpdateFeeTier)
+	}
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/pdf"
+)
+
+type PaymentHandler struct {
+	service *service.PaymentService
+}
+
+func NewPaymentHandler(service *service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{service: service}
+}
+
+func paymentErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), " not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid voucher"):
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *PaymentHandler) Quote(c *gin.Context) {
+	registrationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid registration ID"})
+		return
+	}
+
+	quote, err := h.service.Quote(c.Request.Context(), registrationID, c.Query("voucher_code"), middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+func (h *PaymentHandler) CreatePayment(c *gin.Context) {
+	var req model.CreatePayment
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	payment, err := h.service.CreatePayment(c.Request.Context(), &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, payment)
+}
+
+func (h *PaymentHandler) GetPayment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment ID"})
+		return
+	}
+
+	payment, err := h.service.GetPayment(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+// writePDF sends a rendered document as a download
+func writePDF(c *gin.Context, doc *pdf.Document, filename string) {
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-cache")
+	c.Status(http.StatusOK)
+	if err := doc.Write(c.Writer); err != nil {
+		c.Error(err)
+	}
+}
+
+func (h *PaymentHandler) Invoice(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment ID"})
+		return
+	}
+
+	doc, filename, err := h.service.Invoice(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writePDF(c, doc, filename)
+}
+
+func (h *PaymentHandler) Receipt(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment ID"})
+		return
+	}
+
+	doc, filename, err := h.service.Receipt(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writePDF(c, doc, filename)
+}
+
+func (h *PaymentHandler) RegisterRoutes(router *gin.RouterGroup) {
+	authenticated := middleware.RequireRole(model.RoleStudent, model.RoleAdmin, model.RoleSuperAdmin)
+
+	router.GET("/registrations/:id/price", authenticated, h.Quote)
+
+	payments := router.Group("/payments", authenticated)
+	{
+		payments.POST("", h.CreatePayment)
+		payments.GET("/:id", h.GetPayment)
+		payments.GET("/:id/invoice.pdf", h.Invoice)
+		payments.GET("/:id/receipt.pdf", h.Receipt)
+	}
+}
@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type ProctorHandler struct {
+	service *service.ProctorService
+}
+
+func NewProctorHandler(service *service.ProctorService) *ProctorHandler {
+	return &ProctorHandler{service: service}
+}
+
+func proctorErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), " not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid "):
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *ProctorHandler) CreateProctor(c *gin.Context) {
+	var req model.CreateProctor
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	proctor, err := h.service.CreateProctor(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(proctorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, proctor)
+}
+
+func (h *ProctorHandler) ListProctors(c *gin.Context) {
+	proctors, err := h.service.ListProctors(c.Request.Context(), c.Query("active") == "true")
+	if err != nil {
+		c.JSON(proctorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, proctors)
+}
+
+func (h *ProctorHandler) GetProctor(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid proctor ID"})
+		return
+	}
+
+	proctor, err := h.service.GetProctor(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(proctorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, proctor)
+}
+
+func (h *ProctorHandler) UpdateProctor(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid proctor ID"})
+		return
+	}
+
+	var req model.UpdateProctor
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	proctor, err := h.service.UpdateProctor(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(proctorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, proctor)
+}
+
+func (h *ProctorHandler) AddAvailability(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid proctor ID"})
+		return
+	}
+
+	var req model.CreateProctorAvailability
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	availability, err := h.service.AddAvailability(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(proctorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, availability)
+}
+
+func (h *ProctorHandler) ListAvailability(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid proctor ID"})
+		return
+	}
+
+	periods, err := h.service.ListAvailability(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(proctorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, periods)
+}
+
+func (h *ProctorHandler) DeleteAvailability(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid proctor ID"})
+		return
+	}
+	availabilityID, err := strconv.ParseInt(c.Param("availabilityId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid availability ID"})
+		return
+	}
+
+	if err := h.service.DeleteAvailability(c.Request.Context(), id, availabilityID, middleware.CurrentUser(c)); err != nil {
+		c.JSON(proctorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ProctorSessions is the proctor's own view of their upcoming sessions and attendees
+func (h *ProctorHandler) ProctorSessions(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid proctor ID"})
+		return
+	}
+
+	sessions, err := h.service.ProctorSessions(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(proctorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (h *ProctorHandler) ListStaffing(c *gin.Context) {
+	report, err := h.service.ListStaffing(c.Request.Context(), c.Query("problems") == "true")
+	if err != nil {
+		c.JSON(proctorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *ProctorHandler) GetStaffing(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	staffing, err := h.service.GetStaffing(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(proctorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, staffing)
+}
+
+func (h *ProctorHandler) AssignProctor(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	var req model.AssignProctor
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	staffing, err := h.service.AssignProctor(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(proctorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, staffing)
+}
+
+func (h *ProctorHandler) UnassignProctor(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+	proctorID, err := strconv.ParseInt(c.Param("proctorId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid proctor ID"})
+		return
+	}
+
+	staffing, err := h.service.UnassignProctor(c.Request.Context(), id, proctorID, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(proctorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, staffing)
+}
+
+func (h *ProctorHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admin := middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin)
+
+	proctors := router.Group("/proctors", middleware.RequireRole(model.RoleProctor, model.RoleAdmin, model.RoleSuperAdmin))
+	{
+		proctors.GET("", admin, h.ListProctors)
+		proctors.POST("", admin, h.CreateProctor)
+		proctors.GET("/staffing", admin, h.ListStaffing)
+		proctors.GET("/:id", h.GetProctor)
+		proctors.PUT("/:id", admin, h.UpdateProctor)
+		proctors.GET("/:id/availability", h.ListAvailability)
+		proctors.POST("/:id/availability", h.AddAvailability)
+		proctors.DELETE("/:id/availability/:availabilityId", h.DeleteAvailability)
+		proctors.GET("/:id/sessions", h.ProctorSessions)
+	}
+
+	schedules := router.Group("/schedules/:id/proctors", admin)
+	{
+		schedules.GET("", h.GetStaffing)
+		schedules.POST("", h.AssignProctor)
+		schedules.DELETE("/:proctorId", h.UnassignProctor)
+	}
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type RefundHandler struct {
+	service *service.RefundService
+}
+
+func NewRefundHandler(service *service.RefundService) *RefundHandler {
+	return &RefundHandler{service: service}
+}
+
+func refundErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), " not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid "):
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *RefundHandler) RequestRefund(c *gin.Context) {
+	paymentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment ID"})
+		return
+	}
+
+	var req model.CreateRefund
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	refund, err := h.service.RequestRefund(c.Request.Context(), paymentID, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(refundErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, refund)
+}
+
+func (h *RefundHandler) ListPaymentRefunds(c *gin.Context) {
+	paymentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment ID"})
+		return
+	}
+
+	refunds, err := h.service.ListByPayment(c.Request.Context(), paymentID, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(refundErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, refunds)
+}
+
+func (h *RefundHandler) ListRefunds(c *gin.Context) {
+	refunds, err := h.service.ListRefunds(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		c.JSON(refundErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, refunds)
+}
+
+func (h *RefundHandler) GetRefund(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid refund ID"})
+		return
+	}
+
+	refund, err := h.service.GetRefund(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(refundErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, refund)
+}
+
+func (h *RefundHandler) review(c *gin.Context, approve bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid refund ID"})
+		return
+	}
+
+	// The notes are optional, so an empty body is accepted
+	var req model.ReviewRefund
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+	}
+
+	refund, err := h.service.ReviewRefund(c.Request.Context(), id, approve, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(refundErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, refund)
+}
+
+func (h *RefundHandler) ApproveRefund(c *gin.Context) {
+	h.review(c, true)
+}
+
+func (h *RefundHandler) RejectRefund(c *gin.Context) {
+	h.review(c, false)
+}
+
+func (h *RefundHandler) CompleteRefund(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid refund ID"})
+		return
+	}
+
+	var req model.CompleteRefund
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	refund, err := h.service.CompleteRefund(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(refundErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, refund)
+}
+
+func (h *RefundHandler) RegisterRoutes(router *gin.RouterGroup) {
+	authenticated := middleware.RequireRole(model.RoleStudent, model.RoleAdmin, model.RoleSuperAdmin)
+	admin := middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin)
+
+	router.POST("/payments/:id/refunds", authenticated, h.RequestRefund)
+	router.GET("/payments/:id/refunds", authenticated, h.ListPaymentRefunds)
+
+	refunds := router.Group("/refunds")
+	{
+		refunds.GET("", admin, h.ListRefunds)
+		refunds.GET("/:id", authenticated, h.GetRefund)
+		refunds.POST("/:id/approve", admin, h.ApproveRefund)
+		refunds.POST("/:id/reject", admin, h.RejectRefund)
+		refunds.POST("/:id/complete", admin, h.CompleteRefund)
+	}
+}
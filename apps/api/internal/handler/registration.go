@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type RegistrationHandler struct {
+	service *service.RegistrationService
+}
+
+func NewRegistrationHandler(service *service.RegistrationService) *RegistrationHandler {
+	return &RegistrationHandler{service: service}
+}
+
+// registrationErrorStatus maps service errors to HTTP status codes
+func registrationErrorStatus(err error) int {
+	switch {
+	case err.Error() == "registration not found", err.Error() == "target schedule not found",
+		err.Error() == "schedule not found", err.Error() == "participant not found", err.Error() == "payment not found":
+		return http.StatusNotFound
+	case err.Error() == "eligibility override requires an admin role":
+		return http.StatusForbidden
+	case err.Error() == "registration is already on the target schedule":
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *RegistrationHandler) CreateRegistration(c *gin.Context) {
+	var req model.CreateRegistration
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	registration, err := h.service.CreateRegistration(c.Request.Context(), &req, middleware.CurrentUser(c))
+	var ineligible *service.EligibilityError
+	if errors.As(err, &ineligible) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "failed_rules": ineligible.Violations})
+		return
+	}
+	if err != nil {
+		c.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, registration)
+}
+
+func (h *RegistrationHandler) CancelRegistration(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid registration ID"})
+		return
+	}
+
+	var req model.CancelRegistration
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+	}
+
+	registration, err := h.service.CancelRegistration(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, registration)
+}
+
+func (h *RegistrationHandler) GetRegistration(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid registration ID"})
+		return
+	}
+
+	registration, err := h.service.GetRegistration(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, registration)
+}
+
+func (h *RegistrationHandler) ListHistory(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid registration ID"})
+		return
+	}
+
+	histories, err := h.service.ListHistory(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, histories)
+}
+
+func (h *RegistrationHandler) RescheduleRegistration(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid registration ID"})
+		return
+	}
+
+	var req model.RescheduleRegistration
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	registration, err := h.service.RescheduleRegistration(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	var ineligible *service.EligibilityError
+	if errors.As(err, &ineligible) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "failed_rules": ineligible.Violations})
+		return
+	}
+	if err != nil {
+		c.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, registration)
+}
+
+func (h *RegistrationHandler) ApproveRegistration(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid registration ID"})
+		return
+	}
+
+	var req model.ApproveRegistration
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+	}
+
+	registration, err := h.service.ApproveRegistration(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, registration)
+}
+
+func (h *RegistrationHandler) RegisterRoutes(router *gin.RouterGroup) {
+	registrations := router.Group("/registrations", middleware.RequireRole(model.RoleStudent, model.RoleAdmin, model.RoleSuperAdmin))
+	{
+		registrations.POST("", h.CreateRegistration)
+		registrations.GET("/:id", h.GetRegistration)
+		registrations.GET("/:id/history", h.ListHistory)
+		registrations.POST("/:id/reschedule", h.RescheduleRegistration)
+		registrations.POST("/:id/cancel", h.CancelRegistration)
+		registrations.POST("/:id/approve", middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin), h.ApproveRegistration)
+	}
+}
@@ -3,9 +3,11 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
 	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
 	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
 )
@@ -25,11 +27,16 @@ func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
 		return
 	}
 
-	schedule, err := h.service.CreateSchedule(c.Request.Context(), &req)
+	schedule, err := h.service.CreateSchedule(c.Request.Context(), &req, middleware.CurrentUser(c))
 	if err != nil {
 		status := http.StatusInternalServerError
-		if err.Error() == "test date cannot be in the past" {
+		switch {
+		case err.Error() == "test date cannot be in the past":
 			status = http.StatusBadRequest
+		case strings.HasPrefix(err.Error(), "schedule date"), strings.HasPrefix(err.Error(), "invalid registration window"):
+			status = http.StatusBadRequest
+		case err.Error() == "calendar override requires the super_admin role":
+			status = http.StatusForbidden
 		}
 		c.JSON(status, gin.H{"error": err.Error()})
 		return
@@ -71,14 +78,22 @@ func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
 		return
 	}
 
-	schedule, err := h.service.UpdateSchedule(c.Request.Context(), id, &req)
+	schedule, err := h.service.UpdateSchedule(c.Request.Context(), id, &req, middleware.CurrentUser(c))
 	if err != nil {
 		status := http.StatusInternalServerError
-		switch err.Error() {
-		case "schedule not found":
+		switch {
+		case err.Error() == "schedule not found":
 			status = http.StatusNotFound
-		case "test date cannot be in the past", "invalid schedule: quota must be greater than 0":
+		case err.Error() == "schedule is already cancelled",
+			strings.HasPrefix(err.Error(), "cannot change allocation mode"):
+			status = http.StatusConflict
+		case err.Error() == "test date cannot be in the past", err.Error() == "invalid schedule: quota must be greater than 0":
 			status = http.StatusBadRequest
+		case strings.HasPrefix(err.Error(), "schedule date"), strings.HasPrefix(err.Error(), "invalid registration window"),
+			strings.HasPrefix(err.Error(), "invalid quota segments"):
+			status = http.StatusBadRequest
+		case err.Error() == "calendar override requires the super_admin role":
+			status = http.StatusForbidden
 		}
 		c.JSON(status, gin.H{"error": err.Error()})
 		return
@@ -108,6 +123,69 @@ func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+func (h *ScheduleHandler) CancelSchedule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	var req model.CancelSchedule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.CancelSchedule(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case err.Error() == "schedule not found", err.Error() == "target schedule not found":
+			status = http.StatusNotFound
+		case err.Error() == "target schedule must differ from the cancelled schedule":
+			status = http.StatusBadRequest
+		case err.Error() == "schedule is already cancelled",
+			strings.HasPrefix(err.Error(), "target schedule"):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *ScheduleHandler) ReplaceSegments(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	var req model.ReplaceQuotaSegments
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	schedule, err := h.service.ReplaceSegments(c.Request.Context(), id, &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case err.Error() == "schedule not found":
+			status = http.StatusNotFound
+		case err.Error() == "schedule is already cancelled":
+			status = http.StatusConflict
+		case strings.HasPrefix(err.Error(), "invalid quota segments"):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
 func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
 	// Get pagination parameters
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -146,12 +224,16 @@ func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
 }
 
 func (h *ScheduleHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admin := middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin)
+
 	schedules := router.Group("/schedules")
 	{
-		schedules.POST("", h.CreateSchedule)
+		schedules.POST("", admin, h.CreateSchedule)
 		schedules.GET("/:id", h.GetSchedule)
-		schedules.PUT("/:id", h.UpdateSchedule)
-		schedules.DELETE("/:id", h.DeleteSchedule)
+		schedules.PUT("/:id", admin, h.UpdateSchedule)
+		schedules.DELETE("/:id", admin, h.DeleteSchedule)
 		schedules.GET("", h.ListSchedules)
+		schedules.POST("/:id/cancel", admin, h.CancelSchedule)
+		schedules.PUT("/:id/segments", admin, h.ReplaceSegments)
 	}
 }
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type ScheduleTemplateHandler struct {
+	service *service.ScheduleTemplateService
+}
+
+func NewScheduleTemplateHandler(service *service.ScheduleTemplateService) *ScheduleTemplateHandler {
+	return &ScheduleTemplateHandler{service: service}
+}
+
+// templateErrorStatus maps service errors to HTTP status codes
+func templateErrorStatus(err error) int {
+	switch {
+	case err.Error() == "schedule template not found":
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid schedule template"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *ScheduleTemplateHandler) CreateTemplate(c *gin.Context) {
+	var req model.CreateScheduleTemplate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	template, err := h.service.CreateTemplate(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+func (h *ScheduleTemplateHandler) GetTemplate(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule template ID"})
+		return
+	}
+
+	template, err := h.service.GetTemplate(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func (h *ScheduleTemplateHandler) UpdateTemplate(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule template ID"})
+		return
+	}
+
+	var req model.UpdateScheduleTemplate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	template, err := h.service.UpdateTemplate(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func (h *ScheduleTemplateHandler) DeleteTemplate(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule template ID"})
+		return
+	}
+
+	if err := h.service.DeleteTemplate(c.Request.Context(), id); err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ScheduleTemplateHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.service.ListTemplates(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+func (h *ScheduleTemplateHandler) PreviewSchedules(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule template ID"})
+		return
+	}
+
+	result, err := h.service.PreviewSchedules(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *ScheduleTemplateHandler) GenerateSchedules(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule template ID"})
+		return
+	}
+
+	result, err := h.service.GenerateSchedules(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *ScheduleTemplateHandler) RegisterRoutes(router *gin.RouterGroup) {
+	templates := router.Group("/schedule-templates", middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin))
+	{
+		templates.POST("", h.CreateTemplate)
+		templates.GET("/:id", h.GetTemplate)
+		templates.PUT("/:id", h.UpdateTemplate)
+		templates.DELETE("/:id", h.DeleteTemplate)
+		templates.GET("", h.ListTemplates)
+		templates.GET("/:id/preview", h.PreviewSchedules)
+		templates.POST("/:id/generate", h.GenerateSchedules)
+	}
+}
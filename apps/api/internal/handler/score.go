@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type ScoreHandler struct {
+	service *service.ScoreService
+}
+
+func NewScoreHandler(service *service.ScoreService) *ScoreHandler {
+	return &ScoreHandler{service: service}
+}
+
+func scoreErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), " not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid "):
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *ScoreHandler) RecordScore(c *gin.Context) {
+	var req model.CreateScore
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	score, err := h.service.RecordScore(c.Request.Context(), &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(scoreErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, score)
+}
+
+func (h *ScoreHandler) ListScores(c *gin.Context) {
+	var testPlotID int64
+	if value := c.Query("test_plot_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test plot ID"})
+			return
+		}
+		testPlotID = id
+	}
+
+	scores, err := h.service.ListScores(c.Request.Context(), testPlotID)
+	if err != nil {
+		c.JSON(scoreErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, scores)
+}
+
+func (h *ScoreHandler) ListExpiring(c *gin.Context) {
+	var days int
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid days"})
+			return
+		}
+		days = parsed
+	}
+
+	expiring, err := h.service.ListExpiring(c.Request.Context(), days)
+	if err != nil {
+		c.JSON(scoreErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, expiring)
+}
+
+func (h *ScoreHandler) GetScore(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid score ID"})
+		return
+	}
+
+	score, err := h.service.GetScore(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(scoreErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, score)
+}
+
+func (h *ScoreHandler) UpdateScore(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid score ID"})
+		return
+	}
+
+	var req model.UpdateScore
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	score, err := h.service.UpdateScore(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(scoreErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, score)
+}
+
+func (h *ScoreHandler) ListRevisions(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid score ID"})
+		return
+	}
+
+	revisions, err := h.service.ListRevisions(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(scoreErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+func (h *ScoreHandler) Certificate(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid score ID"})
+		return
+	}
+
+	doc, filename, err := h.service.Certificate(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(scoreErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writePDF(c, doc, filename)
+}
+
+// VerifyCertificate is public so that whoever receives a certificate can check it
+func (h *ScoreHandler) VerifyCertificate(c *gin.Context) {
+	verification, err := h.service.VerifyCertificate(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		c.JSON(scoreErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+func (h *ScoreHandler) ListParticipantScores(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid participant ID"})
+		return
+	}
+
+	scores, err := h.service.ListParticipantScores(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(scoreErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, scores)
+}
+
+func (h *ScoreHandler) GetRelease(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	release, err := h.service.GetRelease(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(scoreErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, release)
+}
+
+func (h *ScoreHandler) ReleaseScores(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	release, err := h.service.ReleaseScores(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(scoreErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, release)
+}
+
+func (h *ScoreHandler) ScheduleRelease(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	var req model.ScheduleScoreRelease
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	release, err := h.service.ScheduleRelease(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(scoreErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, release)
+}
+
+func (h *ScoreHandler) CancelScheduledRelease(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	if err := h.service.CancelScheduledRelease(c.Request.Context(), id); err != nil {
+		c.JSON(scoreErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ScoreHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admin := middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin)
+	student := middleware.RequireRole(model.RoleStudent, model.RoleAdmin, model.RoleSuperAdmin)
+
+	scores := router.Group("/scores")
+	{
+		scores.GET("", admin, h.ListScores)
+		scores.POST("", admin, h.RecordScore)
+		scores.GET("/expiring", admin, h.ListExpiring)
+		scores.GET("/:id", student, h.GetScore)
+		scores.PUT("/:id", admin, h.UpdateScore)
+		scores.GET("/:id/revisions", admin, h.ListRevisions)
+		scores.GET("/:id/certificate.pdf", student, h.Certificate)
+	}
+
+	router.GET("/certificates/:code", h.VerifyCertificate)
+
+	router.GET("/participants/:id/scores", student, h.ListParticipantScores)
+
+	releases := router.Group("/schedules/:id/score-release", admin)
+	{
+		releases.GET("", h.GetRelease)
+		releases.POST("", h.ReleaseScores)
+		releases.PUT("/schedule", h.ScheduleRelease)
+		releases.DELETE("/schedule", h.CancelScheduledRelease)
+	}
+}
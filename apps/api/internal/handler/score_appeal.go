@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type ScoreAppealHandler struct {
+	service *service.ScoreAppealService
+}
+
+func NewScoreAppealHandler(service *service.ScoreAppealService) *ScoreAppealHandler {
+	return &ScoreAppealHandler{service: service}
+}
+
+func scoreAppealErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), " not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid "):
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *ScoreAppealHandler) SubmitAppeal(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid score ID"})
+		return
+	}
+
+	var req model.CreateScoreAppeal
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	appeal, err := h.service.SubmitAppeal(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(scoreAppealErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, appeal)
+}
+
+func (h *ScoreAppealHandler) ListAppeals(c *gin.Context) {
+	appeals, err := h.service.ListAppeals(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		c.JSON(scoreAppealErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, appeals)
+}
+
+func (h *ScoreAppealHandler) ListParticipantAppeals(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid participant ID"})
+		return
+	}
+
+	appeals, err := h.service.ListParticipantAppeals(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(scoreAppealErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, appeals)
+}
+
+func (h *ScoreAppealHandler) GetAppeal(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appeal ID"})
+		return
+	}
+
+	appeal, err := h.service.GetAppeal(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(scoreAppealErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, appeal)
+}
+
+func (h *ScoreAppealHandler) ReviewAppeal(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appeal ID"})
+		return
+	}
+
+	var req model.ReviewScoreAppeal
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	appeal, err := h.service.ReviewAppeal(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(scoreAppealErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, appeal)
+}
+
+func (h *ScoreAppealHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admin := middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin)
+	student := middleware.RequireRole(model.RoleStudent, model.RoleAdmin, model.RoleSuperAdmin)
+
+	router.POST("/scores/:id/appeals", middleware.RequireRole(model.RoleStudent), h.SubmitAppeal)
+	router.GET("/participants/:id/score-appeals", student, h.ListParticipantAppeals)
+
+	appeals := router.Group("/score-appeals")
+	{
+		appeals.GET("", admin, h.ListAppeals)
+		appeals.GET("/:id", student, h.GetAppeal)
+		appeals.POST("/:id/review", admin, h.ReviewAppeal)
+	}
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type SeatingHandler struct {
+	service *service.SeatingService
+}
+
+func NewSeatingHandler(service *service.SeatingService) *SeatingHandler {
+	return &SeatingHandler{service: service}
+}
+
+func seatingErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), " not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid "):
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// writeCSVFile sends an export as a download
+func writeCSVFile(c *gin.Context, data []byte, filename string) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+func (h *SeatingHandler) GetPlan(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	plan, err := h.service.GetPlan(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(seatingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+func (h *SeatingHandler) ConfigureSeating(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	var req model.UpdateSeating
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	plan, err := h.service.ConfigureSeating(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(seatingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+func (h *SeatingHandler) AssignSeats(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	plan, err := h.service.AssignSeats(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(seatingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+func (h *SeatingHandler) SeatingChart(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	doc, filename, err := h.service.SeatingChart(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(seatingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writePDF(c, doc, filename)
+}
+
+func (h *SeatingHandler) SeatingChartCSV(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	data, filename, err := h.service.SeatingChartCSV(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(seatingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writeCSVFile(c, data, filename)
+}
+
+func (h *SeatingHandler) DoorList(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	doc, filename, err := h.service.DoorList(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(seatingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writePDF(c, doc, filename)
+}
+
+func (h *SeatingHandler) DoorListCSV(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	data, filename, err := h.service.DoorListCSV(c.Request.Context(), id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(seatingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writeCSVFile(c, data, filename)
+}
+
+func (h *SeatingHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admin := middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin)
+
+	seating := router.Group("/schedules/:id/seating", middleware.RequireRole(model.RoleProctor, model.RoleAdmin, model.RoleSuperAdmin))
+	{
+		seating.GET("", h.GetPlan)
+		seating.PUT("", admin, h.ConfigureSeating)
+		seating.POST("/assign", admin, h.AssignSeats)
+		seating.GET("/chart.pdf", h.SeatingChart)
+		seating.GET("/chart.csv", h.SeatingChartCSV)
+		seating.GET("/door-list.pdf", h.DoorList)
+		seating.GET("/door-list.csv", h.DoorListCSV)
+	}
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type TestFormHandler struct {
+	service *service.TestFormService
+}
+
+func NewTestFormHandler(service *service.TestFormService) *TestFormHandler {
+	return &TestFormHandler{service: service}
+}
+
+func testFormErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), " not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid "):
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *TestFormHandler) CreateTestForm(c *gin.Context) {
+	var req model.CreateTestForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	form, err := h.service.CreateTestForm(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(testFormErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, form)
+}
+
+func (h *TestFormHandler) ListTestForms(c *gin.Context) {
+	forms, err := h.service.ListTestForms(c.Request.Context())
+	if err != nil {
+		c.JSON(testFormErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, forms)
+}
+
+func (h *TestFormHandler) UpdateTestForm(c *gin.Context) {
+	var req model.UpdateTestForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	form, err := h.service.UpdateTestForm(c.Request.Context(), c.Param("code"), &req)
+	if err != nil {
+		c.JSON(testFormErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, form)
+}
+
+func (h *TestFormHandler) GetConversion(c *gin.Context) {
+	table, err := h.service.GetConversion(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		c.JSON(testFormErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, table)
+}
+
+func (h *TestFormHandler) SaveConversion(c *gin.Context) {
+	var req model.ConversionTable
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	table, err := h.service.SaveConversion(c.Request.Context(), c.Param("code"), &req)
+	if err != nil {
+		c.JSON(testFormErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, table)
+}
+
+func (h *TestFormHandler) GetSessionForms(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	forms, err := h.service.GetSessionForms(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(testFormErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, forms)
+}
+
+func (h *TestFormHandler) AssignSessionForm(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	var req model.AssignTestForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	forms, err := h.service.AssignSessionForm(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(testFormErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, forms)
+}
+
+func (h *TestFormHandler) AssignSeatForm(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid registration ID"})
+		return
+	}
+
+	var req model.AssignSeatTestForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	registration, err := h.service.AssignSeatForm(c.Request.Context(), id, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(testFormErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, registration)
+}
+
+func (h *TestFormHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admin := middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin)
+
+	forms := router.Group("/test-forms", admin)
+	{
+		forms.GET("", h.ListTestForms)
+		forms.POST("", h.CreateTestForm)
+		forms.PUT("/:code", h.UpdateTestForm)
+		forms.GET("/:code/conversion", h.GetConversion)
+		forms.PUT("/:code/conversion", h.SaveConversion)
+	}
+
+	router.GET("/schedules/:id/test-form", admin, h.GetSessionForms)
+	router.PUT("/schedules/:id/test-form", admin, h.AssignSessionForm)
+	router.PUT("/registrations/:id/test-form", admin, h.AssignSeatForm)
+}
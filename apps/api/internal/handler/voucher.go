@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type VoucherHandler struct {
+	service *service.VoucherService
+}
+
+func NewVoucherHandler(service *service.VoucherService) *VoucherHandler {
+	return &VoucherHandler{service: service}
+}
+
+func voucherErrorStatus(err error) int {
+	switch {
+	case err.Error() == "voucher not found":
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "invalid voucher"):
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *VoucherHandler) CreateVoucher(c *gin.Context) {
+	var req model.CreateVoucher
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	voucher, err := h.service.CreateVoucher(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(voucherErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, voucher)
+}
+
+func (h *VoucherHandler) ListVouchers(c *gin.Context) {
+	vouchers, err := h.service.ListVouchers(c.Request.Context())
+	if err != nil {
+		c.JSON(voucherErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, vouchers)
+}
+
+func (h *VoucherHandler) UpdateVoucher(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid voucher ID"})
+		return
+	}
+
+	var req model.UpdateVoucher
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	voucher, err := h.service.UpdateVoucher(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(voucherErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, voucher)
+}
+
+func (h *VoucherHandler) RegisterRoutes(router *gin.RouterGroup) {
+	vouchers := router.Group("/vouchers", middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin))
+	{
+		vouchers.GET("", h.ListVouchers)
+		vouchers.POST("", h.CreateVoucher)
+		vouchers.PUT("/:id", h.UpdateVoucher)
+	}
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/service"
+)
+
+type WaitlistHandler struct {
+	service *service.WaitlistService
+}
+
+func NewWaitlistHandler(service *service.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{service: service}
+}
+
+func waitlistErrorStatus(err error) int {
+	switch {
+	case err.Error() == "waitlist entry not found", err.Error() == "schedule not found", err.Error() == "participant not found":
+		return http.StatusNotFound
+	case err.Error() == "student_id is required":
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "cannot "):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *WaitlistHandler) JoinWaitlist(c *gin.Context) {
+	scheduleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	var req model.JoinWaitlist
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+	}
+
+	entry, err := h.service.JoinWaitlist(c.Request.Context(), scheduleID, &req, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(waitlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+func (h *WaitlistHandler) ListBySchedule(c *gin.Context) {
+	scheduleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	entries, err := h.service.ListBySchedule(c.Request.Context(), scheduleID)
+	if err != nil {
+		c.JSON(waitlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func (h *WaitlistHandler) ListMine(c *gin.Context) {
+	entries, err := h.service.ListMine(c.Request.Context(), middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(waitlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func (h *WaitlistHandler) LeaveWaitlist(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid waitlist entry ID"})
+		return
+	}
+
+	if err := h.service.LeaveWaitlist(c.Request.Context(), id, middleware.CurrentUser(c)); err != nil {
+		c.JSON(waitlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WaitlistHandler) ClaimSeat(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid waitlist entry ID"})
+		return
+	}
+
+	registration, err := h.service.ClaimSeat(c.Request.Context(), id, middleware.CurrentUser(c))
+	var ineligible *service.EligibilityError
+	if errors.As(err, &ineligible) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "failed_rules": ineligible.Violations})
+		return
+	}
+	if err != nil {
+		c.JSON(waitlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, registration)
+}
+
+func (h *WaitlistHandler) RegisterRoutes(router *gin.RouterGroup) {
+	authenticated := middleware.RequireRole(model.RoleStudent, model.RoleAdmin, model.RoleSuperAdmin)
+
+	schedules := router.Group("/schedules/:id/waitlist")
+	{
+		schedules.POST("", authenticated, h.JoinWaitlist)
+		schedules.GET("", middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin), h.ListBySchedule)
+	}
+
+	waitlist := router.Group("/waitlist", authenticated)
+	{
+		// Lists the caller's own entries, which only students have
+		waitlist.GET("", middleware.RequireRole(model.RoleStudent), h.ListMine)
+		waitlist.DELETE("/:id", h.LeaveWaitlist)
+		waitlist.POST("/:id/claim", h.ClaimSeat)
+	}
+}
@@ -0,0 +1,25 @@
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Run calls fn every interval until ctx is cancelled. Errors are logged and the
+// job keeps running.
+func Run(ctx context.Context, name string, interval time.Duration, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				log.Printf("job %s failed: %v", name, err)
+			}
+		}
+	}
+}
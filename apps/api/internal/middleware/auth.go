@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/token"
+)
+
+const authUserKey = "auth_user"
+
+// Authenticate resolves the caller from an optional "Authorization: Bearer <jwt>" header.
+// Requests without a header pass through anonymously; invalid tokens are rejected.
+func Authenticate(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		raw, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header"})
+			return
+		}
+
+		claims, err := token.Parse(raw, secret)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+			return
+		}
+
+		id, _ := strconv.ParseInt(claims.Subject, 10, 64)
+		c.Set(authUserKey, &model.AuthUser{
+			ID:   id,
+			Name: claims.Name,
+			Role: model.Role(claims.Role),
+		})
+		c.Next()
+	}
+}
+
+// RequireRole rejects callers that are not authenticated with one of the given roles
+func RequireRole(roles ...model.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := CurrentUser(c)
+		if user == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			return
+		}
+		if !user.HasRole(roles...) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CurrentUser returns the authenticated caller, or nil for anonymous requests
+func CurrentUser(c *gin.Context) *model.AuthUser {
+	value, ok := c.Get(authUserKey)
+	if !ok {
+		return nil
+	}
+	user, _ := value.(*model.AuthUser)
+	return user
+}
@@ -0,0 +1,43 @@
+package model
+
+// Role represents what an authenticated caller is allowed to do
+type Role string
+
+const (
+	RoleStudent     Role = "student"
+	RoleAdmin       Role = "admin"
+	RoleSuperAdmin  Role = "super_admin" // Can override policy checks such as calendar blocks
+	RoleCoordinator Role = "coordinator" // Faculty staff registering whole cohorts
+	RoleProctor     Role = "proctor"     // Invigilates test sessions and checks participants in
+)
+
+// AuthUser is the caller identified from the bearer token
+type AuthUser struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+// HasRole reports whether the user has any of the given roles; a nil user has none
+func (u *AuthUser) HasRole(roles ...Role) bool {
+	if u == nil {
+		return false
+	}
+	for _, role := range roles {
+		if u.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Identifier is recorded in audit fields such as ChangedBy and VerifiedBy
+func (u *AuthUser) Identifier() string {
+	if u == nil {
+		return "system"
+	}
+	if u.Name != "" {
+		return u.Name
+	}
+	return string(u.Role)
+}
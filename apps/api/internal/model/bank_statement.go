@@ -0,0 +1,77 @@
+package model
+
+import (
+	"time"
+)
+
+// Bank statement line statuses
+const (
+	StatementLineUnmatched = "unmatched" // No payment found yet; needs manual review
+	StatementLineMatched   = "matched"
+	StatementLineIgnored   = "ignored" // Not a registration payment
+	StatementLineLate      = "late"    // Pays an expired or cancelled payment; match it manually to refund or reinstate
+)
+
+// How a statement line was matched to its payment
+const (
+	MatchMethodReference = "reference" // Amount matched and the description names the registration
+	MatchMethodAmount    = "amount"    // The only pending payment with this uniquely coded amount
+	MatchMethodManual    = "manual"
+)
+
+// Base model - An imported bank mutation export
+type BankStatement struct {
+	ID             int64     `json:"id"`
+	Format         string    `json:"format"` // csv, mt940
+	Filename       string    `json:"filename"`
+	AccountNumber  string    `json:"account_number,omitempty"`
+	PeriodStart    time.Time `json:"period_start"`
+	PeriodEnd      time.Time `json:"period_end"`
+	CreditCount    int       `json:"credit_count"`    // Credits imported from this file
+	DuplicateCount int       `json:"duplicate_count"` // Credits skipped because an earlier import had them
+	MatchedCount   int       `json:"matched_count"`   // Credits matched automatically on import
+	ImportedBy     string    `json:"imported_by"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	Lines []*BankStatementLine `json:"lines,omitempty"`
+}
+
+// Base model - One credit on an imported statement
+type BankStatementLine struct {
+	ID          int64      `json:"id"`
+	StatementID int64      `json:"statement_id"`
+	LineNumber  int        `json:"line_number"` // Position among the statement's transactions
+	BookedOn    time.Time  `json:"booked_on"`
+	Amount      float64    `json:"amount"`
+	Description string     `json:"description"`
+	Reference   string     `json:"reference,omitempty"`
+	Status      string     `json:"status"` // unmatched, matched, ignored, late
+	PaymentID   *int64     `json:"payment_id,omitempty"`
+	MatchMethod string     `json:"match_method,omitempty"` // reference, amount, manual
+	MatchNotes  string     `json:"match_notes,omitempty"`  // Why a line was or was not matched
+	MatchedBy   string     `json:"matched_by,omitempty"`
+	MatchedAt   *time.Time `json:"matched_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Unmatched payment - A pending bank transfer no statement line was matched to
+type UnmatchedPayment struct {
+	PaymentID      int64      `json:"payment_id"`
+	RegistrationID int64      `json:"registration_id"`
+	RegNumber      string     `json:"reg_number"`
+	StudentName    string     `json:"student_name"`
+	Amount         float64    `json:"amount"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpiredAt      *time.Time `json:"expired_at,omitempty"`
+}
+
+// Match model - Manually pair a statement line with a pending, expired or cancelled payment
+type MatchStatementLine struct {
+	PaymentID int64  `json:"payment_id" validate:"required"`
+	Notes     string `json:"notes,omitempty" validate:"omitempty,max=500"`
+}
+
+// Ignore model - Set aside a line that is not a registration payment
+type IgnoreStatementLine struct {
+	Notes string `json:"notes" validate:"required,max=500"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+)
+
+// CalendarEventType represents why a date is blocked
+type CalendarEventType string
+
+const (
+	CalendarEventNationalHoliday CalendarEventType = "national_holiday"
+	CalendarEventCampusBlackout  CalendarEventType = "campus_blackout"
+)
+
+// Base model - A holiday or blackout period on which no test may be scheduled
+type CalendarEvent struct {
+	ID          int64             `json:"id"`
+	Name        string            `json:"name"`
+	Type        CalendarEventType `json:"type"`
+	StartDate   Date              `json:"start_date"`
+	EndDate     Date              `json:"end_date"` // Inclusive
+	Source      string            `json:"source"`   // manual, ics
+	ExternalUID string            `json:"external_uid,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// Create model
+type CreateCalendarEvent struct {
+	Name      string            `json:"name" validate:"required,max=255"`
+	Type      CalendarEventType `json:"type" validate:"required,oneof=national_holiday campus_blackout"`
+	StartDate Date              `json:"start_date"`
+	EndDate   Date              `json:"end_date"` // Defaults to StartDate for single-day events
+}
+
+// Result of an ICS import
+type ImportCalendarResult struct {
+	Imported int              `json:"imported"`
+	Events   []*CalendarEvent `json:"events"`
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Base model - A faculty and the majors of the students its coordinators may register
+type Faculty struct {
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Majors    []string  `json:"majors"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HasMajor reports whether students of the major belong to the faculty
+func (f *Faculty) HasMajor(major string) bool {
+	return slices.Contains(f.Majors, major)
+}
+
+// Create model
+type CreateFaculty struct {
+	Code   string   `json:"code" validate:"required,max=32"`
+	Name   string   `json:"name" validate:"required,max=100"`
+	Majors []string `json:"majors" validate:"required,min=1,dive,required,max=100"`
+}
+
+// Update model
+type UpdateFaculty struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Majors []string `json:"majors" validate:"required,min=1,dive,required,max=100"`
+}
+
+// Base model - Faculty staff member who registers cohorts. Coordinators sign in with
+// tokens whose subject is their coordinator ID.
+type Coordinator struct {
+	ID          int64     `json:"id"`
+	FullName    string    `json:"full_name"`
+	Email       string    `json:"email"`
+	FacultyCode string    `json:"faculty_code"`
+	Active      bool      `json:"active"` // Inactive coordinators cannot see or register groups
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Create model
+type CreateCoordinator struct {
+	FullName    string `json:"full_name" validate:"required,min=3,max=100"`
+	Email       string `json:"email" validate:"required,email"`
+	FacultyCode string `json:"faculty_code" validate:"required,max=32"`
+}
+
+// Update model
+type UpdateCoordinator struct {
+	FullName    string `json:"full_name" validate:"required,min=3,max=100"`
+	Email       string `json:"email" validate:"required,email"`
+	FacultyCode string `json:"faculty_code" validate:"required,max=32"`
+	Active      bool   `json:"active"`
+}
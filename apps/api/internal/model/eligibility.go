@@ -0,0 +1,68 @@
+package model
+
+import (
+	"time"
+)
+
+// EligibilityRuleType selects how a rule is evaluated
+type EligibilityRuleType string
+
+const (
+	EligibilityOneActiveRegistration EligibilityRuleType = "one_active_registration" // At most one upcoming registration
+	EligibilityRetakeInterval        EligibilityRuleType = "retake_interval"         // Minimum days between two tests
+	EligibilitySubsidizedMajors      EligibilityRuleType = "subsidized_majors"       // Subsidized sessions: active students of listed majors
+	EligibilityMaxAttemptsPerYear    EligibilityRuleType = "max_attempts_per_year"   // Tests per calendar year
+	EligibilityValidScore            EligibilityRuleType = "valid_score"             // A released score that has not expired
+)
+
+// EligibilityParams holds rule settings; which fields apply depends on the rule type
+type EligibilityParams struct {
+	Days        int      `json:"days,omitempty"`         // retake_interval
+	Majors      []string `json:"majors,omitempty"`       // subsidized_majors; empty allows every major
+	MaxAttempts int      `json:"max_attempts,omitempty"` // max_attempts_per_year
+	MinScore    int      `json:"min_score,omitempty"`    // valid_score; 0 accepts any total score
+}
+
+// Base model - A configurable registration policy
+type EligibilityRule struct {
+	ID          int64               `json:"id"`
+	Code        string              `json:"code"` // Reported when the rule rejects a registration
+	Type        EligibilityRuleType `json:"type"`
+	Params      EligibilityParams   `json:"params"`
+	Description string              `json:"description"`
+	Enabled     bool                `json:"enabled"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// Create model
+type CreateEligibilityRule struct {
+	Code        string              `json:"code" validate:"required,max=64"`
+	Type        EligibilityRuleType `json:"type" validate:"required,oneof=one_active_registration retake_interval subsidized_majors max_attempts_per_year valid_score"`
+	Params      EligibilityParams   `json:"params"`
+	Description string              `json:"description" validate:"max=255"`
+	Enabled     *bool               `json:"enabled,omitempty"` // Defaults to true
+}
+
+// Update model
+type UpdateEligibilityRule struct {
+	Params      EligibilityParams `json:"params"`
+	Description string            `json:"description" validate:"max=255"`
+	Enabled     bool              `json:"enabled"`
+}
+
+// EligibilityFacts is what the rules are evaluated against; it is read inside the
+// registration transaction with the participant locked.
+type EligibilityFacts struct {
+	Participant   *Participant
+	Schedule      *Schedule
+	Registrations []*Registration // The participant's registrations that were not rejected or cancelled
+	Scores        []*Score        // The participant's released scores, latest first
+}
+
+// RuleViolation names a rule that rejected a registration
+type RuleViolation struct {
+	Code    string              `json:"code"`
+	Type    EligibilityRuleType `json:"type"`
+	Message string              `json:"message"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+)
+
+// FeedOwnerType identifies whose sessions a personal calendar feed lists
+type FeedOwnerType string
+
+const (
+	FeedOwnerStudent FeedOwnerType = "student"
+	FeedOwnerProctor FeedOwnerType = "proctor"
+)
+
+// Base model - Secret token embedded in a personal iCalendar feed URL
+type FeedToken struct {
+	ID        int64         `json:"id"`
+	Token     string        `json:"token"`
+	OwnerType FeedOwnerType `json:"owner_type"`
+	OwnerID   int64         `json:"owner_id"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// Response returned when a feed token is issued
+type FeedSubscription struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// FeedSession is a schedule as it appears in someone's personal feed
+type FeedSession struct {
+	Schedule           Schedule
+	RegistrationID     int64
+	RegNumber          string
+	RegistrationStatus string
+	AssignmentID       int64     // Proctor feeds: the assignment instead of a registration
+	Removed            bool      // Proctor feeds: the proctor was taken off the session
+	Participants       int       // Proctor feeds: approved registrations
+	UpdatedAt          time.Time // Latest change to either the schedule or the registration or assignment
+}
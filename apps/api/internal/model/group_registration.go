@@ -0,0 +1,76 @@
+package model
+
+import (
+	"time"
+)
+
+// Outcomes of one student in a group registration
+const (
+	GroupOutcomeRegistered = "registered"
+	GroupOutcomeFailed     = "failed"
+	GroupOutcomeRolledBack = "rolled_back" // Would have been registered, but an all-or-none batch failed
+)
+
+// Group invoice statuses
+const (
+	GroupInvoiceStatusPending   = "pending"
+	GroupInvoiceStatusPaid      = "paid"
+	GroupInvoiceStatusCancelled = "cancelled"
+)
+
+// Base model - A cohort registered for one schedule by a faculty coordinator
+type GroupRegistration struct {
+	ID            int64               `json:"id,omitempty"` // Zero when an all-or-none batch was rolled back
+	ScheduleID    int64               `json:"schedule_id"`
+	PlotID        int64               `json:"plot_id"`
+	CoordinatorID int64               `json:"coordinator_id"`
+	FacultyCode   string              `json:"faculty_code,omitempty"` // Empty for groups registered by an admin
+	CreatedBy     string              `json:"created_by"`
+	AllOrNone     bool                `json:"all_or_none"`
+	Requested     int                 `json:"requested"`
+	Registered    int                 `json:"registered"`
+	Failed        int                 `json:"failed"`
+	Notes         string              `json:"notes,omitempty"`
+	Results       []GroupMemberResult `json:"results,omitempty"` // In upload order; left out of lists
+	Invoice       *GroupInvoice       `json:"invoice,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+}
+
+// GroupMemberResult is the outcome report line of one uploaded student number
+type GroupMemberResult struct {
+	StudentNumber  string  `json:"student_number"`
+	StudentID      int64   `json:"student_id,omitempty"`
+	FullName       string  `json:"full_name,omitempty"`
+	Outcome        string  `json:"outcome"` // registered, failed, rolled_back
+	Reason         string  `json:"reason,omitempty"`
+	RegistrationID int64   `json:"registration_id,omitempty"`
+	RegNumber      string  `json:"reg_number,omitempty"`
+	FeeTier        string  `json:"fee_tier,omitempty"`
+	Amount         float64 `json:"amount,omitempty"` // Line amount on the group invoice
+}
+
+// Base model - One consolidated invoice for every registration of a group
+type GroupInvoice struct {
+	ID                  int64      `json:"id"`
+	GroupRegistrationID int64      `json:"group_registration_id"`
+	InvoiceNumber       string     `json:"invoice_number"` // format: G+order_of_the_month/month_in_roman/year = G001/V/2025
+	Amount              float64    `json:"amount"`
+	Status              string     `json:"status"` // pending, paid, cancelled
+	DueAt               time.Time  `json:"due_at"`
+	PaidAt              *time.Time `json:"paid_at,omitempty"`
+	PaidBy              string     `json:"paid_by,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// Create model
+type CreateGroupRegistration struct {
+	StudentNumbers []string `json:"student_numbers" validate:"required,min=1,max=500,dive,required,max=20"`
+	AllOrNone      bool     `json:"all_or_none"` // Register nobody unless every student can be registered
+	Notes          string   `json:"notes,omitempty" validate:"omitempty,max=500"`
+}
+
+// Cancel model
+type CancelGroupInvoice struct {
+	Reason string `json:"reason,omitempty" validate:"omitempty,max=500"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+)
+
+// Schedule allocation modes
+const (
+	AllocationFirstCome = "fcfs"    // Seats go to whoever registers first
+	AllocationLottery   = "lottery" // Students apply and seats are drawn when the window closes
+)
+
+// Lottery application statuses
+const (
+	LotteryStatusApplied    = "applied"    // Waiting for the draw
+	LotteryStatusWon        = "won"        // Drawn for a seat; a registration was created
+	LotteryStatusWaitlisted = "waitlisted" // Drawn after the seats ran out; placed on the waitlist in draw order
+	LotteryStatusIneligible = "ineligible" // Drawn for a seat but no longer passed the eligibility rules
+	LotteryStatusWithdrawn  = "withdrawn"
+)
+
+// Base model
+type LotteryApplication struct {
+	ID              int64     `json:"id"`
+	ScheduleID      int64     `json:"schedule_id"`
+	StudentID       int64     `json:"student_id"`
+	Status          string    `json:"status"`
+	DrawRank        *int      `json:"draw_rank,omitempty"` // Position in the draw, starting at 1
+	RegistrationID  *int64    `json:"registration_id,omitempty"`
+	WaitlistEntryID *int64    `json:"waitlist_entry_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Create model
+type ApplyLottery struct {
+	StudentID int64 `json:"student_id,omitempty"` // Admins only; students apply for themselves
+}
+
+// LotteryResult is one line of a draw, in draw order
+type LotteryResult struct {
+	Rank            int    `json:"rank"`
+	ApplicationID   int64  `json:"application_id"`
+	StudentID       int64  `json:"student_id"`
+	Outcome         string `json:"outcome"` // won, waitlisted, ineligible
+	RegistrationID  *int64 `json:"registration_id,omitempty"`
+	WaitlistEntryID *int64 `json:"waitlist_entry_id,omitempty"`
+}
+
+// Audit model - The stored outcome of a schedule's draw. Ranks are reproducible
+// from Seed by applying the algorithm to the application IDs in ascending order.
+type LotteryDraw struct {
+	ID         int64           `json:"id"`
+	ScheduleID int64           `json:"schedule_id"`
+	Seed       string          `json:"seed"`
+	Algorithm  string          `json:"algorithm"`
+	Applicants int             `json:"applicants"`
+	Winners    int             `json:"winners"`
+	Waitlisted int             `json:"waitlisted"`
+	Results    []LotteryResult `json:"results"`
+	DrawnBy    string          `json:"drawn_by"`
+	DrawnAt    time.Time       `json:"drawn_at"`
+}
+
+// LotteryVerification reports whether a stored draw can be reproduced from its seed
+type LotteryVerification struct {
+	ScheduleID int64  `json:"schedule_id"`
+	Seed       string `json:"seed"`
+	Algorithm  string `json:"algorithm"`
+	Reproduced bool   `json:"reproduced"`
+}
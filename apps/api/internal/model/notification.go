@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+)
+
+// Base model - In-app message, also delivered by email when SMTP is configured
+type Notification struct {
+	ID        int64      `json:"id"`
+	StudentID int64      `json:"student_id"`
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
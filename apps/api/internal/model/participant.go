@@ -0,0 +1,130 @@
+package model
+
+import (
+	"time"
+)
+
+// Participant types
+const (
+	ParticipantTypeStudent  = "student"  // Enrolled at the university; identified by StudentNumber
+	ParticipantTypeExternal = "external" // Public or alumni; identified by NationalID
+)
+
+// Participant profile fields a fee tier can require
+const (
+	ParticipantFieldStudentNumber = "student_number"
+	ParticipantFieldNationalID    = "national_id"
+	ParticipantFieldInstitution   = "institution"
+	ParticipantFieldMajor         = "major"
+)
+
+// Base model - Anyone who can register for a test. Registrations, waitlist entries and
+// scores reference a participant through their student_id field, whatever the type.
+type Participant struct {
+	ID            int64     `json:"id"`
+	Type          string    `json:"participant_type"`         // student, external
+	StudentNumber string    `json:"student_number,omitempty"` // Will be linked to SSO later; alumni keep theirs
+	NationalID    string    `json:"national_id,omitempty"`
+	Institution   string    `json:"institution,omitempty"` // Employer or school of an external participant
+	FullName      string    `json:"full_name"`
+	Phone         string    `json:"phone"`
+	Email         string    `json:"email"`
+	Major         string    `json:"major,omitempty"`
+	Active        bool      `json:"active"` // Currently enrolled; always false for external participants
+	FeeTier       string    `json:"fee_tier"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Field returns the value of one of the ParticipantField* profile fields
+func (p *Participant) Field(name string) string {
+	switch name {
+	case ParticipantFieldStudentNumber:
+		return p.StudentNumber
+	case ParticipantFieldNationalID:
+		return p.NationalID
+	case ParticipantFieldInstitution:
+		return p.Institution
+	case ParticipantFieldMajor:
+		return p.Major
+	}
+	return ""
+}
+
+// Create model - Which of the optional fields are required depends on the fee tier
+type CreateParticipant struct {
+	Type          string `json:"participant_type" validate:"required,oneof=student external"`
+	FeeTier       string `json:"fee_tier,omitempty" validate:"required_if=Type external,max=32"` // Defaults to the student tier for students
+	StudentNumber string `json:"student_number,omitempty" validate:"omitempty,min=8,max=20"`
+	NationalID    string `json:"national_id,omitempty" validate:"omitempty,min=5,max=32"`
+	Institution   string `json:"institution,omitempty" validate:"omitempty,max=255"`
+	FullName      string `json:"full_name" validate:"required,min=3,max=100"`
+	Phone         string `json:"phone" validate:"required,min=10,max=15"`
+	Email         string `json:"email" validate:"required,email"`
+	Major         string `json:"major,omitempty" validate:"omitempty,max=100"`
+}
+
+// Update model - Only fields that might need updating
+type UpdateParticipant struct {
+	Phone       string `json:"phone" validate:"required,min=10,max=15"`
+	Email       string `json:"email" validate:"required,email"`
+	Institution string `json:"institution,omitempty" validate:"omitempty,max=255"`
+	FeeTier     string `json:"fee_tier,omitempty" validate:"omitempty,max=32"` // Keeps the current tier when empty
+}
+
+// Base model - What a group of participants pays and which profile fields they must fill in
+type FeeTier struct {
+	Code            string             `json:"code"`
+	Name            string             `json:"name"`
+	ParticipantType string             `json:"participant_type"`
+	Amount          float64            `json:"amount"` // Charged unless Prices has an amount for the schedule type
+	Prices          map[string]float64 `json:"prices"` // Amount per schedule type (regular, subsidized)
+	RequiredFields  []string           `json:"required_fields"`
+	Active          bool               `json:"active"` // Inactive tiers cannot be assigned to new participants
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+// Price returns what the tier charges for a schedule of the given type
+func (t *FeeTier) Price(scheduleType string) float64 {
+	if price, ok := t.Prices[scheduleType]; ok {
+		return price
+	}
+	return t.Amount
+}
+
+// Create model
+type CreateFeeTier struct {
+	Code            string             `json:"code" validate:"required,max=32"`
+	Name            string             `json:"name" validate:"required,max=100"`
+	ParticipantType string             `json:"participant_type" validate:"required,oneof=student external"`
+	Amount          float64            `json:"amount" validate:"min=0"`
+	Prices          map[string]float64 `json:"prices,omitempty" validate:"dive,keys,oneof=regular subsidized,endkeys,min=0"`
+	RequiredFields  []string           `json:"required_fields" validate:"dive,oneof=student_number national_id institution major"`
+}
+
+// Update model
+type UpdateFeeTier struct {
+	Name           string             `json:"name" validate:"required,max=100"`
+	Amount         float64            `json:"amount" validate:"min=0"`
+	Prices         map[string]float64 `json:"prices,omitempty" validate:"dive,keys,oneof=regular subsidized,endkeys,min=0"`
+	RequiredFields []string           `json:"required_fields" validate:"dive,oneof=student_number national_id institution major"`
+	Active         bool               `json:"active"`
+}
+
+// Largest photo accepted for admission cards
+const MaxPhotoSize = 2 << 20
+
+// Largest side in pixels of an admission card photo; a small compressed file can
+// still declare dimensions that would take gigabytes to decode when the card is drawn
+const MaxPhotoDimension = 4000
+
+// Base model - Photo printed on admission cards; Data is served separately from the metadata
+type ParticipantPhoto struct {
+	ParticipantID int64     `json:"participant_id"`
+	ContentType   string    `json:"content_type"` // image/jpeg, image/png
+	Data          []byte    `json:"-"`
+	Width         int       `json:"width"`
+	Height        int       `json:"height"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -28,6 +28,11 @@ const (
 	PaymentStatusExpired    PaymentStatus = "expired"    // Payment expired
 	PaymentStatusFailed     PaymentStatus = "failed"     // Payment failed
 	PaymentStatusCancelled  PaymentStatus = "cancelled"  // Payment was cancelled by user
+
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially_refunded" // Part of the payment was transferred back
+	PaymentStatusRefunded          PaymentStatus = "refunded"           // The whole payment was transferred back
+
+	PaymentStatusReceivedLate PaymentStatus = "received_late" // Transferred after it expired or was cancelled; refund it or reinstate the registration
 )
 
 // Implement sql.Scanner and driver.Valuer for PaymentMethod
@@ -68,11 +73,23 @@ func (ps PaymentStatus) Value() (driver.Value, error) {
 
 // Base model
 type Payment struct {
-	ID             int64         `json:"id"`
-	RegistrationID int64         `json:"registration_id"`
-	Amount         float64       `json:"amount"`
-	PaymentMethod  PaymentMethod `json:"payment_method"`
-	PaymentStatus  PaymentStatus `json:"payment_status"`
+	ID              int64         `json:"id"`
+	RegistrationID  int64         `json:"registration_id"`
+	Amount          float64       `json:"amount"` // What is due: BaseAmount - DiscountAmount + UniqueCode
+	PaymentMethod   PaymentMethod `json:"payment_method"`
+	PaymentStatus   PaymentStatus `json:"payment_status"`
+	InvoiceNumber   string        `json:"invoice_number,omitempty"` // Sequential: I001/V/2025
+	InvoiceIssuedAt *time.Time    `json:"invoice_issued_at,omitempty"`
+
+	// Pricing, computed by the server when the payment is created
+	FeeTier        string  `json:"fee_tier,omitempty"`
+	ScheduleType   string  `json:"schedule_type,omitempty"`
+	BaseAmount     float64 `json:"base_amount"`
+	DiscountAmount float64 `json:"discount_amount"`
+	VoucherID      *int64  `json:"voucher_id,omitempty"`
+	VoucherCode    string  `json:"voucher_code,omitempty"`
+	UniqueCode     int     `json:"unique_code"`     // Added to bank transfers so the credit identifies the payment
+	RefundedAmount float64 `json:"refunded_amount"` // Sum of completed refunds
 
 	// Common fields
 	ReceiptImage string    `json:"receipt_image,omitempty"`
@@ -101,13 +118,50 @@ type Payment struct {
 	GatewayResponse      json.RawMessage `json:"gateway_response,omitempty"`
 	GatewayRedirectURL   string          `json:"gateway_redirect_url,omitempty"`
 	GatewayCallbackURL   string          `json:"gateway_callback_url,omitempty"`
+
+	Instructions *PaymentInstructions `json:"instructions,omitempty"` // Set while the payment is pending
 }
 
-// Create model for initial payment method selection
+// MaxUniqueCode is the largest offset added to a bank transfer amount
+const MaxUniqueCode = 999
+
+// UniqueCodeCooldown is how long the code of an expired or cancelled payment stays out
+// of use, so a late transfer for it is not matched to another student's payment
+const UniqueCodeCooldown = 14 * 24 * time.Hour
+
+// TransferAccount is the account students transfer their fee to
+type TransferAccount struct {
+	BankName      string `json:"bank_name"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+}
+
+// PaymentInstructions tell the student exactly what to transfer
+type PaymentInstructions struct {
+	TransferAccount
+	Amount         float64   `json:"amount"`
+	UniqueCode     int       `json:"unique_code"`
+	TransferBefore time.Time `json:"transfer_before"`
+	Notes          string    `json:"notes"`
+}
+
+// Create model for initial payment method selection; the amount is priced by the server
 type CreatePayment struct {
 	RegistrationID int64         `json:"registration_id" validate:"required"`
-	Amount         float64       `json:"amount" validate:"required,min=0"`
 	PaymentMethod  PaymentMethod `json:"payment_method" validate:"required,eq=bank_transfer"` // Only allow bank transfer
+	VoucherCode    string        `json:"voucher_code,omitempty" validate:"omitempty,max=32"`
+}
+
+// PriceQuote is what a registration costs, with the discount of an optional voucher
+type PriceQuote struct {
+	RegistrationID int64   `json:"registration_id"`
+	FeeTier        string  `json:"fee_tier"`
+	ScheduleType   string  `json:"schedule_type"`
+	BaseAmount     float64 `json:"base_amount"`
+	DiscountAmount float64 `json:"discount_amount"`
+	Amount         float64 `json:"amount"`
+	VoucherID      *int64  `json:"-"`
+	VoucherCode    string  `json:"voucher_code,omitempty"`
 }
 
 // Update model for payment processing
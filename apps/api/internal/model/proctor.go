@@ -0,0 +1,100 @@
+package model
+
+import (
+	"time"
+)
+
+// DefaultParticipantsPerProctor applies when the ratio is not configured
+const DefaultParticipantsPerProctor = 20
+
+// RequiredProctors is the minimum number of proctors for a session of the given quota;
+// every session needs at least one
+func RequiredProctors(quota, participantsPerProctor int) int {
+	if participantsPerProctor <= 0 {
+		participantsPerProctor = DefaultParticipantsPerProctor
+	}
+	return max(1, (quota+participantsPerProctor-1)/participantsPerProctor)
+}
+
+// Base model - Staff member who invigilates test sessions. Proctors sign in with tokens
+// whose subject is their proctor ID.
+type Proctor struct {
+	ID        int64     `json:"id"`
+	FullName  string    `json:"full_name"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
+	Active    bool      `json:"active"` // Inactive proctors cannot be assigned
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Create model
+type CreateProctor struct {
+	FullName string `json:"full_name" validate:"required,min=3,max=100"`
+	Email    string `json:"email" validate:"required,email"`
+	Phone    string `json:"phone" validate:"required,min=10,max=15"`
+}
+
+// Update model
+type UpdateProctor struct {
+	FullName string `json:"full_name" validate:"required,min=3,max=100"`
+	Email    string `json:"email" validate:"required,email"`
+	Phone    string `json:"phone" validate:"required,min=10,max=15"`
+	Active   bool   `json:"active"`
+}
+
+// Base model - A period in which a proctor can be assigned to sessions
+type ProctorAvailability struct {
+	ID        int64     `json:"id"`
+	ProctorID int64     `json:"proctor_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Notes     string    `json:"notes,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Create model
+type CreateProctorAvailability struct {
+	StartsAt time.Time `json:"starts_at" validate:"required"`
+	EndsAt   time.Time `json:"ends_at" validate:"required"`
+	Notes    string    `json:"notes,omitempty" validate:"omitempty,max=255"`
+}
+
+// Assign model
+type AssignProctor struct {
+	ProctorID int64 `json:"proctor_id" validate:"required"`
+}
+
+// AssignedProctor is a proctor as assigned to one session. Available and Conflicts are
+// checked again on every read since schedules and availability change after assignment.
+type AssignedProctor struct {
+	ProctorID  int64     `json:"proctor_id"`
+	FullName   string    `json:"full_name"`
+	Email      string    `json:"email"`
+	Phone      string    `json:"phone"`
+	AssignedAt time.Time `json:"assigned_at"`
+	AssignedBy string    `json:"assigned_by"`
+	Available  bool      `json:"available"`           // The session lies within one of the proctor's availability periods
+	Conflicts  []int64   `json:"conflicts,omitempty"` // Test plots of other sessions the proctor has at overlapping times
+}
+
+// ScheduleStaffing compares the proctors assigned to a session with the minimum its quota needs
+type ScheduleStaffing struct {
+	ScheduleID   int64              `json:"schedule_id"`
+	PlotID       int64              `json:"plot_id"`
+	DateTime     time.Time          `json:"date_time"`
+	Location     string             `json:"location"`
+	Status       string             `json:"status"`
+	Quota        int                `json:"quota"`
+	Required     int                `json:"required"`
+	Proctors     []*AssignedProctor `json:"proctors"`
+	Understaffed bool               `json:"understaffed"`
+	HasConflicts bool               `json:"has_conflicts"` // A proctor is unavailable or double-booked
+}
+
+// ProctorSession is an upcoming session in a proctor's own view
+type ProctorSession struct {
+	Schedule  *Schedule          `json:"schedule"`
+	Proctors  []*AssignedProctor `json:"proctors"` // Everyone assigned, including the proctor viewing
+	Attendees []*Seat            `json:"attendees"`
+}
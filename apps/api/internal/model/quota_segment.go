@@ -0,0 +1,49 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Quota segment kinds
+const (
+	SegmentKindMajor           = "major"            // Value is a Participant.Major
+	SegmentKindParticipantType = "participant_type" // Value is a participant type
+)
+
+// Base model - Seats of a schedule reserved for one major or participant type
+type QuotaSegment struct {
+	ID         int64     `json:"id"`
+	ScheduleID int64     `json:"schedule_id"`
+	Name       string    `json:"name"`
+	Kind       string    `json:"kind"` // major, participant_type
+	Value      string    `json:"value"`
+	Quota      int       `json:"quota"`
+	Available  int       `json:"available"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Matches reports whether a participant may take a seat from the segment
+func (s *QuotaSegment) Matches(participantType, major string) bool {
+	switch s.Kind {
+	case SegmentKindMajor:
+		return participantType == ParticipantTypeStudent && strings.EqualFold(strings.TrimSpace(s.Value), strings.TrimSpace(major))
+	case SegmentKindParticipantType:
+		return s.Value == participantType
+	}
+	return false
+}
+
+// Segment input of the replace model
+type QuotaSegmentInput struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Kind  string `json:"kind" validate:"required,oneof=major participant_type"`
+	Value string `json:"value" validate:"required,max=100"`
+	Quota int    `json:"quota" validate:"required,min=1"`
+}
+
+// Replace model - The complete segment list of a schedule; an empty list removes all segments
+type ReplaceQuotaSegments struct {
+	Segments []QuotaSegmentInput `json:"segments" validate:"dive"`
+}
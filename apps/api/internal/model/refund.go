@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+)
+
+// Refund statuses
+const (
+	RefundStatusRequested = "requested" // Waiting for an admin decision
+	RefundStatusApproved  = "approved"  // Approved; the transfer still has to be made
+	RefundStatusRejected  = "rejected"
+	RefundStatusCompleted = "completed" // Transferred back to the student
+)
+
+// Base model - Money to be returned from a paid payment
+type Refund struct {
+	ID             int64   `json:"id"`
+	PaymentID      int64   `json:"payment_id"`
+	RegistrationID int64   `json:"registration_id"`
+	Amount         float64 `json:"amount"`
+	Full           bool    `json:"full"` // Refunds everything that was still refundable
+	Reason         string  `json:"reason"`
+	Status         string  `json:"status"` // requested, approved, rejected, completed
+	RequestedBy    string  `json:"requested_by"`
+
+	// Destination account given by the student
+	BankName      string `json:"bank_name"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+
+	ReviewedBy  string     `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNotes string     `json:"review_notes,omitempty"`
+
+	// Transfer made by the finance office
+	TransferReference string     `json:"transfer_reference,omitempty"`
+	TransferredAt     *time.Time `json:"transferred_at,omitempty"`
+	ProofURL          string     `json:"proof_url,omitempty"`
+	CompletedBy       string     `json:"completed_by,omitempty"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Create model - Amount is left empty to refund everything still refundable
+type CreateRefund struct {
+	Amount        float64 `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	Reason        string  `json:"reason" validate:"required,max=500"`
+	BankName      string  `json:"bank_name" validate:"required,max=100"`
+	AccountNumber string  `json:"account_number" validate:"required,max=50"`
+	AccountName   string  `json:"account_name" validate:"required,max=100"`
+}
+
+// Review model - Approve or reject a requested refund
+type ReviewRefund struct {
+	Notes string `json:"notes,omitempty" validate:"omitempty,max=500"`
+}
+
+// Complete model - Details of the transfer back to the student
+type CompleteRefund struct {
+	TransferReference string    `json:"transfer_reference" validate:"required,max=100"`
+	TransferredAt     time.Time `json:"transferred_at" validate:"required"`
+	ProofURL          string    `json:"proof_url" validate:"required,url,max=500"`
+	Notes             string    `json:"notes,omitempty" validate:"omitempty,max=500"`
+}
@@ -4,21 +4,46 @@ import (
 	"time"
 )
 
+// Registration statuses
+const (
+	RegistrationStatusPending         = "pending"
+	RegistrationStatusPaymentVerified = "payment_verified"
+	RegistrationStatusApproved        = "approved"
+	RegistrationStatusRejected        = "rejected"
+	RegistrationStatusCancelled       = "cancelled"
+)
+
+// Attendance of an approved registration on the test day
+const (
+	AttendanceAttended = "attended"
+	AttendanceNoShow   = "no_show" // Not checked in by the cut-off
+)
+
 // Base model
 type Registration struct {
-	ID           int64     `json:"id"`
-	RegNumber    string    `json:"reg_number"` // Unique: format: reg_order_of_the_month/month_in_roman/year = 001/V/2025
-	StudentID    int64     `json:"student_id"`
-	TestPlotID   int64     `json:"test_plot_id"`
-	PaymentID    int64     `json:"payment_id,omitempty"`
-	Status       string    `json:"status"` // pending, payment_verified, approved, rejected
-	TestDate     time.Time `json:"test_date,omitempty"`
-	TestLocation string    `json:"test_location,omitempty"`
-	Notes        string    `json:"notes,omitempty"`
-	ApprovedAt   time.Time `json:"approved_at,omitempty"`
-	ApprovedBy   string    `json:"approved_by,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID              int64      `json:"id"`
+	RegNumber       string     `json:"reg_number"` // Unique: format: reg_order_of_the_month/month_in_roman/year = 001/V/2025
+	StudentID       int64      `json:"student_id"` // References a participant, student or external
+	TestPlotID      int64      `json:"test_plot_id"`
+	PaymentID       int64      `json:"payment_id,omitempty"`
+	Status          string     `json:"status"` // pending, payment_verified, approved, rejected, cancelled
+	TestDate        time.Time  `json:"test_date,omitempty"`
+	TestLocation    string     `json:"test_location,omitempty"`
+	Notes           string     `json:"notes,omitempty"`
+	ApprovedAt      time.Time  `json:"approved_at,omitempty"`
+	ApprovedBy      string     `json:"approved_by,omitempty"`
+	RefundRequired  bool       `json:"refund_required"` // Set when the language center cancelled the session
+	RescheduleCount int        `json:"reschedule_count"`
+	SegmentID       *int64     `json:"segment_id,omitempty"`            // Quota segment the seat was taken from
+	GroupID         *int64     `json:"group_registration_id,omitempty"` // Set when a coordinator registered a cohort
+	Attendance      string     `json:"attendance,omitempty"`            // attended, no_show; empty until the test day
+	CheckedInAt     *time.Time `json:"checked_in_at,omitempty"`
+	CheckedInBy     string     `json:"checked_in_by,omitempty"`
+	SeatNumber      *int       `json:"seat_number,omitempty"`    // Assigned once registration closes
+	TestFormCode    string     `json:"test_form_code,omitempty"` // Overrides the session's test form for this seat
+	PaymentDueAt    *time.Time `json:"payment_due_at,omitempty"` // Cancelled after this unless a payment is open; unset for group members
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 // History model for registration status changes
@@ -33,8 +58,20 @@ type RegistrationHistory struct {
 
 // Create model
 type CreateRegistration struct {
-	StudentID  int64 `json:"student_id" validate:"required"`
-	TestPlotID int64 `json:"test_plot_id" validate:"required"`
+	StudentID           int64  `json:"student_id" validate:"required"`
+	TestPlotID          int64  `json:"test_plot_id" validate:"required"`
+	OverrideEligibility bool   `json:"override_eligibility,omitempty"` // Admins only: register despite failed eligibility rules
+	OverrideReason      string `json:"override_reason,omitempty" validate:"required_if=OverrideEligibility true,max=500"`
+}
+
+// Cancel model
+type CancelRegistration struct {
+	Reason string `json:"reason,omitempty" validate:"omitempty,max=500"`
+}
+
+// Approve model
+type ApproveRegistration struct {
+	Notes string `json:"notes,omitempty" validate:"omitempty,max=500"`
 }
 
 // Update model
@@ -53,3 +90,34 @@ type CreateRegistrationHistory struct {
 	Notes          string `json:"notes,omitempty"`
 	ChangedBy      string `json:"changed_by" validate:"required"`
 }
+
+// Reschedule model - Move a registration to another test plot
+type RescheduleRegistration struct {
+	TargetScheduleID int64  `json:"target_schedule_id" validate:"required"`
+	Reason           string `json:"reason,omitempty" validate:"omitempty,max=500"`
+}
+
+// Check-in model - The signed code from an admission card's QR code
+type CheckIn struct {
+	Code string `json:"code" validate:"required,max=128"`
+}
+
+// CheckInResult is shown to the proctor after a scan so they can compare the photo
+// and name with the person at the door
+type CheckInResult struct {
+	Registration *Registration      `json:"registration"`
+	Participant  *Participant       `json:"participant"`
+	Attendance   *AttendanceSummary `json:"attendance"`
+}
+
+// AttendanceSummary counts the approved registrations of a session by attendance
+type AttendanceSummary struct {
+	ScheduleID      int64     `json:"schedule_id"`
+	PlotID          int64     `json:"plot_id"`
+	DateTime        time.Time `json:"date_time"`
+	CheckInClosesAt time.Time `json:"check_in_closes_at"` // Participants not checked in by then become no-shows
+	Expected        int       `json:"expected"`
+	CheckedIn       int       `json:"checked_in"`
+	NoShow          int       `json:"no_show"`
+	Remaining       int       `json:"remaining"` // Not checked in yet
+}
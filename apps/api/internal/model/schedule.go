@@ -4,15 +4,54 @@ import (
 	"time"
 )
 
+// Schedule statuses
+const (
+	ScheduleStatusActive    = "active"
+	ScheduleStatusCancelled = "cancelled"
+)
+
+// Registration window statuses, derived from the window and the test time
+const (
+	RegistrationWindowUpcoming = "upcoming"
+	RegistrationWindowOpen     = "open"
+	RegistrationWindowClosed   = "closed"
+)
+
 type Schedule struct {
-	ID        int64     `json:"id"`
-	PlotID    int64     `json:"plot_id"`
-	DateTime  time.Time `json:"date_time"`
-	Location  string    `json:"location"`
-	Quota     int       `json:"quota"`
-	Available int       `json:"available"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                 int64      `json:"id"`
+	PlotID             int64      `json:"plot_id"`
+	DateTime           time.Time  `json:"date_time"`
+	Location           string     `json:"location"`
+	Quota              int        `json:"quota"`
+	Available          int        `json:"available"`
+	TemplateID         *int64     `json:"template_id,omitempty"` // Set when generated from a schedule template
+	Status             string     `json:"status"`                // active, cancelled
+	Subsidized         bool       `json:"subsidized"`            // Restricted by the subsidized_majors eligibility rules
+	AllocationMode     string     `json:"allocation_mode"`       // fcfs, lottery
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty"`
+	CancellationReason string     `json:"cancellation_reason,omitempty"`
+	// Registration window; registration stays open until the test starts when no closing time is set
+	RegistrationOpensAt  *time.Time     `json:"registration_opens_at,omitempty"`
+	RegistrationClosesAt *time.Time     `json:"registration_closes_at,omitempty"`
+	RegistrationClosedAt *time.Time     `json:"registration_closed_at,omitempty"` // When admins were sent the final counts
+	RegistrationStatus   string         `json:"registration_status"`              // upcoming, open, closed
+	Segments             []QuotaSegment `json:"segments,omitempty"`               // Reserved seats; Available still counts every free seat
+	TestFormCode         string         `json:"test_form_code,omitempty"`         // Test booklet version used at the session
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+}
+
+// RegistrationStatusAt derives the registration window status at the given time
+func (s *Schedule) RegistrationStatusAt(now time.Time) string {
+	switch {
+	case s.Status == ScheduleStatusCancelled, !now.Before(s.DateTime):
+		return RegistrationWindowClosed
+	case s.RegistrationClosesAt != nil && !now.Before(*s.RegistrationClosesAt):
+		return RegistrationWindowClosed
+	case s.RegistrationOpensAt != nil && now.Before(*s.RegistrationOpensAt):
+		return RegistrationWindowUpcoming
+	}
+	return RegistrationWindowOpen
 }
 
 type ListSchedule []Schedule
@@ -26,13 +65,56 @@ type PaginatedResponse struct {
 }
 
 type CreateSchedule struct {
-	DateTime time.Time `json:"date_time" validate:"required,notpastdate"`
-	Location string    `json:"location" validate:"required"`
-	Quota    int       `json:"quota" validate:"required"`
+	DateTime                time.Time  `json:"date_time" validate:"required,notpastdate"`
+	Location                string     `json:"location" validate:"required"`
+	Quota                   int        `json:"quota" validate:"required"`
+	RegistrationOpensAt     *time.Time `json:"registration_opens_at,omitempty"`     // Keeps the current opening time when absent
+	RegistrationClosesAt    *time.Time `json:"registration_closes_at,omitempty"`    // Keeps the current closing time when absent
+	ClearRegistrationWindow bool       `json:"clear_registration_window,omitempty"` // Removes both times; registration then stays open until the test
+	Subsidized              bool       `json:"subsidized,omitempty"`
+	AllocationMode          string     `json:"allocation_mode,omitempty" validate:"omitempty,oneof=fcfs lottery"` // Defaults to fcfs
+	OverrideCalendar        bool       `json:"override_calendar,omitempty"`                                       // super_admin only: allow holidays and blackout dates
 }
 
 type UpdateSchedule struct {
-	DateTime time.Time `json:"date_time" validate:"omitempty,notpastdate"`
-	Location string    `json:"location" validate:"omitempty"`
-	Quota    int       `json:"quota" validate:"omitempty"`
+	DateTime                time.Time  `json:"date_time" validate:"omitempty,notpastdate"`
+	Location                string     `json:"location" validate:"omitempty"`
+	Quota                   int        `json:"quota" validate:"omitempty"`
+	RegistrationOpensAt     *time.Time `json:"registration_opens_at,omitempty"`                                   // Keeps the current opening time when absent
+	RegistrationClosesAt    *time.Time `json:"registration_closes_at,omitempty"`                                  // Keeps the current closing time when absent
+	ClearRegistrationWindow bool       `json:"clear_registration_window,omitempty"`                               // Removes both times; registration then stays open until the test
+	Subsidized              *bool      `json:"subsidized,omitempty"`                                              // Keeps the current value when absent
+	AllocationMode          string     `json:"allocation_mode,omitempty" validate:"omitempty,oneof=fcfs lottery"` // Keeps the current mode when empty
+	OverrideCalendar        bool       `json:"override_calendar,omitempty"`                                       // super_admin only: allow holidays and blackout dates
+}
+
+// RegistrationSummary is the final head count sent to admins when a registration window closes
+type RegistrationSummary struct {
+	Schedule     *Schedule      `json:"schedule"`
+	Registered   int            `json:"registered"` // Registrations that still hold a seat
+	StatusCounts map[string]int `json:"status_counts"`
+	Waitlisted   int            `json:"waitlisted"`
+}
+
+// Cancel model - What happens to the registrations of a cancelled schedule
+type CancelSchedule struct {
+	Reason           string `json:"reason" validate:"required,max=500"`
+	Action           string `json:"action" validate:"required,oneof=transfer cancel"` // transfer registrants or cancel them with a refund
+	TargetScheduleID int64  `json:"target_schedule_id" validate:"required_if=Action transfer"`
+}
+
+// AffectedRegistration reports what happened to one registration of a cancelled schedule
+type AffectedRegistration struct {
+	RegistrationID int64  `json:"registration_id"`
+	RegNumber      string `json:"reg_number"`
+	StudentID      int64  `json:"student_id"`
+	Status         string `json:"status"`
+	RefundRequired bool   `json:"refund_required"`
+}
+
+type CancelScheduleResult struct {
+	Schedule              *Schedule              `json:"schedule"`
+	Action                string                 `json:"action"`
+	TargetSchedule        *Schedule              `json:"target_schedule,omitempty"`
+	AffectedRegistrations []AffectedRegistration `json:"affected_registrations"`
 }
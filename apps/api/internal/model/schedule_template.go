@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+)
+
+// Base model - Recurring rule used to generate schedules in bulk
+type ScheduleTemplate struct {
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	Location      string    `json:"location"`
+	Quota         int       `json:"quota"`
+	Weekdays      []int     `json:"weekdays"` // 0 = Sunday ... 6 = Saturday
+	Times         []string  `json:"times"`    // Local start times in HH:MM, e.g. ["08:00", "13:00"]
+	Timezone      string    `json:"timezone"` // IANA name, e.g. Asia/Jakarta
+	StartDate     Date      `json:"start_date"`
+	EndDate       Date      `json:"end_date"`
+	ExcludedDates []Date    `json:"excluded_dates"` // Holidays skipped by this template only
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Create model
+type CreateScheduleTemplate struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Location      string   `json:"location" validate:"required"`
+	Quota         int      `json:"quota" validate:"required,min=1"`
+	Weekdays      []int    `json:"weekdays" validate:"required,min=1,dive,min=0,max=6"`
+	Times         []string `json:"times" validate:"required,min=1,dive,clocktime"`
+	Timezone      string   `json:"timezone" validate:"omitempty,timezone"`
+	StartDate     Date     `json:"start_date"`
+	EndDate       Date     `json:"end_date"`
+	ExcludedDates []Date   `json:"excluded_dates"`
+}
+
+// Update model
+type UpdateScheduleTemplate struct {
+	Name          string   `json:"name" validate:"omitempty,max=100"`
+	Location      string   `json:"location" validate:"omitempty"`
+	Quota         int      `json:"quota" validate:"omitempty,min=1"`
+	Weekdays      []int    `json:"weekdays" validate:"omitempty,min=1,dive,min=0,max=6"`
+	Times         []string `json:"times" validate:"omitempty,min=1,dive,clocktime"`
+	Timezone      string   `json:"timezone" validate:"omitempty,timezone"`
+	StartDate     Date     `json:"start_date"`
+	EndDate       Date     `json:"end_date"`
+	ExcludedDates []Date   `json:"excluded_dates"`
+}
+
+// ScheduleOccurrence is a single session produced by expanding a template
+type ScheduleOccurrence struct {
+	DateTime   time.Time `json:"date_time"`
+	Location   string    `json:"location"`
+	Quota      int       `json:"quota"`
+	Skipped    bool      `json:"skipped"`
+	SkipReason string    `json:"skip_reason,omitempty"`
+	ScheduleID int64     `json:"schedule_id,omitempty"` // Set once the schedule has been created
+}
+
+// Result of a generate or preview run
+type GenerateSchedulesResult struct {
+	TemplateID  int64                `json:"template_id"`
+	DryRun      bool                 `json:"dry_run"`
+	Created     int                  `json:"created"`
+	Skipped     int                  `json:"skipped"`
+	Occurrences []ScheduleOccurrence `json:"occurrences"`
+}
@@ -1,34 +1,115 @@
 package model
 
 import (
+	"math"
 	"time"
 )
 
+// Test sections and the number of questions in each
+const (
+	SectionListening = "listening"
+	SectionStructure = "structure"
+	SectionReading   = "reading"
+
+	ListeningQuestions = 50
+	StructureQuestions = 40
+	ReadingQuestions   = 50
+)
+
+// Score statuses; students only see released scores
+const (
+	ScoreStatusDraft    = "draft"
+	ScoreStatusReleased = "released"
+)
+
+// Score validity statuses
+const (
+	ScoreValidityValid   = "valid"
+	ScoreValidityExpired = "expired"
+)
+
+// DefaultScoreValidityMonths is how long a score is accepted after the test
+const DefaultScoreValidityMonths = 24
+
+// DefaultScoreExpiryWindowDays is how far ahead expiring scores are looked for
+const DefaultScoreExpiryWindowDays = 60
+
+// TotalScore combines the three scaled section scores into the reported score
+func TotalScore(listening, structure, reading int) int {
+	return int(math.Round(float64(listening+structure+reading) * 10 / 3))
+}
+
 // Base model
 type Score struct {
-	ID                         int64     `json:"id"`
-	StudentID                  int64     `json:"student_id"`
-	TestPlotID                 int64     `json:"test_plot_id"` // format: year+month+date+order = 20250501001
-	ListeningComprehension     int       `json:"listening_comprehension" validate:"min=0,max=68"`
-	StructureWrittenExpression int       `json:"structure_written_expression" validate:"min=0,max=68"`
-	ReadingComprehension       int       `json:"reading_comprehension" validate:"min=0,max=67"`
-	TotalScore                 int       `json:"total_score" validate:"min=0,max=677"` // Calculated field: (listening + structure + reading) * 10/3
-	CreatedAt                  time.Time `json:"created_at"`
-	UpdatedAt                  time.Time `json:"updated_at"`
-}
-
-// Create model
+	ID                         int64      `json:"id"`
+	RegistrationID             int64      `json:"registration_id"`
+	StudentID                  int64      `json:"student_id"`     // References a participant, student or external
+	TestPlotID                 int64      `json:"test_plot_id"`   // format: year+month+date+order = 20250501001
+	TestFormCode               string     `json:"test_form_code"` // Form whose conversion table scaled the score
+	ListeningCorrect           int        `json:"listening_correct"`
+	StructureCorrect           int        `json:"structure_correct"`
+	ReadingCorrect             int        `json:"reading_correct"`
+	ListeningComprehension     int        `json:"listening_comprehension" validate:"min=0,max=68"`
+	StructureWrittenExpression int        `json:"structure_written_expression" validate:"min=0,max=68"`
+	ReadingComprehension       int        `json:"reading_comprehension" validate:"min=0,max=67"`
+	TotalScore                 int        `json:"total_score" validate:"min=0,max=677"` // Calculated field: (listening + structure + reading) * 10/3
+	RecordedBy                 string     `json:"recorded_by"`
+	Status                     string     `json:"status"` // draft, released
+	ReleasedAt                 *time.Time `json:"released_at,omitempty"`
+	ReleasedBy                 string     `json:"released_by,omitempty"`
+	ValidUntil                 time.Time  `json:"valid_until"` // Fixed when the score is recorded
+	Validity                   string     `json:"validity"`    // valid, expired
+	CreatedAt                  time.Time  `json:"created_at"`
+	UpdatedAt                  time.Time  `json:"updated_at"`
+}
+
+// ValidAt reports whether the score is still accepted at the given time
+func (s *Score) ValidAt(t time.Time) bool {
+	return t.Before(s.ValidUntil)
+}
+
+// Create model - Raw scores are the questions answered correctly; the scaled scores
+// come from the conversion table of the form the participant took
 type CreateScore struct {
-	StudentID                  int64 `json:"student_id" validate:"required"`
-	TestPlotID                 int64 `json:"test_plot_id" validate:"required"`
-	ListeningComprehension     int   `json:"listening_comprehension" validate:"required,min=0,max=68"`
-	StructureWrittenExpression int   `json:"structure_written_expression" validate:"required,min=0,max=68"`
-	ReadingComprehension       int   `json:"reading_comprehension" validate:"required,min=0,max=67"`
+	RegistrationID   int64 `json:"registration_id" validate:"required"`
+	ListeningCorrect int   `json:"listening_correct" validate:"min=0,max=50"`
+	StructureCorrect int   `json:"structure_correct" validate:"min=0,max=40"`
+	ReadingCorrect   int   `json:"reading_correct" validate:"min=0,max=50"`
 }
 
-// Update model
+// Update model - Corrects the raw scores; the scaled scores are computed again and the
+// change is kept as a revision
 type UpdateScore struct {
-	ListeningComprehension     int `json:"listening_comprehension,omitempty" validate:"omitempty,min=0,max=68"`
-	StructureWrittenExpression int `json:"structure_written_expression,omitempty" validate:"omitempty,min=0,max=68"`
-	ReadingComprehension       int `json:"reading_comprehension,omitempty" validate:"omitempty,min=0,max=67"`
+	ListeningCorrect int    `json:"listening_correct" validate:"min=0,max=50"`
+	StructureCorrect int    `json:"structure_correct" validate:"min=0,max=40"`
+	ReadingCorrect   int    `json:"reading_correct" validate:"min=0,max=50"`
+	Reason           string `json:"reason" validate:"required,max=1000"`
+}
+
+// ExpiringScore is a participant's latest released score that expires soon
+type ExpiringScore struct {
+	Score    *Score `json:"score"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	DaysLeft int    `json:"days_left"`
+}
+
+// ScoreRelease is the release state of a schedule's scores
+type ScoreRelease struct {
+	ScheduleID  int64      `json:"schedule_id"`
+	PlotID      int64      `json:"plot_id"`
+	DateTime    time.Time  `json:"date_time"`
+	Location    string     `json:"location"`
+	Drafts      int        `json:"drafts"`
+	Released    int        `json:"released"`
+	ReleaseAt   *time.Time `json:"release_at,omitempty"` // Scheduled release; kept after it runs to pick up late scores
+	ScheduledBy string     `json:"scheduled_by,omitempty"`
+	ReleasedAt  *time.Time `json:"released_at,omitempty"` // Last release
+	ReleasedBy  string     `json:"released_by,omitempty"`
+}
+
+// Schedule model - Releases the draft scores of a schedule at the given time
+type ScheduleScoreRelease struct {
+	ReleaseAt time.Time `json:"release_at" validate:"required"`
 }
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"time"
+)
+
+// Score appeal statuses
+const (
+	AppealStatusPending  = "pending"
+	AppealStatusAccepted = "accepted" // The score was corrected
+	AppealStatusRejected = "rejected" // The score stands
+)
+
+// Certificate statuses reported when a verification code is checked
+const (
+	CertificateStatusCurrent = "current"
+	CertificateStatusRevoked = "revoked" // Replaced after the score was corrected
+)
+
+// Base model - A student's request to have a released score checked again
+type ScoreAppeal struct {
+	ID         int64      `json:"id"`
+	ScoreID    int64      `json:"score_id"`
+	StudentID  int64      `json:"student_id"`
+	Reason     string     `json:"reason"`
+	Status     string     `json:"status"` // pending, accepted, rejected
+	ReviewNote string     `json:"review_note,omitempty"`
+	ReviewedBy string     `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Create model
+type CreateScoreAppeal struct {
+	Reason string `json:"reason" validate:"required,max=1000"`
+}
+
+// Review model - Accepting an appeal re-scores it with the corrected raw scores
+type ReviewScoreAppeal struct {
+	Decision   string           `json:"decision" validate:"required,oneof=accepted rejected"`
+	Note       string           `json:"note" validate:"required,max=1000"` // Shown to the student
+	Correction *ScoreCorrection `json:"correction,omitempty" validate:"required_if=Decision accepted"`
+}
+
+// ScoreCorrection holds the raw scores found when a score is checked again
+type ScoreCorrection struct {
+	ListeningCorrect int `json:"listening_correct" validate:"min=0,max=50"`
+	StructureCorrect int `json:"structure_correct" validate:"min=0,max=40"`
+	ReadingCorrect   int `json:"reading_correct" validate:"min=0,max=50"`
+}
+
+// ScoreValues are the values of a score a revision records
+type ScoreValues struct {
+	TestFormCode               string `json:"test_form_code"`
+	ListeningCorrect           int    `json:"listening_correct"`
+	StructureCorrect           int    `json:"structure_correct"`
+	ReadingCorrect             int    `json:"reading_correct"`
+	ListeningComprehension     int    `json:"listening_comprehension"`
+	StructureWrittenExpression int    `json:"structure_written_expression"`
+	ReadingComprehension       int    `json:"reading_comprehension"`
+	TotalScore                 int    `json:"total_score"`
+}
+
+// Values returns the current values of the score
+func (s *Score) Values() ScoreValues {
+	return ScoreValues{
+		TestFormCode:               s.TestFormCode,
+		ListeningCorrect:           s.ListeningCorrect,
+		StructureCorrect:           s.StructureCorrect,
+		ReadingCorrect:             s.ReadingCorrect,
+		ListeningComprehension:     s.ListeningComprehension,
+		StructureWrittenExpression: s.StructureWrittenExpression,
+		ReadingComprehension:       s.ReadingComprehension,
+		TotalScore:                 s.TotalScore,
+	}
+}
+
+// ScoreRevision is an immutable record of one change to a score
+type ScoreRevision struct {
+	ID        int64       `json:"id"`
+	ScoreID   int64       `json:"score_id"`
+	AppealID  *int64      `json:"appeal_id,omitempty"` // Set when the change resolved an appeal
+	Old       ScoreValues `json:"old"`
+	New       ScoreValues `json:"new"`
+	Reason    string      `json:"reason"`
+	ChangedBy string      `json:"changed_by"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// ScoreCertificate is issued when a score is released and re-issued with a new
+// verification code whenever the score is corrected
+type ScoreCertificate struct {
+	ID               int64      `json:"id"`
+	ScoreID          int64      `json:"score_id"`
+	VerificationCode string     `json:"verification_code"`
+	IssuedBy         string     `json:"issued_by"`
+	IssuedAt         time.Time  `json:"issued_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason    string     `json:"revoked_reason,omitempty"`
+}
+
+// CertificateVerification is what anyone holding a verification code may see
+type CertificateVerification struct {
+	VerificationCode           string     `json:"verification_code"`
+	Status                     string     `json:"status"` // current, revoked
+	IssuedAt                   time.Time  `json:"issued_at"`
+	RevokedAt                  *time.Time `json:"revoked_at,omitempty"`
+	FullName                   string     `json:"full_name"`
+	TestPlotID                 int64      `json:"test_plot_id"`
+	TestDate                   time.Time  `json:"test_date"`
+	ListeningComprehension     int        `json:"listening_comprehension"`
+	StructureWrittenExpression int        `json:"structure_written_expression"`
+	ReadingComprehension       int        `json:"reading_comprehension"`
+	TotalScore                 int        `json:"total_score"`
+	ValidUntil                 time.Time  `json:"valid_until"`
+	Validity                   string     `json:"validity"` // valid, expired
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Seating modes
+const (
+	SeatingSequential = "sequential" // In registration number order
+	SeatingRandom     = "random"
+)
+
+// DefaultSeatsPerRow applies to schedules whose seating was never configured
+const DefaultSeatsPerRow = 10
+
+// SeatLabel names a seat by its row letter and position from the left, e.g. seat 12
+// with ten seats to a row is B02
+func SeatLabel(number, seatsPerRow int) string {
+	if number <= 0 || seatsPerRow <= 0 {
+		return ""
+	}
+	row := (number - 1) / seatsPerRow
+	var letters string
+	for row >= 0 {
+		letters = string(rune('A'+row%26)) + letters
+		row = row/26 - 1
+	}
+	return fmt.Sprintf("%s%02d", letters, (number-1)%seatsPerRow+1)
+}
+
+// Base model - How a schedule's seats are arranged
+type SeatingPlan struct {
+	ScheduleID  int64      `json:"schedule_id"`
+	PlotID      int64      `json:"plot_id"`
+	DateTime    time.Time  `json:"date_time"`
+	Location    string     `json:"location"`
+	Mode        string     `json:"mode"` // sequential, random
+	SeatsPerRow int        `json:"seats_per_row"`
+	AssignedAt  *time.Time `json:"assigned_at,omitempty"`
+	AssignedBy  string     `json:"assigned_by,omitempty"`
+	Seats       []*Seat    `json:"seats"` // Active registrations by seat; unseated ones last
+}
+
+// Seat is one registration of the plan. Number is 0 for registrations that came in
+// after seats were assigned.
+type Seat struct {
+	Number         int    `json:"number,omitempty"`
+	Label          string `json:"label,omitempty"`
+	RegistrationID int64  `json:"registration_id"`
+	RegNumber      string `json:"reg_number"`
+	StudentID      int64  `json:"student_id"`
+	FullName       string `json:"full_name"`
+	Identity       string `json:"identity"` // Student number or national ID
+	Major          string `json:"major,omitempty"`
+	Status         string `json:"status"`
+	Attendance     string `json:"attendance,omitempty"`
+}
+
+// Update model
+type UpdateSeating struct {
+	Mode        string `json:"mode" validate:"required,oneof=sequential random"`
+	SeatsPerRow int    `json:"seats_per_row" validate:"required,min=1,max=12"`
+}
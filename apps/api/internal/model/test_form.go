@@ -0,0 +1,113 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Range of scaled section scores a conversion table can give
+const (
+	MinScaledScore     = 31
+	MaxScaledListening = 68
+	MaxScaledStructure = 68
+	MaxScaledReading   = 67
+)
+
+// Base model - A test booklet version; forms are rotated between sessions so
+// participants do not see the same questions twice in a row
+type TestForm struct {
+	Code      string    `json:"code"` // Stored upper case
+	Name      string    `json:"name"`
+	Active    bool      `json:"active"`    // Inactive forms cannot be given to new sessions
+	HasTable  bool      `json:"has_table"` // A conversion table was uploaded; forms without one cannot be used
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Create model
+type CreateTestForm struct {
+	Code string `json:"code" validate:"required,alphanum,max=32"`
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// Update model
+type UpdateTestForm struct {
+	Name   string `json:"name" validate:"required,max=100"`
+	Active bool   `json:"active"`
+}
+
+// ConversionTable maps the raw score of each section, the index, to its scaled score
+type ConversionTable struct {
+	FormCode  string `json:"form_code"`
+	Listening []int  `json:"listening" validate:"len=51"`
+	Structure []int  `json:"structure" validate:"len=41"`
+	Reading   []int  `json:"reading" validate:"len=51"`
+}
+
+// Check rejects tables whose scaled scores are out of range or drop as the raw score rises
+func (t *ConversionTable) Check() error {
+	sections := []struct {
+		name   string
+		scores []int
+		max    int
+	}{
+		{SectionListening, t.Listening, MaxScaledListening},
+		{SectionStructure, t.Structure, MaxScaledStructure},
+		{SectionReading, t.Reading, MaxScaledReading},
+	}
+	for _, section := range sections {
+		for raw, scaled := range section.scores {
+			if scaled < MinScaledScore || scaled > section.max {
+				return fmt.Errorf("invalid conversion table: %s raw score %d converts to %d, outside %d-%d",
+					section.name, raw, scaled, MinScaledScore, section.max)
+			}
+			if raw > 0 && scaled < section.scores[raw-1] {
+				return fmt.Errorf("invalid conversion table: %s raw score %d converts lower than raw score %d",
+					section.name, raw, raw-1)
+			}
+		}
+	}
+	return nil
+}
+
+// Scale converts raw section scores into a score, setting the scaled and total scores
+func (t *ConversionTable) Scale(score *Score) {
+	score.TestFormCode = t.FormCode
+	score.ListeningComprehension = t.Listening[score.ListeningCorrect]
+	score.StructureWrittenExpression = t.Structure[score.StructureCorrect]
+	score.ReadingComprehension = t.Reading[score.ReadingCorrect]
+	score.TotalScore = TotalScore(score.ListeningComprehension, score.StructureWrittenExpression, score.ReadingComprehension)
+}
+
+// Assign model - Sets the form of a session. Participants who took FormCode at their
+// previous attempt get AlternateFormCode instead; it is required when there are any.
+type AssignTestForm struct {
+	FormCode          string `json:"form_code" validate:"required,max=32"`
+	AlternateFormCode string `json:"alternate_form_code,omitempty" validate:"omitempty,max=32"`
+}
+
+// Assign model - Sets the form of one seat; an empty code falls back to the session's form
+type AssignSeatTestForm struct {
+	FormCode string `json:"form_code" validate:"omitempty,max=32"`
+}
+
+// SessionTestForms lists the form each participant of a session gets
+type SessionTestForms struct {
+	ScheduleID int64           `json:"schedule_id"`
+	PlotID     int64           `json:"plot_id"`
+	FormCode   string          `json:"form_code,omitempty"` // Session's form; empty until assigned
+	Seats      []*SeatTestForm `json:"seats"`
+	Conflicts  int             `json:"conflicts"` // Seats whose form is the one taken at the previous attempt
+}
+
+// SeatTestForm is the form of one registration of a session
+type SeatTestForm struct {
+	RegistrationID   int64  `json:"registration_id"`
+	RegNumber        string `json:"reg_number"`
+	StudentID        int64  `json:"student_id"`
+	FullName         string `json:"full_name"`
+	FormCode         string `json:"form_code,omitempty"` // Effective form: the seat's own or the session's
+	Override         bool   `json:"override"`            // The seat has its own form
+	PreviousFormCode string `json:"previous_form_code,omitempty"`
+	Conflict         bool   `json:"conflict"`
+}
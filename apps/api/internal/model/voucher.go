@@ -0,0 +1,74 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// Schedule types fee tiers can be priced by
+const (
+	ScheduleTypeRegular    = "regular"
+	ScheduleTypeSubsidized = "subsidized"
+)
+
+// ScheduleType returns which price of a fee tier applies to the schedule
+func (s *Schedule) ScheduleType() string {
+	if s.Subsidized {
+		return ScheduleTypeSubsidized
+	}
+	return ScheduleTypeRegular
+}
+
+// Voucher discount types
+const (
+	DiscountPercentage = "percentage" // DiscountValue is a percentage of the base amount
+	DiscountFixed      = "fixed"      // DiscountValue is deducted from the base amount
+)
+
+// Base model - A discount code students can enter when paying
+type Voucher struct {
+	ID            int64      `json:"id"`
+	Code          string     `json:"code"` // Stored upper case; matched case-insensitively
+	Description   string     `json:"description,omitempty"`
+	DiscountType  string     `json:"discount_type"` // percentage, fixed
+	DiscountValue float64    `json:"discount_value"`
+	MaxUses       *int       `json:"max_uses,omitempty"` // Unlimited when empty
+	UsedCount     int        `json:"used_count"`         // Payments holding the voucher; expired payments give their use back
+	ValidFrom     *time.Time `json:"valid_from,omitempty"`
+	ValidUntil    *time.Time `json:"valid_until,omitempty"`
+	FeeTiers      []string   `json:"fee_tiers"` // Restricts the voucher to these tiers; empty allows every tier
+	Active        bool       `json:"active"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Discount returns how much the voucher takes off the base amount, never more than the base
+func (v *Voucher) Discount(base float64) float64 {
+	discount := v.DiscountValue
+	if v.DiscountType == DiscountPercentage {
+		discount = math.Round(base*v.DiscountValue) / 100
+	}
+	return math.Min(discount, base)
+}
+
+// Create model
+type CreateVoucher struct {
+	Code          string     `json:"code" validate:"required,alphanum,max=32"`
+	Description   string     `json:"description,omitempty" validate:"omitempty,max=255"`
+	DiscountType  string     `json:"discount_type" validate:"required,oneof=percentage fixed"`
+	DiscountValue float64    `json:"discount_value" validate:"required,gt=0"`
+	MaxUses       *int       `json:"max_uses,omitempty" validate:"omitempty,min=1"`
+	ValidFrom     *time.Time `json:"valid_from,omitempty"`
+	ValidUntil    *time.Time `json:"valid_until,omitempty"`
+	FeeTiers      []string   `json:"fee_tiers,omitempty" validate:"dive,required,max=32"`
+}
+
+// Update model - The code and discount stay fixed once payments may reference them
+type UpdateVoucher struct {
+	Description string     `json:"description,omitempty" validate:"omitempty,max=255"`
+	MaxUses     *int       `json:"max_uses,omitempty" validate:"omitempty,min=1"`
+	ValidFrom   *time.Time `json:"valid_from,omitempty"`
+	ValidUntil  *time.Time `json:"valid_until,omitempty"`
+	FeeTiers    []string   `json:"fee_tiers,omitempty" validate:"dive,required,max=32"`
+	Active      bool       `json:"active"`
+}
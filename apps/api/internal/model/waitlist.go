@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+)
+
+// Waitlist entry statuses
+const (
+	WaitlistStatusWaiting    = "waiting"    // In line for a seat
+	WaitlistStatusOffered    = "offered"    // A seat is held until OfferExpiresAt
+	WaitlistStatusClaimed    = "claimed"    // The held seat became a registration
+	WaitlistStatusExpired    = "expired"    // The offer lapsed or the schedule was cancelled
+	WaitlistStatusIneligible = "ineligible" // Failed the eligibility rules when claiming the offer
+	WaitlistStatusLeft       = "left"       // The student withdrew
+)
+
+// Base model
+type WaitlistEntry struct {
+	ID             int64      `json:"id"`
+	ScheduleID     int64      `json:"schedule_id"`
+	PlotID         int64      `json:"plot_id"`
+	DateTime       time.Time  `json:"date_time"`
+	StudentID      int64      `json:"student_id"`
+	Position       int        `json:"position,omitempty"` // Current place in line while waiting, starting at 1
+	Status         string     `json:"status"`
+	OfferedAt      *time.Time `json:"offered_at,omitempty"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"`
+	RegistrationID *int64     `json:"registration_id,omitempty"`
+	SegmentID      *int64     `json:"segment_id,omitempty"` // Quota segment of the held seat
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Create model
+type JoinWaitlist struct {
+	StudentID int64 `json:"student_id,omitempty"` // Admins only; students join for themselves
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type AttendanceRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAttendanceRepository(db *pgxpool.Pool) *AttendanceRepository {
+	return &AttendanceRepository{db: db}
+}
+
+// CheckIn marks a registration as attended at the session being proctored. plotID is
+// the test plot named by the admission card, which must be the session's own. Check-in
+// is open from opens before the test starts until cutoff after it.
+func (r *AttendanceRepository) CheckIn(ctx context.Context, scheduleID, registrationID, plotID int64, opens, cutoff time.Duration, checkedInBy string) (*model.Registration, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	registration, err := lockRegistration(ctx, tx, registrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		schedulePlotID int64
+		startsAt       time.Time
+		scheduleStatus string
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT plot_id, date_time, status FROM schedules WHERE id = $1
+	`, scheduleID).Scan(&schedulePlotID, &startsAt, &scheduleStatus)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("schedule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+
+	opensAt, closesAt := startsAt.Add(-opens), startsAt.Add(cutoff)
+	switch {
+	case plotID != schedulePlotID:
+		return nil, fmt.Errorf("invalid admission code: the card is for another session")
+	case registration.TestPlotID != schedulePlotID:
+		// The card predates a reschedule
+		return nil, fmt.Errorf("invalid admission code: the registration was moved to another session")
+	case scheduleStatus == model.ScheduleStatusCancelled:
+		return nil, fmt.Errorf("cannot check in: session is cancelled")
+	case registration.Status != model.RegistrationStatusApproved:
+		return nil, fmt.Errorf("cannot check in: registration is %s", registration.Status)
+	case registration.Attendance == model.AttendanceAttended:
+		return nil, fmt.Errorf("cannot check in: already checked in at %s by %s",
+			registration.CheckedInAt.Format("15:04"), registration.CheckedInBy)
+	case registration.Attendance == model.AttendanceNoShow, time.Now().After(closesAt):
+		return nil, fmt.Errorf("cannot check in: check-in closed at %s", closesAt.Format("15:04"))
+	case time.Now().Before(opensAt):
+		// A card scanned days early would otherwise count as attendance
+		return nil, fmt.Errorf("cannot check in: check-in opens at %s on %s", opensAt.Format("15:04"), opensAt.Format("2 Jan 2006"))
+	}
+
+	err = scanRegistration(tx.QueryRow(ctx, `
+		UPDATE registrations
+		SET attendance = $1, checked_in_at = CURRENT_TIMESTAMP, checked_in_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING `+registrationColumns,
+		model.AttendanceAttended, checkedInBy, registration.ID,
+	), registration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check in: %w", err)
+	}
+
+	err = insertHistory(ctx, tx, &model.CreateRegistrationHistory{
+		RegistrationID: registration.ID,
+		Status:         registration.Status,
+		Notes:          "Checked in",
+		ChangedBy:      checkedInBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit check-in: %w", err)
+	}
+
+	return registration, nil
+}
+
+// Summary counts a session's approved registrations by attendance
+func (r *AttendanceRepository) Summary(ctx context.Context, scheduleID int64, cutoff time.Duration) (*model.AttendanceSummary, error) {
+	summary := &model.AttendanceSummary{ScheduleID: scheduleID}
+	err := r.db.QueryRow(ctx, `
+		SELECT s.plot_id, s.date_time,
+		       COUNT(r.id),
+		       COUNT(r.id) FILTER (WHERE r.attendance = $2),
+		       COUNT(r.id) FILTER (WHERE r.attendance = $3)
+		FROM schedules s
+		LEFT JOIN registrations r ON r.test_plot_id = s.plot_id AND r.status = $4
+		WHERE s.id = $1
+		GROUP BY s.id
+	`, scheduleID, model.AttendanceAttended, model.AttendanceNoShow, model.RegistrationStatusApproved).Scan(
+		&summary.PlotID,
+		&summary.DateTime,
+		&summary.Expected,
+		&summary.CheckedIn,
+		&summary.NoShow,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("schedule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to count attendance: %w", err)
+	}
+
+	summary.CheckInClosesAt = summary.DateTime.Add(cutoff)
+	summary.Remaining = summary.Expected - summary.CheckedIn - summary.NoShow
+	return summary, nil
+}
+
+// MarkNoShows records every approved registration that was not checked in by the
+// cut-off of its session as a no-show
+func (r *AttendanceRepository) MarkNoShows(ctx context.Context, cutoff time.Duration) ([]*model.Registration, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		UPDATE registrations
+		SET attendance = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT r.id
+			FROM registrations r
+			JOIN schedules s ON s.plot_id = r.test_plot_id
+			WHERE r.status = $2 AND r.attendance IS NULL
+			  AND s.status = $3 AND s.date_time <= $4
+			ORDER BY r.id
+			FOR UPDATE OF r SKIP LOCKED
+		)
+		RETURNING `+registrationColumns,
+		model.AttendanceNoShow, model.RegistrationStatusApproved, model.ScheduleStatusActive, time.Now().Add(-cutoff))
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark no-shows: %w", err)
+	}
+
+	var marked []*model.Registration
+	for rows.Next() {
+		registration := &model.Registration{}
+		if err := scanRegistration(rows, registration); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan registration: %w", err)
+		}
+		marked = append(marked, registration)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating no-shows: %w", err)
+	}
+
+	for _, registration := range marked {
+		err := insertHistory(ctx, tx, &model.CreateRegistrationHistory{
+			RegistrationID: registration.ID,
+			Status:         registration.Status,
+			Notes:          "No-show: not checked in by the cut-off",
+			ChangedBy:      "system",
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit no-shows: %w", err)
+	}
+
+	return marked, nil
+}
@@ -0,0 +1,537 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/bankstatement"
+)
+
+type BankStatementRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewBankStatementRepository(db *pgxpool.Pool) *BankStatementRepository {
+	return &BankStatementRepository{db: db}
+}
+
+const bankStatementColumns = `id, format, filename, COALESCE(account_number, ''), period_start, period_end,
+	       credit_count, duplicate_count, matched_count, imported_by, created_at`
+
+func scanBankStatement(row rowScanner, statement *model.BankStatement) error {
+	return row.Scan(
+		&statement.ID,
+		&statement.Format,
+		&statement.Filename,
+		&statement.AccountNumber,
+		&statement.PeriodStart,
+		&statement.PeriodEnd,
+		&statement.CreditCount,
+		&statement.DuplicateCount,
+		&statement.MatchedCount,
+		&statement.ImportedBy,
+		&statement.CreatedAt,
+	)
+}
+
+const statementLineColumns = `id, statement_id, line_number, booked_on, amount::float8, description,
+	       COALESCE(reference, ''), status, payment_id, COALESCE(match_method, ''), COALESCE(match_notes, ''),
+	       COALESCE(matched_by, ''), matched_at, created_at`
+
+func scanStatementLine(row rowScanner, line *model.BankStatementLine) error {
+	return row.Scan(
+		&line.ID,
+		&line.StatementID,
+		&line.LineNumber,
+		&line.BookedOn,
+		&line.Amount,
+		&line.Description,
+		&line.Reference,
+		&line.Status,
+		&line.PaymentID,
+		&line.MatchMethod,
+		&line.MatchNotes,
+		&line.MatchedBy,
+		&line.MatchedAt,
+		&line.CreatedAt,
+	)
+}
+
+// Import stores the credits of a parsed statement. Credits already imported from an
+// overlapping statement are skipped by their fingerprint. The new lines are returned
+// so they can be matched.
+func (r *BankStatementRepository) Import(ctx context.Context, parsed *bankstatement.Statement, filename, importedBy string) (*model.BankStatement, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	start, end := parsed.Transactions[0].Date, parsed.Transactions[0].Date
+	for _, t := range parsed.Transactions {
+		if t.Date.Before(start) {
+			start = t.Date
+		}
+		if t.Date.After(end) {
+			end = t.Date
+		}
+	}
+
+	statement := &model.BankStatement{}
+	err = scanBankStatement(tx.QueryRow(ctx, `
+		INSERT INTO bank_statements (format, filename, account_number, period_start, period_end, imported_by)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)
+		RETURNING `+bankStatementColumns,
+		parsed.Format, filename, parsed.Account, start, end, importedBy,
+	), statement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bank statement: %w", err)
+	}
+
+	for i, t := range parsed.Transactions {
+		if t.Amount <= 0 {
+			continue
+		}
+
+		line := &model.BankStatementLine{}
+		err := scanStatementLine(tx.QueryRow(ctx, `
+			INSERT INTO bank_statement_lines (statement_id, line_number, booked_on, amount, description, reference, fingerprint)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
+			ON CONFLICT (fingerprint) DO NOTHING
+			RETURNING `+statementLineColumns,
+			statement.ID, i+1, t.Date, t.Amount, t.Description, t.Reference, t.Fingerprint,
+		), line)
+		if err == pgx.ErrNoRows {
+			statement.DuplicateCount++
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to import statement line %d: %w", i+1, err)
+		}
+		statement.CreditCount++
+		statement.Lines = append(statement.Lines, line)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE bank_statements SET credit_count = $1, duplicate_count = $2 WHERE id = $3
+	`, statement.CreditCount, statement.DuplicateCount, statement.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update bank statement: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit bank statement: %w", err)
+	}
+
+	return statement, nil
+}
+
+func (r *BankStatementRepository) GetByID(ctx context.Context, id int64) (*model.BankStatement, error) {
+	statement := &model.BankStatement{}
+	err := scanBankStatement(r.db.QueryRow(ctx, `
+		SELECT `+bankStatementColumns+`
+		FROM bank_statements
+		WHERE id = $1
+	`, id), statement)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("bank statement not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank statement: %w", err)
+	}
+
+	statement.Lines, err = r.listLines(ctx, `WHERE statement_id = $1 ORDER BY line_number`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return statement, nil
+}
+
+func (r *BankStatementRepository) List(ctx context.Context) ([]*model.BankStatement, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+bankStatementColumns+`
+		FROM bank_statements
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bank statements: %w", err)
+	}
+	defer rows.Close()
+
+	var statements []*model.BankStatement
+	for rows.Next() {
+		statement := &model.BankStatement{}
+		if err := scanBankStatement(rows, statement); err != nil {
+			return nil, fmt.Errorf("failed to scan bank statement: %w", err)
+		}
+		statements = append(statements, statement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bank statements: %w", err)
+	}
+
+	return statements, nil
+}
+
+func (r *BankStatementRepository) GetLine(ctx context.Context, id int64) (*model.BankStatementLine, error) {
+	line := &model.BankStatementLine{}
+	err := scanStatementLine(r.db.QueryRow(ctx, `
+		SELECT `+statementLineColumns+`
+		FROM bank_statement_lines
+		WHERE id = $1
+	`, id), line)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("statement line not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement line: %w", err)
+	}
+
+	return line, nil
+}
+
+// ListLines returns the lines of all statements with the given status, oldest first
+func (r *BankStatementRepository) ListLines(ctx context.Context, status string) ([]*model.BankStatementLine, error) {
+	return r.listLines(ctx, `WHERE status = $1 ORDER BY booked_on, id`, status)
+}
+
+func (r *BankStatementRepository) listLines(ctx context.Context, where string, arg any) ([]*model.BankStatementLine, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+statementLineColumns+`
+		FROM bank_statement_lines
+		`+where, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement lines: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []*model.BankStatementLine
+	for rows.Next() {
+		line := &model.BankStatementLine{}
+		if err := scanStatementLine(rows, line); err != nil {
+			return nil, fmt.Errorf("failed to scan statement line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating statement lines: %w", err)
+	}
+
+	return lines, nil
+}
+
+// ListUnmatchedPayments returns pending bank transfers no statement line was matched
+// to, oldest first
+func (r *BankStatementRepository) ListUnmatchedPayments(ctx context.Context) ([]*model.UnmatchedPayment, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT p.id, r.id, r.reg_number, s.full_name, p.amount::float8, p.created_at, p.expired_at
+		FROM payments p
+		JOIN registrations r ON r.id = p.registration_id
+		JOIN participants s ON s.id = r.student_id
+		WHERE p.payment_method = $1 AND p.payment_status = $2
+		  AND NOT EXISTS (SELECT 1 FROM bank_statement_lines l WHERE l.payment_id = p.id)
+		ORDER BY p.created_at
+	`, model.PaymentMethodBankTransfer, model.PaymentStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unmatched payments: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []*model.UnmatchedPayment
+	for rows.Next() {
+		p := &model.UnmatchedPayment{}
+		err := rows.Scan(&p.PaymentID, &p.RegistrationID, &p.RegNumber, &p.StudentName, &p.Amount, &p.CreatedAt, &p.ExpiredAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan unmatched payment: %w", err)
+		}
+		payments = append(payments, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unmatched payments: %w", err)
+	}
+
+	return payments, nil
+}
+
+// normalizeReference keeps only letters and digits, so "001/V/2025" is found in a
+// description that reads "TRF 001V2025"
+func normalizeReference(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, s)
+}
+
+type matchCandidate struct {
+	paymentID      int64
+	registrationID int64
+	regNumber      string
+	uniqueCode     int
+}
+
+// AutoMatch pairs an unmatched credit with a pending bank transfer of the same amount
+// that was open on the booking date. The match is made when the description names
+// the registration, or when the payment was given a unique code, which no other
+// pending payment shares. Otherwise the line stays for manual review with the reason in its notes.
+func (r *BankStatementRepository) AutoMatch(ctx context.Context, line *model.BankStatementLine) error {
+	rows, err := r.db.Query(ctx, `
+		SELECT p.id, r.id, r.reg_number, p.unique_code
+		FROM payments p
+		JOIN registrations r ON r.id = p.registration_id
+		WHERE p.payment_method = $1 AND p.payment_status = $2 AND p.amount = $3
+		  AND p.created_at::date <= $4 AND (p.expired_at IS NULL OR p.expired_at::date >= $4)
+		  AND NOT EXISTS (SELECT 1 FROM bank_statement_lines l WHERE l.payment_id = p.id)
+		ORDER BY p.created_at
+	`, model.PaymentMethodBankTransfer, model.PaymentStatusPending, line.Amount, line.BookedOn)
+	if err != nil {
+		return fmt.Errorf("failed to query payments: %w", err)
+	}
+	candidates, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (matchCandidate, error) {
+		var c matchCandidate
+		err := row.Scan(&c.paymentID, &c.registrationID, &c.regNumber, &c.uniqueCode)
+		return c, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query payments: %w", err)
+	}
+
+	text := normalizeReference(line.Description + " " + line.Reference)
+	var named []matchCandidate
+	for _, c := range candidates {
+		if strings.Contains(text, normalizeReference(c.regNumber)) {
+			named = append(named, c)
+		}
+	}
+
+	var notes string
+	status := model.StatementLineUnmatched
+	switch {
+	case len(named) == 1:
+		return r.match(ctx, line.ID, named[0], model.MatchMethodReference, "Description names registration "+named[0].regNumber, "system")
+	case len(candidates) == 1 && candidates[0].uniqueCode > 0:
+		return r.match(ctx, line.ID, candidates[0], model.MatchMethodAmount, "Only pending payment with this amount", "system")
+	case len(candidates) == 0:
+		late, err := r.lateCandidates(ctx, line, text)
+		if err != nil {
+			return err
+		}
+		if len(late) == 0 {
+			notes = "No pending payment with this amount was open on the booking date"
+			break
+		}
+		status = model.StatementLineLate
+		notes = "Transfer for " + strings.Join(late, ", ") + "; match it manually to refund or reinstate"
+	case len(candidates) == 1:
+		notes = fmt.Sprintf("Amount has no unique code; payment %d is the only candidate", candidates[0].paymentID)
+	default:
+		notes = fmt.Sprintf("%d pending payments have this amount", len(candidates))
+	}
+
+	_, err = r.db.Exec(ctx, `
+		UPDATE bank_statement_lines SET status = $1, match_notes = $2 WHERE id = $3 AND status = $4
+	`, status, notes, line.ID, model.StatementLineUnmatched)
+	if err != nil {
+		return fmt.Errorf("failed to update statement line: %w", err)
+	}
+	line.Status = status
+	line.MatchNotes = notes
+	return nil
+}
+
+// lateCandidates describes the expired or cancelled bank transfers a credit that
+// found no pending payment most likely pays: same amount, and either a unique code
+// or a registration the description names
+func (r *BankStatementRepository) lateCandidates(ctx context.Context, line *model.BankStatementLine, text string) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT p.id, r.id, r.reg_number, p.unique_code
+		FROM payments p
+		JOIN registrations r ON r.id = p.registration_id
+		WHERE p.payment_method = $1 AND p.payment_status IN ($2, $3) AND p.amount = $4
+		  AND p.created_at::date <= $5
+		  AND NOT EXISTS (SELECT 1 FROM bank_statement_lines l WHERE l.payment_id = p.id)
+		ORDER BY p.created_at
+	`, model.PaymentMethodBankTransfer, model.PaymentStatusExpired, model.PaymentStatusCancelled, line.Amount, line.BookedOn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payments: %w", err)
+	}
+	candidates, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (matchCandidate, error) {
+		var c matchCandidate
+		err := row.Scan(&c.paymentID, &c.registrationID, &c.regNumber, &c.uniqueCode)
+		return c, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payments: %w", err)
+	}
+
+	var late []string
+	for _, c := range candidates {
+		if c.uniqueCode > 0 || strings.Contains(text, normalizeReference(c.regNumber)) {
+			late = append(late, fmt.Sprintf("payment %d of %s", c.paymentID, c.regNumber))
+		}
+	}
+	return late, nil
+}
+
+// CountMatched records how many lines of a statement were matched on import
+func (r *BankStatementRepository) CountMatched(ctx context.Context, statementID int64) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE bank_statements
+		SET matched_count = (
+			SELECT COUNT(*) FROM bank_statement_lines
+			WHERE statement_id = $1 AND status = $2 AND match_method <> $3
+		)
+		WHERE id = $1
+	`, statementID, model.StatementLineMatched, model.MatchMethodManual)
+	if err != nil {
+		return fmt.Errorf("failed to count matched lines: %w", err)
+	}
+	return nil
+}
+
+// Match pairs a line with a payment chosen by an admin. Besides pending payments an
+// admin may pick one that expired or was cancelled before the money arrived; it is
+// recorded as received late, to be refunded or its registration reinstated.
+func (r *BankStatementRepository) Match(ctx context.Context, lineID, paymentID int64, notes, matchedBy string) error {
+	c := matchCandidate{paymentID: paymentID}
+	err := r.db.QueryRow(ctx, `
+		SELECT r.id, r.reg_number
+		FROM payments p
+		JOIN registrations r ON r.id = p.registration_id
+		WHERE p.id = $1
+	`, paymentID).Scan(&c.registrationID, &c.regNumber)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("payment not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	if notes == "" {
+		notes = "Matched manually"
+	}
+	return r.match(ctx, lineID, c, model.MatchMethodManual, notes, matchedBy)
+}
+
+// match marks the payment paid as of the booking date, pending verification, and
+// links the line to it. Only a manual match takes an expired or cancelled payment,
+// which becomes received late instead.
+func (r *BankStatementRepository) match(ctx context.Context, lineID int64, c matchCandidate, method, notes, matchedBy string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	registration, err := lockRegistration(ctx, tx, c.registrationID)
+	if err != nil {
+		return err
+	}
+	payment, err := lockPayment(ctx, tx, c.paymentID)
+	if err != nil {
+		return err
+	}
+	status, notice := model.PaymentStatusPaid, "awaiting verification"
+	switch payment.PaymentStatus {
+	case model.PaymentStatusPending:
+	case model.PaymentStatusExpired, model.PaymentStatusCancelled:
+		if method != model.MatchMethodManual {
+			return fmt.Errorf("cannot match: payment is %s", payment.PaymentStatus)
+		}
+		status = model.PaymentStatusReceivedLate
+		notice = fmt.Sprintf("received after the payment was %s; refund it or reinstate the registration", payment.PaymentStatus)
+	default:
+		return fmt.Errorf("cannot match: payment is %s", payment.PaymentStatus)
+	}
+
+	line := &model.BankStatementLine{}
+	err = scanStatementLine(tx.QueryRow(ctx, `
+		SELECT `+statementLineColumns+`
+		FROM bank_statement_lines
+		WHERE id = $1
+		FOR UPDATE
+	`, lineID), line)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("statement line not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock statement line: %w", err)
+	}
+	if line.Status != model.StatementLineUnmatched && line.Status != model.StatementLineLate {
+		return fmt.Errorf("cannot match: statement line is %s", line.Status)
+	}
+	if line.Amount != payment.Amount {
+		return fmt.Errorf("cannot match: line amount %.2f differs from payment amount %.2f", line.Amount, payment.Amount)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE bank_statement_lines
+		SET status = $1, payment_id = $2, match_method = $3, match_notes = $4, matched_by = $5, matched_at = CURRENT_TIMESTAMP
+		WHERE id = $6
+	`, model.StatementLineMatched, payment.ID, method, notes, matchedBy, line.ID)
+	if err != nil {
+		return fmt.Errorf("failed to match statement line: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE payments
+		SET payment_status = $1, paid_at = $2, transfer_date = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, status, line.BookedOn, payment.ID)
+	if err != nil {
+		return fmt.Errorf("failed to mark payment paid: %w", err)
+	}
+
+	err = insertHistory(ctx, tx, &model.CreateRegistrationHistory{
+		RegistrationID: registration.ID,
+		Status:         registration.Status,
+		Notes: fmt.Sprintf("Payment %d found on bank statement %d (%s, %s); %s",
+			payment.ID, line.StatementID, line.BookedOn.Format(time.DateOnly), method, notice),
+		ChangedBy: matchedBy,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit match: %w", err)
+	}
+
+	return nil
+}
+
+// Ignore sets aside an unmatched or late line that is not a registration payment
+func (r *BankStatementRepository) Ignore(ctx context.Context, lineID int64, notes, ignoredBy string) (*model.BankStatementLine, error) {
+	line := &model.BankStatementLine{}
+	err := scanStatementLine(r.db.QueryRow(ctx, `
+		UPDATE bank_statement_lines
+		SET status = $1, match_notes = $2, matched_by = $3, matched_at = CURRENT_TIMESTAMP
+		WHERE id = $4 AND status IN ($5, $6)
+		RETURNING `+statementLineColumns,
+		model.StatementLineIgnored, notes, ignoredBy, lineID, model.StatementLineUnmatched, model.StatementLineLate,
+	), line)
+	if err == pgx.ErrNoRows {
+		existing, err := r.GetLine(ctx, lineID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("cannot ignore: statement line is %s", existing.Status)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to ignore statement line: %w", err)
+	}
+
+	return line, nil
+}
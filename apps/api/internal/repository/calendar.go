@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type CalendarRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCalendarRepository(db *pgxpool.Pool) *CalendarRepository {
+	return &CalendarRepository{db: db}
+}
+
+const calendarEventColumns = `id, name, type, start_date, end_date, source,
+	       COALESCE(external_uid, ''), created_at, updated_at`
+
+func scanCalendarEvent(row rowScanner, event *model.CalendarEvent) error {
+	return row.Scan(
+		&event.ID,
+		&event.Name,
+		&event.Type,
+		&event.StartDate,
+		&event.EndDate,
+		&event.Source,
+		&event.ExternalUID,
+		&event.CreatedAt,
+		&event.UpdatedAt,
+	)
+}
+
+func (r *CalendarRepository) Create(ctx context.Context, event *model.CalendarEvent) error {
+	query := `
+		INSERT INTO calendar_events (
+			name, type, start_date, end_date, source
+		) VALUES (
+			$1, $2, $3, $4, $5
+		) RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(ctx, query,
+		event.Name,
+		event.Type,
+		event.StartDate,
+		event.EndDate,
+		event.Source,
+	).Scan(
+		&event.ID,
+		&event.CreatedAt,
+		&event.UpdatedAt,
+	)
+}
+
+// Import upserts events by their external UID in a single transaction, so
+// re-importing the same ICS file updates events instead of duplicating them.
+func (r *CalendarRepository) Import(ctx context.Context, events []*model.CalendarEvent) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO calendar_events (
+			name, type, start_date, end_date, source, external_uid
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+		ON CONFLICT (external_uid) WHERE external_uid IS NOT NULL DO UPDATE
+		SET name = EXCLUDED.name, type = EXCLUDED.type,
+		    start_date = EXCLUDED.start_date, end_date = EXCLUDED.end_date,
+		    updated_at = CURRENT_TIMESTAMP
+		RETURNING id, created_at, updated_at
+	`
+
+	for _, event := range events {
+		err := tx.QueryRow(ctx, query,
+			event.Name,
+			event.Type,
+			event.StartDate,
+			event.EndDate,
+			event.Source,
+			event.ExternalUID,
+		).Scan(
+			&event.ID,
+			&event.CreatedAt,
+			&event.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to import calendar event %q: %w", event.Name, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit calendar import: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CalendarRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM calendar_events WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar event: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("calendar event not found")
+	}
+
+	return nil
+}
+
+// ListBetween returns events overlapping the inclusive date range
+func (r *CalendarRepository) ListBetween(ctx context.Context, from, to model.Date) ([]*model.CalendarEvent, error) {
+	query := `
+		SELECT ` + calendarEventColumns + `
+		FROM calendar_events
+		WHERE start_date <= $2 AND end_date >= $1
+		ORDER BY start_date, id
+	`
+
+	rows, err := r.db.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calendar events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*model.CalendarEvent
+	for rows.Next() {
+		event := &model.CalendarEvent{}
+		if err := scanCalendarEvent(rows, event); err != nil {
+			return nil, fmt.Errorf("failed to scan calendar event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating calendar events: %w", err)
+	}
+
+	return events, nil
+}
+
+// FindBlocking returns the first event covering the given date, or nil if the date is free
+func (r *CalendarRepository) FindBlocking(ctx context.Context, date model.Date) (*model.CalendarEvent, error) {
+	query := `
+		SELECT ` + calendarEventColumns + `
+		FROM calendar_events
+		WHERE start_date <= $1 AND end_date >= $1
+		ORDER BY start_date, id
+		LIMIT 1
+	`
+
+	event := &model.CalendarEvent{}
+	err := scanCalendarEvent(r.db.QueryRow(ctx, query, date), event)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check calendar: %w", err)
+	}
+
+	return event, nil
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type CoordinatorRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCoordinatorRepository(db *pgxpool.Pool) *CoordinatorRepository {
+	return &CoordinatorRepository{db: db}
+}
+
+const facultyColumns = `code, name, majors, created_at, updated_at`
+
+func scanFaculty(row rowScanner, faculty *model.Faculty) error {
+	return row.Scan(
+		&faculty.Code,
+		&faculty.Name,
+		&faculty.Majors,
+		&faculty.CreatedAt,
+		&faculty.UpdatedAt,
+	)
+}
+
+const coordinatorColumns = `id, full_name, email, faculty_code, active, created_at, updated_at`
+
+func scanCoordinator(row rowScanner, coordinator *model.Coordinator) error {
+	return row.Scan(
+		&coordinator.ID,
+		&coordinator.FullName,
+		&coordinator.Email,
+		&coordinator.FacultyCode,
+		&coordinator.Active,
+		&coordinator.CreatedAt,
+		&coordinator.UpdatedAt,
+	)
+}
+
+// coordinatorConstraint translates the unique violation on the email address and the
+// foreign key on the faculty
+func coordinatorConstraint(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	switch {
+	case pgErr.Code == "23505" && pgErr.ConstraintName == "idx_coordinators_email":
+		return fmt.Errorf("cannot save coordinator: email is already registered")
+	case pgErr.Code == "23503":
+		return fmt.Errorf("faculty not found")
+	}
+	return nil
+}
+
+func (r *CoordinatorRepository) CreateFaculty(ctx context.Context, faculty *model.Faculty) error {
+	query := `
+		INSERT INTO faculties (code, name, majors)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (code) DO NOTHING
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query, faculty.Code, faculty.Name, faculty.Majors).Scan(&faculty.CreatedAt, &faculty.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("cannot create faculty: code %q already exists", faculty.Code)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create faculty: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CoordinatorRepository) GetFaculty(ctx context.Context, code string) (*model.Faculty, error) {
+	query := `
+		SELECT ` + facultyColumns + `
+		FROM faculties
+		WHERE code = $1
+	`
+
+	faculty := &model.Faculty{}
+	err := scanFaculty(r.db.QueryRow(ctx, query, code), faculty)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("faculty not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get faculty: %w", err)
+	}
+
+	return faculty, nil
+}
+
+func (r *CoordinatorRepository) ListFaculties(ctx context.Context) ([]*model.Faculty, error) {
+	query := `
+		SELECT ` + facultyColumns + `
+		FROM faculties
+		ORDER BY name, code
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query faculties: %w", err)
+	}
+	defer rows.Close()
+
+	var faculties []*model.Faculty
+	for rows.Next() {
+		faculty := &model.Faculty{}
+		if err := scanFaculty(rows, faculty); err != nil {
+			return nil, fmt.Errorf("failed to scan faculty: %w", err)
+		}
+		faculties = append(faculties, faculty)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating faculties: %w", err)
+	}
+
+	return faculties, nil
+}
+
+func (r *CoordinatorRepository) UpdateFaculty(ctx context.Context, faculty *model.Faculty) error {
+	query := `
+		UPDATE faculties
+		SET name = $1, majors = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE code = $3
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query, faculty.Name, faculty.Majors, faculty.Code).Scan(&faculty.CreatedAt, &faculty.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("faculty not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update faculty: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CoordinatorRepository) Create(ctx context.Context, coordinator *model.Coordinator) error {
+	query := `
+		INSERT INTO coordinators (full_name, email, faculty_code)
+		VALUES ($1, $2, $3)
+		RETURNING ` + coordinatorColumns
+
+	err := scanCoordinator(r.db.QueryRow(ctx, query, coordinator.FullName, coordinator.Email, coordinator.FacultyCode), coordinator)
+	if constraint := coordinatorConstraint(err); constraint != nil {
+		return constraint
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create coordinator: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CoordinatorRepository) GetByID(ctx context.Context, id int64) (*model.Coordinator, error) {
+	query := `
+		SELECT ` + coordinatorColumns + `
+		FROM coordinators
+		WHERE id = $1
+	`
+
+	coordinator := &model.Coordinator{}
+	err := scanCoordinator(r.db.QueryRow(ctx, query, id), coordinator)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("coordinator not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coordinator: %w", err)
+	}
+
+	return coordinator, nil
+}
+
+// List returns coordinators ordered by name; facultyCode "" lists every faculty's
+func (r *CoordinatorRepository) List(ctx context.Context, facultyCode string) ([]*model.Coordinator, error) {
+	query := `
+		SELECT ` + coordinatorColumns + `
+		FROM coordinators
+		WHERE $1 = '' OR faculty_code = $1
+		ORDER BY full_name, id
+	`
+
+	rows, err := r.db.Query(ctx, query, facultyCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query coordinators: %w", err)
+	}
+	defer rows.Close()
+
+	var coordinators []*model.Coordinator
+	for rows.Next() {
+		coordinator := &model.Coordinator{}
+		if err := scanCoordinator(rows, coordinator); err != nil {
+			return nil, fmt.Errorf("failed to scan coordinator: %w", err)
+		}
+		coordinators = append(coordinators, coordinator)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating coordinators: %w", err)
+	}
+
+	return coordinators, nil
+}
+
+func (r *CoordinatorRepository) Update(ctx context.Context, coordinator *model.Coordinator) error {
+	query := `
+		UPDATE coordinators
+		SET full_name = $1, email = $2, faculty_code = $3, active = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+		RETURNING ` + coordinatorColumns
+
+	err := scanCoordinator(r.db.QueryRow(ctx, query,
+		coordinator.FullName,
+		coordinator.Email,
+		coordinator.FacultyCode,
+		coordinator.Active,
+		coordinator.ID,
+	), coordinator)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("coordinator not found")
+	}
+	if constraint := coordinatorConstraint(err); constraint != nil {
+		return constraint
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update coordinator: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type EligibilityRuleRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEligibilityRuleRepository(db *pgxpool.Pool) *EligibilityRuleRepository {
+	return &EligibilityRuleRepository{db: db}
+}
+
+const eligibilityRuleColumns = `id, code, type, params, description, enabled, created_at, updated_at`
+
+func scanEligibilityRule(row rowScanner, rule *model.EligibilityRule) error {
+	return row.Scan(
+		&rule.ID,
+		&rule.Code,
+		&rule.Type,
+		&rule.Params,
+		&rule.Description,
+		&rule.Enabled,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+}
+
+func (r *EligibilityRuleRepository) Create(ctx context.Context, rule *model.EligibilityRule) error {
+	query := `
+		INSERT INTO eligibility_rules (
+			code, type, params, description, enabled
+		) VALUES (
+			$1, $2, $3, $4, $5
+		) RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		rule.Code,
+		rule.Type,
+		rule.Params,
+		rule.Description,
+		rule.Enabled,
+	).Scan(
+		&rule.ID,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil && strings.Contains(err.Error(), "eligibility_rules_code_key") {
+		return fmt.Errorf("invalid eligibility rule: code %q is already in use", rule.Code)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create eligibility rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *EligibilityRuleRepository) GetByID(ctx context.Context, id int64) (*model.EligibilityRule, error) {
+	query := `
+		SELECT ` + eligibilityRuleColumns + `
+		FROM eligibility_rules
+		WHERE id = $1
+	`
+
+	rule := &model.EligibilityRule{}
+	err := scanEligibilityRule(r.db.QueryRow(ctx, query, id), rule)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("eligibility rule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get eligibility rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// List returns the rules in evaluation order, optionally only the enabled ones
+func (r *EligibilityRuleRepository) List(ctx context.Context, enabledOnly bool) ([]*model.EligibilityRule, error) {
+	query := `
+		SELECT ` + eligibilityRuleColumns + `
+		FROM eligibility_rules
+		WHERE enabled OR NOT $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(ctx, query, enabledOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query eligibility rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*model.EligibilityRule
+	for rows.Next() {
+		rule := &model.EligibilityRule{}
+		if err := scanEligibilityRule(rows, rule); err != nil {
+			return nil, fmt.Errorf("failed to scan eligibility rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating eligibility rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+func (r *EligibilityRuleRepository) Update(ctx context.Context, rule *model.EligibilityRule) error {
+	query := `
+		UPDATE eligibility_rules
+		SET params = $1, description = $2, enabled = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		rule.Params,
+		rule.Description,
+		rule.Enabled,
+		rule.ID,
+	).Scan(&rule.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("eligibility rule not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update eligibility rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *EligibilityRuleRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM eligibility_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete eligibility rule: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("eligibility rule not found")
+	}
+
+	return nil
+}
+
+// loadEligibilityFacts locks the participant so concurrent registrations of the same
+// participant are evaluated one after the other, then reads their registrations.
+func loadEligibilityFacts(ctx context.Context, tx pgx.Tx, studentID int64, schedule *model.Schedule) (*model.EligibilityFacts, error) {
+	participant := &model.Participant{}
+	err := scanParticipant(tx.QueryRow(ctx, `
+		SELECT `+participantColumns+`
+		FROM participants
+		WHERE id = $1
+		FOR UPDATE
+	`, studentID), participant)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("participant not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock participant: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT `+registrationColumns+`
+		FROM registrations
+		WHERE student_id = $1 AND status NOT IN ($2, $3)
+		ORDER BY test_date DESC
+	`, studentID, model.RegistrationStatusRejected, model.RegistrationStatusCancelled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query participant registrations: %w", err)
+	}
+	defer rows.Close()
+
+	facts := &model.EligibilityFacts{Participant: participant, Schedule: schedule}
+	for rows.Next() {
+		registration := &model.Registration{}
+		if err := scanRegistration(rows, registration); err != nil {
+			return nil, fmt.Errorf("failed to scan registration: %w", err)
+		}
+		facts.Registrations = append(facts.Registrations, registration)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating registrations: %w", err)
+	}
+
+	scoreRows, err := tx.Query(ctx, `
+		SELECT `+scoreColumns+`
+		FROM scores
+		WHERE student_id = $1 AND released_at IS NOT NULL
+		ORDER BY valid_until DESC
+	`, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query participant scores: %w", err)
+	}
+	defer scoreRows.Close()
+
+	for scoreRows.Next() {
+		score := &model.Score{}
+		if err := scanScore(scoreRows, score); err != nil {
+			return nil, fmt.Errorf("failed to scan score: %w", err)
+		}
+		facts.Scores = append(facts.Scores, score)
+	}
+
+	if err := scoreRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scores: %w", err)
+	}
+
+	return facts, nil
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type FeedRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewFeedRepository(db *pgxpool.Pool) *FeedRepository {
+	return &FeedRepository{db: db}
+}
+
+// RotateToken revokes the owner's current token, if any, and stores a new one
+func (r *FeedRepository) RotateToken(ctx context.Context, feedToken *model.FeedToken) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE calendar_feed_tokens
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE owner_type = $1 AND owner_id = $2 AND revoked_at IS NULL
+	`, feedToken.OwnerType, feedToken.OwnerID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke feed token: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO calendar_feed_tokens (token, owner_type, owner_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, feedToken.Token, feedToken.OwnerType, feedToken.OwnerID).Scan(&feedToken.ID, &feedToken.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create feed token: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit feed token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *FeedRepository) GetByToken(ctx context.Context, token string) (*model.FeedToken, error) {
+	query := `
+		SELECT id, token, owner_type, owner_id, created_at
+		FROM calendar_feed_tokens
+		WHERE token = $1 AND revoked_at IS NULL
+	`
+
+	feedToken := &model.FeedToken{}
+	err := r.db.QueryRow(ctx, query, token).Scan(
+		&feedToken.ID,
+		&feedToken.Token,
+		&feedToken.OwnerType,
+		&feedToken.OwnerID,
+		&feedToken.CreatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("feed not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed token: %w", err)
+	}
+
+	return feedToken, nil
+}
+
+// ListStudentSessions returns every schedule the student has registered for, including
+// rejected and cancelled ones so calendar apps can mark them cancelled instead of
+// silently dropping them.
+func (r *FeedRepository) ListStudentSessions(ctx context.Context, studentID int64) ([]*model.FeedSession, error) {
+	query := `
+		SELECT s.id, s.plot_id, s.date_time, s.location,
+		       s.quota, s.available, s.template_id, s.status, s.cancelled_at,
+		       COALESCE(s.cancellation_reason, ''), s.created_at, s.updated_at,
+		       r.id, r.reg_number, r.status, GREATEST(s.updated_at, r.updated_at)
+		FROM registrations r
+		JOIN schedules s ON s.plot_id = r.test_plot_id
+		WHERE r.student_id = $1
+		ORDER BY s.date_time
+	`
+
+	rows, err := r.db.Query(ctx, query, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query student sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*model.FeedSession
+	for rows.Next() {
+		session := &model.FeedSession{}
+		err := rows.Scan(
+			&session.Schedule.ID,
+			&session.Schedule.PlotID,
+			&session.Schedule.DateTime,
+			&session.Schedule.Location,
+			&session.Schedule.Quota,
+			&session.Schedule.Available,
+			&session.Schedule.TemplateID,
+			&session.Schedule.Status,
+			&session.Schedule.CancelledAt,
+			&session.Schedule.CancellationReason,
+			&session.Schedule.CreatedAt,
+			&session.Schedule.UpdatedAt,
+			&session.RegistrationID,
+			&session.RegNumber,
+			&session.RegistrationStatus,
+			&session.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan student session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating student sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// ListProctorSessions returns every session the proctor has been assigned to, including
+// removed assignments so calendar apps can mark them cancelled
+func (r *FeedRepository) ListProctorSessions(ctx context.Context, proctorID int64) ([]*model.FeedSession, error) {
+	query := `
+		SELECT s.id, s.plot_id, s.date_time, s.location,
+		       s.quota, s.available, s.template_id, s.status, s.cancelled_at,
+		       COALESCE(s.cancellation_reason, ''), s.created_at, s.updated_at,
+		       sp.id, sp.removed_at IS NOT NULL,
+		       (SELECT COUNT(*) FROM registrations r WHERE r.test_plot_id = s.plot_id AND r.status = $2),
+		       GREATEST(s.updated_at, sp.assigned_at, sp.removed_at)
+		FROM schedule_proctors sp
+		JOIN schedules s ON s.id = sp.schedule_id
+		WHERE sp.proctor_id = $1
+		ORDER BY s.date_time, sp.id
+	`
+
+	rows, err := r.db.Query(ctx, query, proctorID, model.RegistrationStatusApproved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query proctor sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*model.FeedSession
+	for rows.Next() {
+		session := &model.FeedSession{}
+		err := rows.Scan(
+			&session.Schedule.ID,
+			&session.Schedule.PlotID,
+			&session.Schedule.DateTime,
+			&session.Schedule.Location,
+			&session.Schedule.Quota,
+			&session.Schedule.Available,
+			&session.Schedule.TemplateID,
+			&session.Schedule.Status,
+			&session.Schedule.CancelledAt,
+			&session.Schedule.CancellationReason,
+			&session.Schedule.CreatedAt,
+			&session.Schedule.UpdatedAt,
+			&session.AssignmentID,
+			&session.Removed,
+			&session.Participants,
+			&session.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan proctor session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating proctor sessions: %w", err)
+	}
+
+	return sessions, nil
+}
@@ -0,0 +1,519 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type GroupRegistrationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewGroupRegistrationRepository(db *pgxpool.Pool) *GroupRegistrationRepository {
+	return &GroupRegistrationRepository{db: db}
+}
+
+const groupRegistrationColumns = `g.id, g.schedule_id, s.plot_id, g.coordinator_id, COALESCE(g.faculty_code, ''), g.created_by, g.all_or_none,
+	       g.requested, g.registered, g.failed, COALESCE(g.notes, ''), g.results, g.created_at`
+
+func scanGroupRegistration(row rowScanner, group *model.GroupRegistration) error {
+	return row.Scan(
+		&group.ID,
+		&group.ScheduleID,
+		&group.PlotID,
+		&group.CoordinatorID,
+		&group.FacultyCode,
+		&group.CreatedBy,
+		&group.AllOrNone,
+		&group.Requested,
+		&group.Registered,
+		&group.Failed,
+		&group.Notes,
+		&group.Results,
+		&group.CreatedAt,
+	)
+}
+
+const groupInvoiceColumns = `id, group_registration_id, invoice_number, amount::float8, status, due_at, paid_at,
+	       COALESCE(paid_by, ''), created_at, updated_at`
+
+func scanGroupInvoice(row rowScanner, invoice *model.GroupInvoice) error {
+	return row.Scan(
+		&invoice.ID,
+		&invoice.GroupRegistrationID,
+		&invoice.InvoiceNumber,
+		&invoice.Amount,
+		&invoice.Status,
+		&invoice.DueAt,
+		&invoice.PaidAt,
+		&invoice.PaidBy,
+		&invoice.CreatedAt,
+		&invoice.UpdatedAt,
+	)
+}
+
+// nextGroupInvoiceNumber generates the next group invoice number of the month, e.g. G001/V/2025
+func nextGroupInvoiceNumber(ctx context.Context, tx pgx.Tx) (string, error) {
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('group_invoices.invoice_number'))`); err != nil {
+		return "", fmt.Errorf("failed to lock invoice numbering: %w", err)
+	}
+
+	var count, month, year int
+	err := tx.QueryRow(ctx, `
+		SELECT COUNT(*), EXTRACT(MONTH FROM CURRENT_TIMESTAMP)::int, EXTRACT(YEAR FROM CURRENT_TIMESTAMP)::int
+		FROM group_invoices
+		WHERE date_trunc('month', created_at) = date_trunc('month', CURRENT_TIMESTAMP)
+	`).Scan(&count, &month, &year)
+	if err != nil {
+		return "", fmt.Errorf("failed to count group invoices: %w", err)
+	}
+
+	return fmt.Sprintf("G%03d/%s/%d", count+1, romanMonths[month-1], year), nil
+}
+
+// GroupMemberCheck is evaluated with the participant locked, before the eligibility
+// rules, to decide whether the group may include them
+type GroupMemberCheck func(participant *model.Participant) error
+
+// registerMember registers one student of a group on the locked seat pool. Errors
+// starting with "cannot " reject only this student; any other error aborts the group.
+func registerMember(ctx context.Context, tx pgx.Tx, pool *seatPool, participantID int64, groupID int64, member GroupMemberCheck, eligible EligibilityCheck, changedBy string, result *model.GroupMemberResult) error {
+	facts, err := loadEligibilityFacts(ctx, tx, participantID, pool.schedule)
+	if err != nil {
+		return err
+	}
+	result.FullName = facts.Participant.FullName
+	if err := member(facts.Participant); err != nil {
+		return err
+	}
+	for _, existing := range facts.Registrations {
+		if existing.TestPlotID == pool.schedule.PlotID {
+			return fmt.Errorf("cannot register: student is already registered for this schedule (%s)", existing.RegNumber)
+		}
+	}
+	if _, err := eligible(facts); err != nil {
+		return err
+	}
+
+	segment, ok := pool.allocate(facts.Participant.Type, facts.Participant.Major)
+	if !ok {
+		if pool.schedule.Available <= 0 {
+			return fmt.Errorf("cannot register: schedule is full")
+		}
+		return fmt.Errorf("cannot register: the remaining seats are reserved for other segments")
+	}
+	if err := pool.take(ctx, tx, segment); err != nil {
+		return err
+	}
+
+	registration := &model.Registration{StudentID: participantID, GroupID: &groupID}
+	if segment != nil {
+		registration.SegmentID = &segment.ID
+	}
+	notes := fmt.Sprintf("Registered in group %d", groupID)
+	if err := insertRegistration(ctx, tx, registration, pool.schedule, 0, notes, changedBy); err != nil {
+		return err
+	}
+
+	tier, err := feeTierFor(ctx, tx, participantID)
+	if err != nil {
+		return err
+	}
+	result.FeeTier = tier.Code
+	result.Amount = tier.Price(pool.schedule.ScheduleType())
+
+	result.Outcome = model.GroupOutcomeRegistered
+	result.RegistrationID = registration.ID
+	result.RegNumber = registration.RegNumber
+	return nil
+}
+
+// Create registers every listed student for the schedule, each in its own savepoint so
+// that one rejected student does not affect the others. With AllOrNone nothing is kept
+// unless every student could be registered; the returned report then has no ID and
+// the registrations it would have created are marked rolled_back. The registrations of
+// a stored group share one invoice due after dueWithin, but no later than the test.
+func (r *GroupRegistrationRepository) Create(ctx context.Context, group *model.GroupRegistration, studentNumbers []string, check ScheduleCheck, member GroupMemberCheck, eligible EligibilityCheck, dueWithin time.Duration) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	schedule, err := lockSchedule(ctx, tx, group.ScheduleID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case schedule == nil:
+		return fmt.Errorf("schedule not found")
+	case schedule.Status == model.ScheduleStatusCancelled:
+		return fmt.Errorf("cannot register group: schedule is cancelled")
+	case schedule.DateTime.Before(time.Now()):
+		return fmt.Errorf("cannot register group: schedule has already started")
+	case schedule.AllocationMode == model.AllocationLottery:
+		return fmt.Errorf("cannot register group: seats on this schedule are allocated by lottery")
+	}
+	if err := check(schedule); err != nil {
+		return err
+	}
+	group.PlotID = schedule.PlotID
+	group.Requested = len(studentNumbers)
+
+	// The group row exists first so registrations can reference it; counts follow below
+	err = tx.QueryRow(ctx, `
+		INSERT INTO group_registrations (
+			schedule_id, coordinator_id, faculty_code, created_by, all_or_none, requested, registered, failed, notes, results
+		) VALUES (
+			$1, $2, NULLIF($3, ''), $4, $5, $6, 0, 0, NULLIF($7, ''), '[]'
+		) RETURNING id, created_at
+	`,
+		group.ScheduleID,
+		group.CoordinatorID,
+		group.FacultyCode,
+		group.CreatedBy,
+		group.AllOrNone,
+		group.Requested,
+		group.Notes,
+	).Scan(&group.ID, &group.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create group registration: %w", err)
+	}
+
+	pool, err := lockSeatPool(ctx, tx, schedule)
+	if err != nil {
+		return err
+	}
+
+	group.Results = make([]model.GroupMemberResult, 0, len(studentNumbers))
+	seen := make(map[string]bool, len(studentNumbers))
+	var total float64
+	for _, number := range studentNumbers {
+		result := model.GroupMemberResult{StudentNumber: strings.TrimSpace(number), Outcome: model.GroupOutcomeFailed}
+
+		switch {
+		case seen[result.StudentNumber]:
+			result.Reason = "listed more than once"
+		default:
+			seen[result.StudentNumber] = true
+
+			err := tx.QueryRow(ctx, `
+				SELECT id FROM participants WHERE student_number = $1 AND participant_type = $2
+			`, result.StudentNumber, model.ParticipantTypeStudent).Scan(&result.StudentID)
+			if err == pgx.ErrNoRows {
+				result.Reason = "student number not found"
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to look up student %s: %w", result.StudentNumber, err)
+			}
+
+			savepoint, err := tx.Begin(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to begin savepoint: %w", err)
+			}
+			err = registerMember(ctx, savepoint, pool, result.StudentID, group.ID, member, eligible, group.CreatedBy, &result)
+			if err != nil {
+				savepoint.Rollback(ctx)
+				if !strings.HasPrefix(err.Error(), "cannot ") {
+					return err
+				}
+				result.Outcome = model.GroupOutcomeFailed
+				result.Reason = strings.TrimPrefix(err.Error(), "cannot register: ")
+				break
+			}
+			if err := savepoint.Commit(ctx); err != nil {
+				return fmt.Errorf("failed to release savepoint: %w", err)
+			}
+		}
+
+		if result.Outcome == model.GroupOutcomeRegistered {
+			group.Registered++
+			total += result.Amount
+		} else {
+			group.Failed++
+		}
+		group.Results = append(group.Results, result)
+	}
+
+	if group.AllOrNone && group.Failed > 0 {
+		// Rolled back by the deferred Rollback
+		group.ID = 0
+		group.Registered = 0
+		for i := range group.Results {
+			if group.Results[i].Outcome == model.GroupOutcomeRegistered {
+				group.Results[i].Outcome = model.GroupOutcomeRolledBack
+				group.Results[i].RegistrationID = 0
+				group.Results[i].RegNumber = ""
+			}
+		}
+		return nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE group_registrations SET registered = $1, failed = $2, results = $3 WHERE id = $4
+	`, group.Registered, group.Failed, group.Results, group.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update group registration: %w", err)
+	}
+
+	if group.Registered > 0 {
+		invoiceNumber, err := nextGroupInvoiceNumber(ctx, tx)
+		if err != nil {
+			return err
+		}
+		dueAt := time.Now().Add(dueWithin)
+		if schedule.DateTime.Before(dueAt) {
+			dueAt = schedule.DateTime
+		}
+
+		group.Invoice = &model.GroupInvoice{}
+		err = scanGroupInvoice(tx.QueryRow(ctx, `
+			INSERT INTO group_invoices (group_registration_id, invoice_number, amount, due_at)
+			VALUES ($1, $2, $3, $4)
+			RETURNING `+groupInvoiceColumns,
+			group.ID, invoiceNumber, total, dueAt,
+		), group.Invoice)
+		if err != nil {
+			return fmt.Errorf("failed to create group invoice: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit group registration: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID returns a group registration together with its invoice
+func (r *GroupRegistrationRepository) GetByID(ctx context.Context, id int64) (*model.GroupRegistration, error) {
+	query := `
+		SELECT ` + groupRegistrationColumns + `
+		FROM group_registrations g
+		JOIN schedules s ON s.id = g.schedule_id
+		WHERE g.id = $1
+	`
+
+	group := &model.GroupRegistration{}
+	err := scanGroupRegistration(r.db.QueryRow(ctx, query, id), group)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("group registration not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group registration: %w", err)
+	}
+
+	invoice := &model.GroupInvoice{}
+	err = scanGroupInvoice(r.db.QueryRow(ctx, `
+		SELECT `+groupInvoiceColumns+`
+		FROM group_invoices
+		WHERE group_registration_id = $1
+	`, id), invoice)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("failed to get group invoice: %w", err)
+	}
+	if err == nil {
+		group.Invoice = invoice
+	}
+
+	return group, nil
+}
+
+// List returns group registrations newest first; facultyCode "" lists every faculty's.
+// Results are left out; they are returned by GetByID.
+func (r *GroupRegistrationRepository) List(ctx context.Context, facultyCode string) ([]*model.GroupRegistration, error) {
+	query := `
+		SELECT ` + groupRegistrationColumns + `
+		FROM group_registrations g
+		JOIN schedules s ON s.id = g.schedule_id
+		WHERE $1 = '' OR g.faculty_code = $1
+		ORDER BY g.created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, facultyCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query group registrations: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*model.GroupRegistration
+	for rows.Next() {
+		group := &model.GroupRegistration{}
+		if err := scanGroupRegistration(rows, group); err != nil {
+			return nil, fmt.Errorf("failed to scan group registration: %w", err)
+		}
+		group.Results = nil
+		groups = append(groups, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating group registrations: %w", err)
+	}
+
+	return groups, nil
+}
+
+// lockGroupInvoice reads a group's invoice with a row lock held until the transaction ends
+func lockGroupInvoice(ctx context.Context, tx pgx.Tx, groupID int64) (*model.GroupInvoice, error) {
+	invoice := &model.GroupInvoice{}
+	err := scanGroupInvoice(tx.QueryRow(ctx, `
+		SELECT `+groupInvoiceColumns+`
+		FROM group_invoices
+		WHERE group_registration_id = $1
+		FOR UPDATE
+	`, groupID), invoice)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("group invoice not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock group invoice: %w", err)
+	}
+
+	return invoice, nil
+}
+
+// MarkInvoicePaid records that a faculty paid its group invoice, after which the
+// group's registrations can be approved. The payment is noted in the history of every
+// registration the invoice covers.
+func (r *GroupRegistrationRepository) MarkInvoicePaid(ctx context.Context, groupID int64, paidBy string) (*model.GroupInvoice, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	invoice, err := lockGroupInvoice(ctx, tx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status != model.GroupInvoiceStatusPending {
+		return nil, fmt.Errorf("cannot mark group invoice as paid: invoice is %s", invoice.Status)
+	}
+
+	err = scanGroupInvoice(tx.QueryRow(ctx, `
+		UPDATE group_invoices
+		SET status = $1, paid_at = CURRENT_TIMESTAMP, paid_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING `+groupInvoiceColumns,
+		model.GroupInvoiceStatusPaid, paidBy, invoice.ID,
+	), invoice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark group invoice as paid: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, status FROM registrations
+		WHERE group_registration_id = $1 AND status NOT IN ($2, $3)
+		ORDER BY id
+	`, groupID, model.RegistrationStatusRejected, model.RegistrationStatusCancelled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query group registrations: %w", err)
+	}
+	covered, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (model.CreateRegistrationHistory, error) {
+		history := model.CreateRegistrationHistory{
+			Notes:     fmt.Sprintf("Group invoice %s paid", invoice.InvoiceNumber),
+			ChangedBy: paidBy,
+		}
+		err := row.Scan(&history.RegistrationID, &history.Status)
+		return history, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan group registrations: %w", err)
+	}
+	for i := range covered {
+		if err := insertHistory(ctx, tx, &covered[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit group invoice: %w", err)
+	}
+
+	return invoice, nil
+}
+
+// CancelInvoice cancels an unpaid group invoice together with the registrations it
+// pays for. Their seats are offered to the waitlist; the cancelled registrations and
+// the promoted entries are returned.
+func (r *GroupRegistrationRepository) CancelInvoice(ctx context.Context, groupID int64, reason, changedBy string, claimWindow time.Duration) (*model.GroupInvoice, []*model.Registration, []*model.WaitlistEntry, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	invoice, err := lockGroupInvoice(ctx, tx, groupID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if invoice.Status != model.GroupInvoiceStatusPending {
+		return nil, nil, nil, fmt.Errorf("cannot cancel group invoice: invoice is %s", invoice.Status)
+	}
+
+	// Every registration is locked before the schedule, the same order a single
+	// cancellation takes them in
+	rows, err := tx.Query(ctx, `
+		SELECT `+registrationColumns+`
+		FROM registrations
+		WHERE group_registration_id = $1 AND status NOT IN ($2, $3)
+		ORDER BY id
+		FOR UPDATE
+	`, groupID, model.RegistrationStatusRejected, model.RegistrationStatusCancelled)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to query group registrations: %w", err)
+	}
+	var registrations []*model.Registration
+	for rows.Next() {
+		registration := &model.Registration{}
+		if err := scanRegistration(rows, registration); err != nil {
+			rows.Close()
+			return nil, nil, nil, fmt.Errorf("failed to scan registration: %w", err)
+		}
+		registrations = append(registrations, registration)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("error iterating registrations: %w", err)
+	}
+
+	notes := fmt.Sprintf("Cancelled with group invoice %s", invoice.InvoiceNumber)
+	if reason != "" {
+		notes += ": " + reason
+	}
+	var offered []*model.WaitlistEntry
+	for _, registration := range registrations {
+		entry, err := cancelLocked(ctx, tx, registration, notes, changedBy, claimWindow)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if entry != nil {
+			offered = append(offered, entry)
+		}
+	}
+
+	err = scanGroupInvoice(tx.QueryRow(ctx, `
+		UPDATE group_invoices
+		SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING `+groupInvoiceColumns,
+		model.GroupInvoiceStatusCancelled, invoice.ID,
+	), invoice)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to cancel group invoice: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to commit group invoice: %w", err)
+	}
+
+	return invoice, registrations, offered, nil
+}
@@ -0,0 +1,428 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/lottery"
+)
+
+type LotteryRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLotteryRepository(db *pgxpool.Pool) *LotteryRepository {
+	return &LotteryRepository{db: db}
+}
+
+const lotteryApplicationColumns = `id, schedule_id, student_id, status, draw_rank,
+	       registration_id, waitlist_entry_id, created_at, updated_at`
+
+func scanLotteryApplication(row rowScanner, application *model.LotteryApplication) error {
+	return row.Scan(
+		&application.ID,
+		&application.ScheduleID,
+		&application.StudentID,
+		&application.Status,
+		&application.DrawRank,
+		&application.RegistrationID,
+		&application.WaitlistEntryID,
+		&application.CreatedAt,
+		&application.UpdatedAt,
+	)
+}
+
+func getLotteryApplication(ctx context.Context, q querier, id int64, forUpdate bool) (*model.LotteryApplication, error) {
+	query := `
+		SELECT ` + lotteryApplicationColumns + `
+		FROM lottery_applications
+		WHERE id = $1
+	`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	application := &model.LotteryApplication{}
+	err := scanLotteryApplication(q.QueryRow(ctx, query, id), application)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("lottery application not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lottery application: %w", err)
+	}
+
+	return application, nil
+}
+
+// isDrawn reports whether the schedule's lottery has been drawn
+func isDrawn(ctx context.Context, q querier, scheduleID int64) (bool, error) {
+	var drawn bool
+	err := q.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM lottery_draws WHERE schedule_id = $1)`, scheduleID).Scan(&drawn)
+	if err != nil {
+		return false, fmt.Errorf("failed to check lottery draw: %w", err)
+	}
+	return drawn, nil
+}
+
+// Apply enters a student into a lottery schedule's draw
+func (r *LotteryRepository) Apply(ctx context.Context, scheduleID, studentID int64, check ScheduleCheck, eligible EligibilityCheck) (*model.LotteryApplication, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	schedule, err := lockSchedule(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case schedule == nil:
+		return nil, fmt.Errorf("schedule not found")
+	case schedule.AllocationMode != model.AllocationLottery:
+		return nil, fmt.Errorf("cannot apply: schedule does not use a lottery")
+	case schedule.Status == model.ScheduleStatusCancelled:
+		return nil, fmt.Errorf("cannot apply: schedule is cancelled")
+	}
+	if err := check(schedule); err != nil {
+		return nil, err
+	}
+	drawn, err := isDrawn(ctx, tx, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+	if drawn {
+		return nil, fmt.Errorf("cannot apply: the lottery has already been drawn")
+	}
+
+	facts, err := loadEligibilityFacts(ctx, tx, studentID, schedule)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := eligible(facts); err != nil {
+		return nil, err
+	}
+
+	var id int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO lottery_applications (schedule_id, student_id)
+		VALUES ($1, $2)
+		ON CONFLICT (schedule_id, student_id) WHERE status <> 'withdrawn' DO NOTHING
+		RETURNING id
+	`, schedule.ID, studentID).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("cannot apply: student has already applied")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply: %w", err)
+	}
+
+	application, err := getLotteryApplication(ctx, tx, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit lottery application: %w", err)
+	}
+
+	return application, nil
+}
+
+func (r *LotteryRepository) GetApplication(ctx context.Context, id int64) (*model.LotteryApplication, error) {
+	return getLotteryApplication(ctx, r.db, id, false)
+}
+
+// Withdraw takes an application out of a draw that has not happened yet
+func (r *LotteryRepository) Withdraw(ctx context.Context, id int64) (*model.LotteryApplication, error) {
+	application, err := getLotteryApplication(ctx, r.db, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.QueryRow(ctx, `
+		UPDATE lottery_applications SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = $3
+		RETURNING status, updated_at
+	`, model.LotteryStatusWithdrawn, id, model.LotteryStatusApplied).Scan(&application.Status, &application.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("cannot withdraw: application is %s", application.Status)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to withdraw application: %w", err)
+	}
+
+	return application, nil
+}
+
+// ListBySchedule returns a schedule's applications in draw order once drawn, otherwise by application time
+func (r *LotteryRepository) ListBySchedule(ctx context.Context, scheduleID int64) ([]*model.LotteryApplication, error) {
+	query := `
+		SELECT ` + lotteryApplicationColumns + `
+		FROM lottery_applications
+		WHERE schedule_id = $1
+		ORDER BY draw_rank NULLS LAST, id
+	`
+
+	return r.list(ctx, query, scheduleID)
+}
+
+// ListByStudent returns every application of a student, newest first
+func (r *LotteryRepository) ListByStudent(ctx context.Context, studentID int64) ([]*model.LotteryApplication, error) {
+	query := `
+		SELECT ` + lotteryApplicationColumns + `
+		FROM lottery_applications
+		WHERE student_id = $1
+		ORDER BY created_at DESC
+	`
+
+	return r.list(ctx, query, studentID)
+}
+
+func (r *LotteryRepository) list(ctx context.Context, query string, args ...any) ([]*model.LotteryApplication, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lottery applications: %w", err)
+	}
+	defer rows.Close()
+
+	var applications []*model.LotteryApplication
+	for rows.Next() {
+		application := &model.LotteryApplication{}
+		if err := scanLotteryApplication(rows, application); err != nil {
+			return nil, fmt.Errorf("failed to scan lottery application: %w", err)
+		}
+		applications = append(applications, application)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating lottery applications: %w", err)
+	}
+
+	return applications, nil
+}
+
+// ListDue returns the lottery schedules whose application window has closed but
+// which have not been drawn yet
+func (r *LotteryRepository) ListDue(ctx context.Context) ([]int64, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT s.id FROM schedules s
+		WHERE s.allocation_mode = $1 AND s.status = $2
+		  AND COALESCE(s.registration_closes_at, s.date_time) <= CURRENT_TIMESTAMP
+		  AND NOT EXISTS (SELECT 1 FROM lottery_draws d WHERE d.schedule_id = s.id)
+		ORDER BY s.id
+	`, model.AllocationLottery, model.ScheduleStatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due lotteries: %w", err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan due lotteries: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Draw orders the applications of a schedule with the seeded lottery and hands out
+// seats in that order: each applicant gets a seat while one is available to them
+// (quota segments included), everyone after that joins the waitlist in draw order.
+// Applicants drawn for a seat are checked against the eligibility rules again, since
+// they may have registered elsewhere since applying; those who fail are skipped.
+// The seed and the ordered results are stored with the draw.
+func (r *LotteryRepository) Draw(ctx context.Context, scheduleID int64, seed string, eligible EligibilityCheck, paymentDeadline time.Duration, drawnBy string) (*model.LotteryDraw, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	schedule, err := lockSchedule(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case schedule == nil:
+		return nil, fmt.Errorf("schedule not found")
+	case schedule.AllocationMode != model.AllocationLottery:
+		return nil, fmt.Errorf("cannot draw: schedule does not use a lottery")
+	case schedule.Status == model.ScheduleStatusCancelled:
+		return nil, fmt.Errorf("cannot draw: schedule is cancelled")
+	case schedule.RegistrationStatusAt(time.Now()) != model.RegistrationWindowClosed:
+		return nil, fmt.Errorf("cannot draw: applications are still open")
+	}
+	drawn, err := isDrawn(ctx, tx, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+	if drawn {
+		return nil, fmt.Errorf("cannot draw: the lottery has already been drawn")
+	}
+
+	// Applications in ascending ID order are the input the seed is applied to
+	type applicant struct {
+		applicationID int64
+		studentID     int64
+		ptype         string
+		major         string
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT a.id, a.student_id, p.participant_type, p.major
+		FROM lottery_applications a
+		JOIN participants p ON p.id = a.student_id
+		WHERE a.schedule_id = $1 AND a.status = $2
+		ORDER BY a.id
+		FOR UPDATE OF a
+	`, schedule.ID, model.LotteryStatusApplied)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lottery applications: %w", err)
+	}
+	applicants, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (applicant, error) {
+		var a applicant
+		err := row.Scan(&a.applicationID, &a.studentID, &a.ptype, &a.major)
+		return a, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan lottery applications: %w", err)
+	}
+
+	order, err := lottery.Draw(seed, len(applicants))
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := lockSeatPool(ctx, tx, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	draw := &model.LotteryDraw{
+		ScheduleID: schedule.ID,
+		Seed:       seed,
+		Algorithm:  lottery.Algorithm,
+		Applicants: len(applicants),
+		Results:    make([]model.LotteryResult, 0, len(applicants)),
+		DrawnBy:    drawnBy,
+	}
+	for i, index := range order {
+		a := applicants[index]
+		result := model.LotteryResult{Rank: i + 1, ApplicationID: a.applicationID, StudentID: a.studentID}
+
+		segment, ok := pool.allocate(a.ptype, a.major)
+		ineligible := false
+		if ok {
+			facts, err := loadEligibilityFacts(ctx, tx, a.studentID, schedule)
+			if err != nil {
+				return nil, err
+			}
+			// The check only fails on rule violations; the seat stays for the next applicant
+			_, err = eligible(facts)
+			ineligible = err != nil
+		}
+
+		switch {
+		case ineligible:
+			result.Outcome = model.LotteryStatusIneligible
+		case ok:
+			if err := pool.take(ctx, tx, segment); err != nil {
+				return nil, err
+			}
+			registration := &model.Registration{StudentID: a.studentID}
+			if segment != nil {
+				registration.SegmentID = &segment.ID
+			}
+			notes := fmt.Sprintf("Registered by lottery draw (rank %d)", result.Rank)
+			if err := insertRegistration(ctx, tx, registration, schedule, paymentDeadline, notes, drawnBy); err != nil {
+				return nil, err
+			}
+			result.Outcome = model.LotteryStatusWon
+			result.RegistrationID = &registration.ID
+			draw.Winners++
+		default:
+			var entryID int64
+			err := tx.QueryRow(ctx, `
+				INSERT INTO waitlist_entries (schedule_id, student_id, sequence)
+				SELECT $1, $2, COALESCE(MAX(sequence), 0) + 1
+				FROM waitlist_entries
+				WHERE schedule_id = $1
+				RETURNING id
+			`, schedule.ID, a.studentID).Scan(&entryID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to waitlist application %d: %w", a.applicationID, err)
+			}
+			result.Outcome = model.LotteryStatusWaitlisted
+			result.WaitlistEntryID = &entryID
+			draw.Waitlisted++
+		}
+
+		_, err := tx.Exec(ctx, `
+			UPDATE lottery_applications
+			SET status = $1, draw_rank = $2, registration_id = $3, waitlist_entry_id = $4, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $5
+		`, result.Outcome, result.Rank, result.RegistrationID, result.WaitlistEntryID, a.applicationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record draw result: %w", err)
+		}
+
+		draw.Results = append(draw.Results, result)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO lottery_draws (
+			schedule_id, seed, algorithm, applicants, winners, waitlisted, results, drawn_by
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8
+		) RETURNING id, drawn_at
+	`,
+		draw.ScheduleID,
+		draw.Seed,
+		draw.Algorithm,
+		draw.Applicants,
+		draw.Winners,
+		draw.Waitlisted,
+		draw.Results,
+		draw.DrawnBy,
+	).Scan(&draw.ID, &draw.DrawnAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store lottery draw: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit lottery draw: %w", err)
+	}
+
+	return draw, nil
+}
+
+func (r *LotteryRepository) GetDraw(ctx context.Context, scheduleID int64) (*model.LotteryDraw, error) {
+	draw := &model.LotteryDraw{}
+	err := r.db.QueryRow(ctx, `
+		SELECT id, schedule_id, seed, algorithm, applicants, winners, waitlisted, results, drawn_by, drawn_at
+		FROM lottery_draws
+		WHERE schedule_id = $1
+	`, scheduleID).Scan(
+		&draw.ID,
+		&draw.ScheduleID,
+		&draw.Seed,
+		&draw.Algorithm,
+		&draw.Applicants,
+		&draw.Winners,
+		&draw.Waitlisted,
+		&draw.Results,
+		&draw.DrawnBy,
+		&draw.DrawnAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("lottery draw not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lottery draw: %w", err)
+	}
+
+	return draw, nil
+}
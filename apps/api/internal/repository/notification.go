@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type NotificationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationRepository(db *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Create stores the notification and returns the student's email address for delivery
+func (r *NotificationRepository) Create(ctx context.Context, notification *model.Notification) (string, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO notifications (student_id, subject, body)
+			VALUES ($1, $2, $3)
+			RETURNING id, student_id, created_at
+		)
+		SELECT i.id, i.created_at, s.email
+		FROM inserted i
+		JOIN participants s ON s.id = i.student_id
+	`
+
+	var email string
+	err := r.db.QueryRow(ctx, query,
+		notification.StudentID,
+		notification.Subject,
+		notification.Body,
+	).Scan(
+		&notification.ID,
+		&notification.CreatedAt,
+		&email,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return email, nil
+}
+
+func (r *NotificationRepository) ListByStudent(ctx context.Context, studentID int64, limit int) ([]*model.Notification, error) {
+	query := `
+		SELECT id, student_id, subject, body, read_at, created_at
+		FROM notifications
+		WHERE student_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, studentID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*model.Notification
+	for rows.Next() {
+		notification := &model.Notification{}
+		err := rows.Scan(
+			&notification.ID,
+			&notification.StudentID,
+			&notification.Subject,
+			&notification.Body,
+			&notification.ReadAt,
+			&notification.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (r *NotificationRepository) MarkRead(ctx context.Context, id, studentID int64) error {
+	query := `
+		UPDATE notifications SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+		WHERE id = $1 AND student_id = $2
+		RETURNING id
+	`
+
+	err := r.db.QueryRow(ctx, query, id, studentID).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("notification not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,351 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type ParticipantRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewParticipantRepository(db *pgxpool.Pool) *ParticipantRepository {
+	return &ParticipantRepository{db: db}
+}
+
+const participantColumns = `id, participant_type, COALESCE(student_number, ''), COALESCE(national_id, ''),
+	       COALESCE(institution, ''), full_name, phone, email, major, active, fee_tier,
+	       created_at, updated_at`
+
+func scanParticipant(row rowScanner, participant *model.Participant) error {
+	return row.Scan(
+		&participant.ID,
+		&participant.Type,
+		&participant.StudentNumber,
+		&participant.NationalID,
+		&participant.Institution,
+		&participant.FullName,
+		&participant.Phone,
+		&participant.Email,
+		&participant.Major,
+		&participant.Active,
+		&participant.FeeTier,
+		&participant.CreatedAt,
+		&participant.UpdatedAt,
+	)
+}
+
+// duplicateParticipant translates unique violations on the identity columns
+func duplicateParticipant(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		switch pgErr.ConstraintName {
+		case "idx_participants_student_number":
+			return fmt.Errorf("cannot save participant: student number is already registered")
+		case "idx_participants_national_id":
+			return fmt.Errorf("cannot save participant: national ID is already registered")
+		}
+	}
+	return nil
+}
+
+func (r *ParticipantRepository) Create(ctx context.Context, participant *model.Participant) error {
+	query := `
+		INSERT INTO participants (
+			participant_type, student_number, national_id, institution,
+			full_name, phone, email, major, active, fee_tier
+		) VALUES (
+			$1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), $5, $6, $7, $8, $9, $10
+		) RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		participant.Type,
+		participant.StudentNumber,
+		participant.NationalID,
+		participant.Institution,
+		participant.FullName,
+		participant.Phone,
+		participant.Email,
+		participant.Major,
+		participant.Active,
+		participant.FeeTier,
+	).Scan(&participant.ID, &participant.CreatedAt, &participant.UpdatedAt)
+	if dup := duplicateParticipant(err); dup != nil {
+		return dup
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create participant: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ParticipantRepository) GetByID(ctx context.Context, id int64) (*model.Participant, error) {
+	query := `
+		SELECT ` + participantColumns + `
+		FROM participants
+		WHERE id = $1
+	`
+
+	participant := &model.Participant{}
+	err := scanParticipant(r.db.QueryRow(ctx, query, id), participant)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("participant not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get participant: %w", err)
+	}
+
+	return participant, nil
+}
+
+// List returns participants ordered by name, optionally of one type only
+func (r *ParticipantRepository) List(ctx context.Context, participantType string) ([]*model.Participant, error) {
+	query := `
+		SELECT ` + participantColumns + `
+		FROM participants
+		WHERE $1 = '' OR participant_type = $1
+		ORDER BY full_name, id
+	`
+
+	rows, err := r.db.Query(ctx, query, participantType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query participants: %w", err)
+	}
+	defer rows.Close()
+
+	var participants []*model.Participant
+	for rows.Next() {
+		participant := &model.Participant{}
+		if err := scanParticipant(rows, participant); err != nil {
+			return nil, fmt.Errorf("failed to scan participant: %w", err)
+		}
+		participants = append(participants, participant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating participants: %w", err)
+	}
+
+	return participants, nil
+}
+
+func (r *ParticipantRepository) Update(ctx context.Context, participant *model.Participant) error {
+	query := `
+		UPDATE participants
+		SET phone = $1, email = $2, institution = NULLIF($3, ''), fee_tier = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		participant.Phone,
+		participant.Email,
+		participant.Institution,
+		participant.FeeTier,
+		participant.ID,
+	).Scan(&participant.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("participant not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update participant: %w", err)
+	}
+
+	return nil
+}
+
+// participantProfile returns what a participant is matched on against quota segments
+func participantProfile(ctx context.Context, q querier, participantID int64) (participantType, major string, err error) {
+	err = q.QueryRow(ctx, `SELECT participant_type, major FROM participants WHERE id = $1`, participantID).Scan(&participantType, &major)
+	if err == pgx.ErrNoRows {
+		return "", "", fmt.Errorf("participant not found")
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get participant: %w", err)
+	}
+
+	return participantType, major, nil
+}
+
+const feeTierColumns = `code, name, participant_type, amount::float8, prices, required_fields, active, created_at, updated_at`
+
+func scanFeeTier(row rowScanner, tier *model.FeeTier) error {
+	return row.Scan(
+		&tier.Code,
+		&tier.Name,
+		&tier.ParticipantType,
+		&tier.Amount,
+		&tier.Prices,
+		&tier.RequiredFields,
+		&tier.Active,
+		&tier.CreatedAt,
+		&tier.UpdatedAt,
+	)
+}
+
+func (r *ParticipantRepository) CreateFeeTier(ctx context.Context, tier *model.FeeTier) error {
+	query := `
+		INSERT INTO fee_tiers (code, name, participant_type, amount, prices, required_fields)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (code) DO NOTHING
+		RETURNING active, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		tier.Code,
+		tier.Name,
+		tier.ParticipantType,
+		tier.Amount,
+		tier.Prices,
+		tier.RequiredFields,
+	).Scan(&tier.Active, &tier.CreatedAt, &tier.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("cannot create fee tier: code %q already exists", tier.Code)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create fee tier: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ParticipantRepository) GetFeeTier(ctx context.Context, code string) (*model.FeeTier, error) {
+	query := `
+		SELECT ` + feeTierColumns + `
+		FROM fee_tiers
+		WHERE code = $1
+	`
+
+	tier := &model.FeeTier{}
+	err := scanFeeTier(r.db.QueryRow(ctx, query, code), tier)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("fee tier not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee tier: %w", err)
+	}
+
+	return tier, nil
+}
+
+func (r *ParticipantRepository) ListFeeTiers(ctx context.Context) ([]*model.FeeTier, error) {
+	query := `
+		SELECT ` + feeTierColumns + `
+		FROM fee_tiers
+		ORDER BY participant_type DESC, amount, code
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fee tiers: %w", err)
+	}
+	defer rows.Close()
+
+	var tiers []*model.FeeTier
+	for rows.Next() {
+		tier := &model.FeeTier{}
+		if err := scanFeeTier(rows, tier); err != nil {
+			return nil, fmt.Errorf("failed to scan fee tier: %w", err)
+		}
+		tiers = append(tiers, tier)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fee tiers: %w", err)
+	}
+
+	return tiers, nil
+}
+
+func (r *ParticipantRepository) UpdateFeeTier(ctx context.Context, tier *model.FeeTier) error {
+	query := `
+		UPDATE fee_tiers
+		SET name = $1, amount = $2, prices = $3, required_fields = $4, active = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE code = $6
+		RETURNING participant_type, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		tier.Name,
+		tier.Amount,
+		tier.Prices,
+		tier.RequiredFields,
+		tier.Active,
+		tier.Code,
+	).Scan(&tier.ParticipantType, &tier.CreatedAt, &tier.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("fee tier not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update fee tier: %w", err)
+	}
+
+	return nil
+}
+
+// SavePhoto stores or replaces a participant's photo
+func (r *ParticipantRepository) SavePhoto(ctx context.Context, photo *model.ParticipantPhoto) error {
+	query := `
+		INSERT INTO participant_photos (participant_id, content_type, data, width, height)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (participant_id) DO UPDATE
+		SET content_type = EXCLUDED.content_type,
+		    data = EXCLUDED.data,
+		    width = EXCLUDED.width,
+		    height = EXCLUDED.height,
+		    updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		photo.ParticipantID,
+		photo.ContentType,
+		photo.Data,
+		photo.Width,
+		photo.Height,
+	).Scan(&photo.UpdatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return fmt.Errorf("participant not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save photo: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ParticipantRepository) GetPhoto(ctx context.Context, participantID int64) (*model.ParticipantPhoto, error) {
+	query := `
+		SELECT participant_id, content_type, data, width, height, updated_at
+		FROM participant_photos
+		WHERE participant_id = $1
+	`
+
+	photo := &model.ParticipantPhoto{}
+	err := r.db.QueryRow(ctx, query, participantID).Scan(
+		&photo.ParticipantID,
+		&photo.ContentType,
+		&photo.Data,
+		&photo.Width,
+		&photo.Height,
+		&photo.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("photo not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get photo: %w", err)
+	}
+
+	return photo, nil
+}
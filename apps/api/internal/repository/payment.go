@@ -0,0 +1,443 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type PaymentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPaymentRepository(db *pgxpool.Pool) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+const paymentColumns = `id, registration_id, amount::float8, payment_method, payment_status,
+	       COALESCE(invoice_number, ''), invoice_issued_at,
+	       COALESCE(fee_tier, ''), COALESCE(schedule_type, ''), base_amount::float8, discount_amount::float8,
+	       voucher_id, COALESCE(voucher_code, ''), unique_code, refunded_amount::float8, COALESCE(receipt_image, ''), COALESCE(notes, ''),
+	       expired_at, paid_at, COALESCE(verified_by, ''), verified_at, created_at, updated_at,
+	       COALESCE(bank_name, ''), COALESCE(account_number, ''), COALESCE(account_name, ''), transfer_date`
+
+func scanPayment(row rowScanner, payment *model.Payment) error {
+	var expiredAt, paidAt, verifiedAt, transferDate *time.Time
+	err := row.Scan(
+		&payment.ID,
+		&payment.RegistrationID,
+		&payment.Amount,
+		&payment.PaymentMethod,
+		&payment.PaymentStatus,
+		&payment.InvoiceNumber,
+		&payment.InvoiceIssuedAt,
+		&payment.FeeTier,
+		&payment.ScheduleType,
+		&payment.BaseAmount,
+		&payment.DiscountAmount,
+		&payment.VoucherID,
+		&payment.VoucherCode,
+		&payment.UniqueCode,
+		&payment.RefundedAmount,
+		&payment.ReceiptImage,
+		&payment.Notes,
+		&expiredAt,
+		&paidAt,
+		&payment.VerifiedBy,
+		&verifiedAt,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+		&payment.BankName,
+		&payment.AccountNumber,
+		&payment.AccountName,
+		&transferDate,
+	)
+	if err != nil {
+		return err
+	}
+	if expiredAt != nil {
+		payment.ExpiredAt = *expiredAt
+	}
+	if paidAt != nil {
+		payment.PaidAt = *paidAt
+	}
+	if verifiedAt != nil {
+		payment.VerifiedAt = *verifiedAt
+	}
+	if transferDate != nil {
+		payment.TransferDate = *transferDate
+	}
+	return nil
+}
+
+func (r *PaymentRepository) GetByID(ctx context.Context, id int64) (*model.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE id = $1
+	`
+
+	payment := &model.Payment{}
+	err := scanPayment(r.db.QueryRow(ctx, query, id), payment)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("payment not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	return payment, nil
+}
+
+// feeTierFor returns the fee tier of a participant
+func feeTierFor(ctx context.Context, q querier, participantID int64) (*model.FeeTier, error) {
+	tier := &model.FeeTier{}
+	err := scanFeeTier(q.QueryRow(ctx, `
+		SELECT `+feeTierColumns+`
+		FROM fee_tiers
+		WHERE code = (SELECT fee_tier FROM participants WHERE id = $1)
+	`, participantID), tier)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("participant not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee tier: %w", err)
+	}
+
+	return tier, nil
+}
+
+// applicableVoucher reads a voucher by code and checks that it can be used for the
+// tier now; forUpdate locks it so its uses can be counted
+func applicableVoucher(ctx context.Context, q querier, code, feeTier string, forUpdate bool) (*model.Voucher, error) {
+	query := `
+		SELECT ` + voucherColumns + `
+		FROM vouchers
+		WHERE code = $1
+	`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	voucher := &model.Voucher{}
+	err := scanVoucher(q.QueryRow(ctx, query, strings.ToUpper(strings.TrimSpace(code))), voucher)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("invalid voucher: code %q does not exist", code)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voucher: %w", err)
+	}
+
+	now := time.Now()
+	switch {
+	case !voucher.Active:
+		return nil, fmt.Errorf("invalid voucher: %s is no longer active", voucher.Code)
+	case voucher.ValidFrom != nil && now.Before(*voucher.ValidFrom):
+		return nil, fmt.Errorf("invalid voucher: %s is valid from %s", voucher.Code, voucher.ValidFrom.Format(time.RFC3339))
+	case voucher.ValidUntil != nil && !now.Before(*voucher.ValidUntil):
+		return nil, fmt.Errorf("invalid voucher: %s has expired", voucher.Code)
+	case voucher.MaxUses != nil && voucher.UsedCount >= *voucher.MaxUses:
+		return nil, fmt.Errorf("invalid voucher: %s has been used up", voucher.Code)
+	case len(voucher.FeeTiers) > 0 && !slices.Contains(voucher.FeeTiers, feeTier):
+		return nil, fmt.Errorf("invalid voucher: %s does not apply to the %s fee tier", voucher.Code, feeTier)
+	}
+
+	return voucher, nil
+}
+
+// quote prices a registration from its participant's fee tier and the type of its schedule
+func quote(ctx context.Context, q querier, registration *model.Registration, voucherCode string, forUpdate bool) (*model.PriceQuote, error) {
+	tier, err := feeTierFor(ctx, q, registration.StudentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var subsidized bool
+	err = q.QueryRow(ctx, `SELECT subsidized FROM schedules WHERE plot_id = $1`, registration.TestPlotID).Scan(&subsidized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+	schedule := &model.Schedule{Subsidized: subsidized}
+
+	result := &model.PriceQuote{
+		RegistrationID: registration.ID,
+		FeeTier:        tier.Code,
+		ScheduleType:   schedule.ScheduleType(),
+		BaseAmount:     tier.Price(schedule.ScheduleType()),
+	}
+	if strings.TrimSpace(voucherCode) != "" {
+		voucher, err := applicableVoucher(ctx, q, voucherCode, tier.Code, forUpdate)
+		if err != nil {
+			return nil, err
+		}
+		result.VoucherID = &voucher.ID
+		result.VoucherCode = voucher.Code
+		result.DiscountAmount = voucher.Discount(result.BaseAmount)
+	}
+	result.Amount = result.BaseAmount - result.DiscountAmount
+
+	return result, nil
+}
+
+// Quote prices a registration without reserving the voucher
+func (r *PaymentRepository) Quote(ctx context.Context, registration *model.Registration, voucherCode string) (*model.PriceQuote, error) {
+	return quote(ctx, r.db, registration, voucherCode, false)
+}
+
+// Create prices the registration and opens a payment for it. A voucher use is counted
+// while the payment holds it. The payment expires after deadline, but no later than the test.
+func (r *PaymentRepository) Create(ctx context.Context, req *model.CreatePayment, deadline time.Duration, changedBy string) (*model.Payment, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	registration, err := lockRegistration(ctx, tx, req.RegistrationID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case registration.Status != model.RegistrationStatusPending:
+		return nil, fmt.Errorf("cannot create payment: registration is %s", registration.Status)
+	case registration.GroupID != nil:
+		return nil, fmt.Errorf("cannot create payment: registration is paid through its group invoice")
+	}
+
+	var active bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM payments
+			WHERE registration_id = $1 AND payment_status IN ($2, $3, $4, $5)
+		)
+	`, registration.ID, model.PaymentStatusPending, model.PaymentStatusProcessing, model.PaymentStatusPaid, model.PaymentStatusVerified).Scan(&active)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check payments: %w", err)
+	}
+	if active {
+		return nil, fmt.Errorf("cannot create payment: registration already has an active payment")
+	}
+
+	price, err := quote(ctx, tx, registration, req.VoucherCode, true)
+	if err != nil {
+		return nil, err
+	}
+	if price.VoucherID != nil {
+		_, err := tx.Exec(ctx, `
+			UPDATE vouchers SET used_count = used_count + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1
+		`, *price.VoucherID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to redeem voucher: %w", err)
+		}
+	}
+
+	var uniqueCode int
+	if price.Amount > 0 && req.PaymentMethod == model.PaymentMethodBankTransfer {
+		if uniqueCode, err = allocateUniqueCode(ctx, tx, price.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	expiredAt := time.Now().Add(deadline)
+	if registration.TestDate.Before(expiredAt) {
+		expiredAt = registration.TestDate
+	}
+
+	payment := &model.Payment{}
+	err = scanPayment(tx.QueryRow(ctx, `
+		INSERT INTO payments (
+			registration_id, amount, payment_method, payment_status, expired_at,
+			fee_tier, schedule_type, base_amount, discount_amount, voucher_id, voucher_code, unique_code
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), $12
+		) RETURNING `+paymentColumns,
+		registration.ID,
+		price.Amount+float64(uniqueCode),
+		req.PaymentMethod,
+		model.PaymentStatusPending,
+		expiredAt,
+		price.FeeTier,
+		price.ScheduleType,
+		price.BaseAmount,
+		price.DiscountAmount,
+		price.VoucherID,
+		price.VoucherCode,
+		uniqueCode,
+	), payment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment: %w", err)
+	}
+
+	if err := issueInvoice(ctx, tx, payment); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE registrations SET payment_id = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+	`, payment.ID, registration.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link payment: %w", err)
+	}
+
+	notes := fmt.Sprintf("Payment %d of %.2f created, invoice %s", payment.ID, payment.Amount, payment.InvoiceNumber)
+	if payment.VoucherCode != "" {
+		notes += fmt.Sprintf(" (voucher %s, discount %.2f)", payment.VoucherCode, payment.DiscountAmount)
+	}
+	err = insertHistory(ctx, tx, &model.CreateRegistrationHistory{
+		RegistrationID: registration.ID,
+		Status:         registration.Status,
+		Notes:          notes,
+		ChangedBy:      changedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit payment: %w", err)
+	}
+
+	return payment, nil
+}
+
+// nextInvoiceNumber generates the next payment invoice number for the current month.
+// The advisory lock serializes numbering until the transaction ends.
+func nextInvoiceNumber(ctx context.Context, tx pgx.Tx) (string, error) {
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('payments.invoice_number'))`); err != nil {
+		return "", fmt.Errorf("failed to lock invoice numbering: %w", err)
+	}
+
+	var count, month, year int
+	err := tx.QueryRow(ctx, `
+		SELECT COUNT(*), EXTRACT(MONTH FROM CURRENT_TIMESTAMP)::int, EXTRACT(YEAR FROM CURRENT_TIMESTAMP)::int
+		FROM payments
+		WHERE date_trunc('month', invoice_issued_at) = date_trunc('month', CURRENT_TIMESTAMP)
+	`).Scan(&count, &month, &year)
+	if err != nil {
+		return "", fmt.Errorf("failed to count invoices: %w", err)
+	}
+
+	return fmt.Sprintf("I%03d/%s/%d", count+1, romanMonths[month-1], year), nil
+}
+
+// issueInvoice numbers the invoice of a payment that does not have one yet
+func issueInvoice(ctx context.Context, tx pgx.Tx, payment *model.Payment) error {
+	if payment.InvoiceNumber != "" {
+		return nil
+	}
+
+	number, err := nextInvoiceNumber(ctx, tx)
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRow(ctx, `
+		UPDATE payments SET invoice_number = $1, invoice_issued_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING invoice_number, invoice_issued_at
+	`, number, payment.ID).Scan(&payment.InvoiceNumber, &payment.InvoiceIssuedAt)
+	if err != nil {
+		return fmt.Errorf("failed to issue invoice: %w", err)
+	}
+
+	return nil
+}
+
+// IssueInvoice returns the payment with its invoice number, numbering payments that
+// were created before invoices were issued
+func (r *PaymentRepository) IssueInvoice(ctx context.Context, id int64) (*model.Payment, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	payment, err := lockPayment(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := issueInvoice(ctx, tx, payment); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit invoice: %w", err)
+	}
+
+	return payment, nil
+}
+
+// allocateUniqueCode picks a random offset that makes amount+offset differ from every
+// pending payment, so a credit of that amount identifies one payment. The offset is
+// held by the pending payment and becomes free again once it is paid, or once
+// UniqueCodeCooldown has passed since it expired or was cancelled, since the student
+// may still transfer after that. The advisory lock serializes allocation until the
+// transaction ends.
+func allocateUniqueCode(ctx context.Context, tx pgx.Tx, amount float64) (int, error) {
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('payments.unique_code'))`); err != nil {
+		return 0, fmt.Errorf("failed to lock unique codes: %w", err)
+	}
+
+	var code int
+	err := tx.QueryRow(ctx, `
+		SELECT c
+		FROM generate_series(1, $2::int) AS c
+		WHERE NOT EXISTS (
+			SELECT 1 FROM payments
+			WHERE amount = $1::numeric + c
+			  AND (payment_status = $3 OR (payment_status IN ($4, $5) AND updated_at > $6))
+		)
+		ORDER BY random()
+		LIMIT 1
+	`, amount, model.MaxUniqueCode, model.PaymentStatusPending,
+		model.PaymentStatusExpired, model.PaymentStatusCancelled, time.Now().Add(-model.UniqueCodeCooldown)).Scan(&code)
+	if err == pgx.ErrNoRows {
+		return 0, fmt.Errorf("cannot create payment: every unique code for this amount is in use; try again later")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate unique code: %w", err)
+	}
+
+	return code, nil
+}
+
+// releaseVoucherUse gives back the voucher use of a payment that will not be paid
+func releaseVoucherUse(ctx context.Context, tx pgx.Tx, paymentID int64) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE vouchers SET used_count = used_count - 1, updated_at = CURRENT_TIMESTAMP
+		WHERE used_count > 0 AND id = (SELECT voucher_id FROM payments WHERE id = $1)
+	`, paymentID)
+	if err != nil {
+		return fmt.Errorf("failed to release voucher: %w", err)
+	}
+	return nil
+}
+
+// cancelPendingPayments cancels the unpaid payments of a registration that was withdrawn
+func cancelPendingPayments(ctx context.Context, tx pgx.Tx, registrationID int64) error {
+	rows, err := tx.Query(ctx, `
+		UPDATE payments SET payment_status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE registration_id = $2 AND payment_status = $3
+		RETURNING id
+	`, model.PaymentStatusCancelled, registrationID, model.PaymentStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to cancel payments: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return fmt.Errorf("failed to cancel payments: %w", err)
+	}
+
+	for _, id := range ids {
+		if err := releaseVoucherUse(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,520 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type ProctorRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewProctorRepository(db *pgxpool.Pool) *ProctorRepository {
+	return &ProctorRepository{db: db}
+}
+
+const proctorColumns = `id, full_name, email, phone, active, created_at, updated_at`
+
+func scanProctor(row rowScanner, proctor *model.Proctor) error {
+	return row.Scan(
+		&proctor.ID,
+		&proctor.FullName,
+		&proctor.Email,
+		&proctor.Phone,
+		&proctor.Active,
+		&proctor.CreatedAt,
+		&proctor.UpdatedAt,
+	)
+}
+
+// duplicateProctor translates the unique violation on the email address
+func duplicateProctor(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_proctors_email" {
+		return fmt.Errorf("cannot save proctor: email is already registered")
+	}
+	return nil
+}
+
+func (r *ProctorRepository) Create(ctx context.Context, proctor *model.Proctor) error {
+	query := `
+		INSERT INTO proctors (full_name, email, phone)
+		VALUES ($1, $2, $3)
+		RETURNING ` + proctorColumns
+
+	err := scanProctor(r.db.QueryRow(ctx, query, proctor.FullName, proctor.Email, proctor.Phone), proctor)
+	if dup := duplicateProctor(err); dup != nil {
+		return dup
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create proctor: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ProctorRepository) GetByID(ctx context.Context, id int64) (*model.Proctor, error) {
+	query := `
+		SELECT ` + proctorColumns + `
+		FROM proctors
+		WHERE id = $1
+	`
+
+	proctor := &model.Proctor{}
+	err := scanProctor(r.db.QueryRow(ctx, query, id), proctor)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("proctor not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proctor: %w", err)
+	}
+
+	return proctor, nil
+}
+
+// List returns proctors ordered by name, optionally the active ones only
+func (r *ProctorRepository) List(ctx context.Context, activeOnly bool) ([]*model.Proctor, error) {
+	query := `
+		SELECT ` + proctorColumns + `
+		FROM proctors
+		WHERE active OR NOT $1
+		ORDER BY full_name, id
+	`
+
+	rows, err := r.db.Query(ctx, query, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query proctors: %w", err)
+	}
+	defer rows.Close()
+
+	var proctors []*model.Proctor
+	for rows.Next() {
+		proctor := &model.Proctor{}
+		if err := scanProctor(rows, proctor); err != nil {
+			return nil, fmt.Errorf("failed to scan proctor: %w", err)
+		}
+		proctors = append(proctors, proctor)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating proctors: %w", err)
+	}
+
+	return proctors, nil
+}
+
+func (r *ProctorRepository) Update(ctx context.Context, proctor *model.Proctor) error {
+	query := `
+		UPDATE proctors
+		SET full_name = $1, email = $2, phone = $3, active = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+		RETURNING ` + proctorColumns
+
+	err := scanProctor(r.db.QueryRow(ctx, query,
+		proctor.FullName,
+		proctor.Email,
+		proctor.Phone,
+		proctor.Active,
+		proctor.ID,
+	), proctor)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("proctor not found")
+	}
+	if dup := duplicateProctor(err); dup != nil {
+		return dup
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update proctor: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ProctorRepository) AddAvailability(ctx context.Context, availability *model.ProctorAvailability) error {
+	query := `
+		INSERT INTO proctor_availability (proctor_id, starts_at, ends_at, notes)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		availability.ProctorID,
+		availability.StartsAt,
+		availability.EndsAt,
+		availability.Notes,
+	).Scan(&availability.ID, &availability.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return fmt.Errorf("proctor not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add availability: %w", err)
+	}
+
+	return nil
+}
+
+// ListAvailability returns the proctor's availability periods that have not ended
+func (r *ProctorRepository) ListAvailability(ctx context.Context, proctorID int64) ([]*model.ProctorAvailability, error) {
+	query := `
+		SELECT id, proctor_id, starts_at, ends_at, COALESCE(notes, ''), created_at
+		FROM proctor_availability
+		WHERE proctor_id = $1 AND ends_at > CURRENT_TIMESTAMP
+		ORDER BY starts_at, id
+	`
+
+	rows, err := r.db.Query(ctx, query, proctorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query availability: %w", err)
+	}
+	defer rows.Close()
+
+	var periods []*model.ProctorAvailability
+	for rows.Next() {
+		availability := &model.ProctorAvailability{}
+		err := rows.Scan(
+			&availability.ID,
+			&availability.ProctorID,
+			&availability.StartsAt,
+			&availability.EndsAt,
+			&availability.Notes,
+			&availability.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan availability: %w", err)
+		}
+		periods = append(periods, availability)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating availability: %w", err)
+	}
+
+	return periods, nil
+}
+
+// lockProctor serializes changes to one proctor's assignments and availability so
+// two overlapping sessions cannot both pass the conflict check
+func lockProctor(ctx context.Context, tx pgx.Tx, proctorID int64) (*model.Proctor, error) {
+	query := `
+		SELECT ` + proctorColumns + `
+		FROM proctors
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	proctor := &model.Proctor{}
+	err := scanProctor(tx.QueryRow(ctx, query, proctorID), proctor)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("proctor not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock proctor: %w", err)
+	}
+
+	return proctor, nil
+}
+
+// DeleteAvailability removes an availability period unless an upcoming session the
+// proctor is assigned to would no longer be covered by another period
+func (r *ProctorRepository) DeleteAvailability(ctx context.Context, proctorID, id int64, sessionLength time.Duration) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := lockProctor(ctx, tx, proctorID); err != nil {
+		return err
+	}
+
+	var (
+		plotID   int64
+		dateTime time.Time
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT s.plot_id, s.date_time
+		FROM proctor_availability a
+		JOIN schedule_proctors sp ON sp.proctor_id = a.proctor_id AND sp.removed_at IS NULL
+		JOIN schedules s ON s.id = sp.schedule_id
+		WHERE a.id = $1 AND a.proctor_id = $2
+		  AND s.status = $3 AND s.date_time > CURRENT_TIMESTAMP
+		  AND s.date_time >= a.starts_at AND s.date_time + make_interval(secs => $4) <= a.ends_at
+		  AND NOT EXISTS (
+		      SELECT 1 FROM proctor_availability o
+		      WHERE o.proctor_id = a.proctor_id AND o.id <> a.id
+		        AND o.starts_at <= s.date_time AND o.ends_at >= s.date_time + make_interval(secs => $4)
+		  )
+		ORDER BY s.date_time
+		LIMIT 1
+	`, id, proctorID, model.ScheduleStatusActive, sessionLength.Seconds()).Scan(&plotID, &dateTime)
+	if err == nil {
+		return fmt.Errorf("cannot remove availability: the proctor is assigned to plot %d at %s", plotID, dateTime.Format(time.RFC3339))
+	}
+	if err != pgx.ErrNoRows {
+		return fmt.Errorf("failed to check assignments: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM proctor_availability WHERE id = $1 AND proctor_id = $2`, id, proctorID)
+	if err != nil {
+		return fmt.Errorf("failed to delete availability: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("availability not found")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit availability: %w", err)
+	}
+
+	return nil
+}
+
+// Assign puts a proctor on a session. The proctor must be active and available for
+// the whole session, and must not proctor another session at an overlapping time.
+func (r *ProctorRepository) Assign(ctx context.Context, scheduleID, proctorID int64, sessionLength time.Duration, assignedBy string) (*model.AssignedProctor, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	schedule, err := lockSchedule(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	proctor, err := lockProctor(ctx, tx, proctorID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case schedule.Status == model.ScheduleStatusCancelled:
+		return nil, fmt.Errorf("cannot assign proctor: session is cancelled")
+	case !time.Now().Before(schedule.DateTime):
+		return nil, fmt.Errorf("cannot assign proctor: the test has started")
+	case !proctor.Active:
+		return nil, fmt.Errorf("cannot assign proctor: %s is inactive", proctor.FullName)
+	}
+
+	var assigned, available bool
+	err = tx.QueryRow(ctx, `
+		SELECT
+		    EXISTS (
+		        SELECT 1 FROM schedule_proctors
+		        WHERE schedule_id = $1 AND proctor_id = $2 AND removed_at IS NULL
+		    ),
+		    EXISTS (
+		        SELECT 1 FROM proctor_availability
+		        WHERE proctor_id = $2 AND starts_at <= $3 AND ends_at >= $3::timestamptz + make_interval(secs => $4)
+		    )
+	`, scheduleID, proctorID, schedule.DateTime, sessionLength.Seconds()).Scan(&assigned, &available)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check proctor: %w", err)
+	}
+	if assigned {
+		return nil, fmt.Errorf("cannot assign proctor: %s is already assigned to this session", proctor.FullName)
+	}
+	if !available {
+		return nil, fmt.Errorf("cannot assign proctor: %s is not available at %s", proctor.FullName, schedule.DateTime.Format(time.RFC3339))
+	}
+
+	var (
+		otherPlotID   int64
+		otherDateTime time.Time
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT s.plot_id, s.date_time
+		FROM schedule_proctors sp
+		JOIN schedules s ON s.id = sp.schedule_id
+		WHERE sp.proctor_id = $1 AND sp.removed_at IS NULL AND s.id <> $2 AND s.status = $3
+		  AND s.date_time < $4::timestamptz + make_interval(secs => $5)
+		  AND $4::timestamptz < s.date_time + make_interval(secs => $5)
+		ORDER BY s.date_time
+		LIMIT 1
+	`, proctorID, scheduleID, model.ScheduleStatusActive, schedule.DateTime, sessionLength.Seconds()).Scan(&otherPlotID, &otherDateTime)
+	if err == nil {
+		return nil, fmt.Errorf("cannot assign proctor: %s already proctors plot %d at %s",
+			proctor.FullName, otherPlotID, otherDateTime.Format(time.RFC3339))
+	}
+	if err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("failed to check proctor conflicts: %w", err)
+	}
+
+	result := &model.AssignedProctor{
+		ProctorID:  proctor.ID,
+		FullName:   proctor.FullName,
+		Email:      proctor.Email,
+		Phone:      proctor.Phone,
+		AssignedBy: assignedBy,
+		Available:  true,
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO schedule_proctors (schedule_id, proctor_id, assigned_by)
+		VALUES ($1, $2, $3)
+		RETURNING assigned_at
+	`, scheduleID, proctorID, assignedBy).Scan(&result.AssignedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign proctor: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit proctor assignment: %w", err)
+	}
+
+	return result, nil
+}
+
+// Unassign takes a proctor off a session. The assignment is kept as removed so the
+// proctor's calendar feed can show the session cancelled.
+func (r *ProctorRepository) Unassign(ctx context.Context, scheduleID, proctorID int64, removedBy string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE schedule_proctors
+		SET removed_at = CURRENT_TIMESTAMP, removed_by = $1
+		WHERE schedule_id = $2 AND proctor_id = $3 AND removed_at IS NULL
+	`, removedBy, scheduleID, proctorID)
+	if err != nil {
+		return fmt.Errorf("failed to unassign proctor: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("assignment not found")
+	}
+	return nil
+}
+
+// ListAssigned returns the proctors assigned to each of the given schedules, checking
+// their availability and overlapping assignments as of now
+func (r *ProctorRepository) ListAssigned(ctx context.Context, scheduleIDs []int64, sessionLength time.Duration) (map[int64][]*model.AssignedProctor, error) {
+	query := `
+		SELECT sp.schedule_id, p.id, p.full_name, p.email, p.phone, sp.assigned_at, sp.assigned_by,
+		       EXISTS (
+		           SELECT 1 FROM proctor_availability a
+		           WHERE a.proctor_id = p.id AND a.starts_at <= s.date_time
+		             AND a.ends_at >= s.date_time + make_interval(secs => $2)
+		       ),
+		       ARRAY(
+		           SELECT o.plot_id
+		           FROM schedule_proctors osp
+		           JOIN schedules o ON o.id = osp.schedule_id
+		           WHERE osp.proctor_id = p.id AND osp.removed_at IS NULL AND o.id <> s.id AND o.status = $3
+		             AND o.date_time < s.date_time + make_interval(secs => $2)
+		             AND s.date_time < o.date_time + make_interval(secs => $2)
+		           ORDER BY o.date_time
+		       )
+		FROM schedule_proctors sp
+		JOIN proctors p ON p.id = sp.proctor_id
+		JOIN schedules s ON s.id = sp.schedule_id
+		WHERE sp.schedule_id = ANY($1) AND sp.removed_at IS NULL
+		ORDER BY sp.assigned_at, p.id
+	`
+
+	rows, err := r.db.Query(ctx, query, scheduleIDs, sessionLength.Seconds(), model.ScheduleStatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assigned proctors: %w", err)
+	}
+	defer rows.Close()
+
+	assigned := make(map[int64][]*model.AssignedProctor)
+	for rows.Next() {
+		var scheduleID int64
+		proctor := &model.AssignedProctor{}
+		err := rows.Scan(
+			&scheduleID,
+			&proctor.ProctorID,
+			&proctor.FullName,
+			&proctor.Email,
+			&proctor.Phone,
+			&proctor.AssignedAt,
+			&proctor.AssignedBy,
+			&proctor.Available,
+			&proctor.Conflicts,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan assigned proctor: %w", err)
+		}
+		assigned[scheduleID] = append(assigned[scheduleID], proctor)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating assigned proctors: %w", err)
+	}
+
+	return assigned, nil
+}
+
+// ListSessions returns the sessions a proctor is assigned to that have not ended,
+// including cancelled ones
+func (r *ProctorRepository) ListSessions(ctx context.Context, proctorID int64, sessionLength time.Duration) ([]*model.Schedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM schedules
+		WHERE id IN (
+			SELECT schedule_id FROM schedule_proctors WHERE proctor_id = $1 AND removed_at IS NULL
+		) AND date_time + make_interval(secs => $2) > CURRENT_TIMESTAMP
+		ORDER BY date_time
+	`
+
+	rows, err := r.db.Query(ctx, query, proctorID, sessionLength.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query proctor sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []*model.Schedule
+	for rows.Next() {
+		schedule := &model.Schedule{}
+		if err := scanSchedule(rows, schedule); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating proctor sessions: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// IsAssigned reports whether the proctor is currently assigned to the session
+func (r *ProctorRepository) IsAssigned(ctx context.Context, scheduleID, proctorID int64) (bool, error) {
+	var assigned bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM schedule_proctors
+			WHERE schedule_id = $1 AND proctor_id = $2 AND removed_at IS NULL
+		)
+	`, scheduleID, proctorID).Scan(&assigned)
+	if err != nil {
+		return false, fmt.Errorf("failed to check proctor assignment: %w", err)
+	}
+	return assigned, nil
+}
+
+// ProctorsParticipant reports whether the participant holds an active registration
+// at a session the proctor is assigned to
+func (r *ProctorRepository) ProctorsParticipant(ctx context.Context, proctorID, participantID int64) (bool, error) {
+	var proctored bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM registrations r
+			JOIN schedules s ON s.plot_id = r.test_plot_id
+			JOIN schedule_proctors sp ON sp.schedule_id = s.id AND sp.removed_at IS NULL
+			WHERE sp.proctor_id = $1 AND r.student_id = $2 AND r.status IN ($3, $4, $5)
+		)
+	`, proctorID, participantID,
+		model.RegistrationStatusPending, model.RegistrationStatusPaymentVerified, model.RegistrationStatusApproved).Scan(&proctored)
+	if err != nil {
+		return false, fmt.Errorf("failed to check proctor assignment: %w", err)
+	}
+	return proctored, nil
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+const quotaSegmentColumns = `id, schedule_id, name, kind, value, quota, available, created_at, updated_at`
+
+func scanQuotaSegment(row rowScanner, segment *model.QuotaSegment) error {
+	return row.Scan(
+		&segment.ID,
+		&segment.ScheduleID,
+		&segment.Name,
+		&segment.Kind,
+		&segment.Value,
+		&segment.Quota,
+		&segment.Available,
+		&segment.CreatedAt,
+		&segment.UpdatedAt,
+	)
+}
+
+// seatPool is a locked schedule together with its locked quota segments
+type seatPool struct {
+	schedule *model.Schedule
+	segments []*model.QuotaSegment
+}
+
+// lockSeatPool locks the quota segments of a schedule the caller has already locked
+func lockSeatPool(ctx context.Context, tx pgx.Tx, schedule *model.Schedule) (*seatPool, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT `+quotaSegmentColumns+`
+		FROM schedule_quota_segments
+		WHERE schedule_id = $1
+		ORDER BY id
+		FOR UPDATE
+	`, schedule.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock quota segments: %w", err)
+	}
+	defer rows.Close()
+
+	pool := &seatPool{schedule: schedule}
+	for rows.Next() {
+		segment := &model.QuotaSegment{}
+		if err := scanQuotaSegment(rows, segment); err != nil {
+			return nil, fmt.Errorf("failed to scan quota segment: %w", err)
+		}
+		pool.segments = append(pool.segments, segment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating quota segments: %w", err)
+	}
+
+	return pool, nil
+}
+
+// unreserved returns the free seats that do not belong to any segment
+func (p *seatPool) unreserved() int {
+	free := p.schedule.Available
+	for _, segment := range p.segments {
+		free -= segment.Available
+	}
+	return free
+}
+
+// allocate picks where a participant's seat comes from: a matching segment with a
+// free seat first, otherwise the unreserved seats (a nil segment). ok is false when
+// neither has a seat left.
+func (p *seatPool) allocate(participantType, major string) (segment *model.QuotaSegment, ok bool) {
+	if p.schedule.Available <= 0 {
+		return nil, false
+	}
+	for _, segment := range p.segments {
+		if segment.Available > 0 && segment.Matches(participantType, major) {
+			return segment, true
+		}
+	}
+	return nil, p.unreserved() > 0
+}
+
+// take reserves one seat on the schedule and, if given, on the segment
+func (p *seatPool) take(ctx context.Context, tx pgx.Tx, segment *model.QuotaSegment) error {
+	err := tx.QueryRow(ctx, `
+		UPDATE schedules SET available = available - 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1
+		RETURNING available
+	`, p.schedule.ID).Scan(&p.schedule.Available)
+	if err != nil {
+		return fmt.Errorf("failed to reserve seat: %w", err)
+	}
+
+	if segment != nil {
+		err := tx.QueryRow(ctx, `
+			UPDATE schedule_quota_segments SET available = available - 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1
+			RETURNING available
+		`, segment.ID).Scan(&segment.Available)
+		if err != nil {
+			return fmt.Errorf("failed to reserve seat in segment %q: %w", segment.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// releaseSeat gives a seat back to the schedule and, for a reserved seat, to its segment
+func releaseSeat(ctx context.Context, tx pgx.Tx, scheduleID int64, segmentID *int64) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE schedules SET available = LEAST(available + 1, quota), updated_at = CURRENT_TIMESTAMP WHERE id = $1
+	`, scheduleID)
+	if err != nil {
+		return fmt.Errorf("failed to release seat: %w", err)
+	}
+
+	if segmentID != nil {
+		_, err := tx.Exec(ctx, `
+			UPDATE schedule_quota_segments
+			SET available = LEAST(available + 1, quota), updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`, *segmentID)
+		if err != nil {
+			return fmt.Errorf("failed to release segment seat: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// lockQuotaSegment reads a segment with a row lock; it returns nil when the segment was removed
+func lockQuotaSegment(ctx context.Context, tx pgx.Tx, id int64) (*model.QuotaSegment, error) {
+	segment := &model.QuotaSegment{}
+	err := scanQuotaSegment(tx.QueryRow(ctx, `
+		SELECT `+quotaSegmentColumns+`
+		FROM schedule_quota_segments
+		WHERE id = $1
+		FOR UPDATE
+	`, id), segment)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock quota segment: %w", err)
+	}
+
+	return segment, nil
+}
+
+// AttachSegments loads the quota segments of the given schedules
+func (r *ScheduleRepository) AttachSegments(ctx context.Context, schedules ...*model.Schedule) error {
+	if len(schedules) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(schedules))
+	byID := make(map[int64]*model.Schedule, len(schedules))
+	for i, schedule := range schedules {
+		ids[i] = schedule.ID
+		byID[schedule.ID] = schedule
+		schedule.Segments = nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+quotaSegmentColumns+`
+		FROM schedule_quota_segments
+		WHERE schedule_id = ANY($1)
+		ORDER BY schedule_id, id
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to query quota segments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var segment model.QuotaSegment
+		if err := scanQuotaSegment(rows, &segment); err != nil {
+			return fmt.Errorf("failed to scan quota segment: %w", err)
+		}
+		schedule := byID[segment.ScheduleID]
+		schedule.Segments = append(schedule.Segments, segment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating quota segments: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceSegments sets the complete segment list of a schedule. Segments are matched
+// by name so taken seats stay counted; seats taken from a removed segment become
+// unreserved seats.
+func (r *ScheduleRepository) ReplaceSegments(ctx context.Context, scheduleID int64, inputs []model.QuotaSegmentInput) (*model.Schedule, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	schedule, err := lockSchedule(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, fmt.Errorf("schedule not found")
+	}
+	if schedule.Status == model.ScheduleStatusCancelled {
+		return nil, fmt.Errorf("schedule is already cancelled")
+	}
+
+	pool, err := lockSeatPool(ctx, tx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]*model.QuotaSegment, len(pool.segments))
+	for _, segment := range pool.segments {
+		existing[segment.Name] = segment
+	}
+
+	reserved, reservedTaken := 0, 0
+	seen := make(map[string]bool, len(inputs))
+	for _, input := range inputs {
+		if seen[input.Name] {
+			return nil, fmt.Errorf("invalid quota segments: duplicate segment name %q", input.Name)
+		}
+		seen[input.Name] = true
+		reserved += input.Quota
+
+		if segment, ok := existing[input.Name]; ok {
+			taken := segment.Quota - segment.Available
+			if input.Quota < taken {
+				return nil, fmt.Errorf("invalid quota segments: segment %q already has %d seats taken", input.Name, taken)
+			}
+			reservedTaken += taken
+		}
+	}
+	if reserved > schedule.Quota {
+		return nil, fmt.Errorf("invalid quota segments: segments reserve %d seats but the schedule has %d", reserved, schedule.Quota)
+	}
+	unreservedTaken := schedule.Quota - schedule.Available - reservedTaken
+	if unreservedTaken > schedule.Quota-reserved {
+		return nil, fmt.Errorf("invalid quota segments: %d seats outside the segments are taken but only %d would remain unreserved",
+			unreservedTaken, schedule.Quota-reserved)
+	}
+
+	for _, segment := range pool.segments {
+		if seen[segment.Name] {
+			continue
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM schedule_quota_segments WHERE id = $1`, segment.ID); err != nil {
+			return nil, fmt.Errorf("failed to remove segment %q: %w", segment.Name, err)
+		}
+	}
+
+	for _, input := range inputs {
+		if segment, ok := existing[input.Name]; ok {
+			_, err := tx.Exec(ctx, `
+				UPDATE schedule_quota_segments
+				SET kind = $1, value = $2, quota = $3, available = $3 - (quota - available), updated_at = CURRENT_TIMESTAMP
+				WHERE id = $4
+			`, input.Kind, input.Value, input.Quota, segment.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update segment %q: %w", input.Name, err)
+			}
+			continue
+		}
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO schedule_quota_segments (schedule_id, name, kind, value, quota, available)
+			VALUES ($1, $2, $3, $4, $5, $5)
+		`, schedule.ID, input.Name, input.Kind, input.Value, input.Quota)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create segment %q: %w", input.Name, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit quota segments: %w", err)
+	}
+
+	if err := r.AttachSegments(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
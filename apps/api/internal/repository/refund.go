@@ -0,0 +1,422 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type RefundRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRefundRepository(db *pgxpool.Pool) *RefundRepository {
+	return &RefundRepository{db: db}
+}
+
+const refundColumns = `id, payment_id, registration_id, amount::float8, full_refund, reason, status, requested_by,
+	       bank_name, account_number, account_name,
+	       COALESCE(reviewed_by, ''), reviewed_at, COALESCE(review_notes, ''),
+	       COALESCE(transfer_reference, ''), transferred_at, COALESCE(proof_url, ''),
+	       COALESCE(completed_by, ''), completed_at, created_at, updated_at`
+
+func scanRefund(row rowScanner, refund *model.Refund) error {
+	return row.Scan(
+		&refund.ID,
+		&refund.PaymentID,
+		&refund.RegistrationID,
+		&refund.Amount,
+		&refund.Full,
+		&refund.Reason,
+		&refund.Status,
+		&refund.RequestedBy,
+		&refund.BankName,
+		&refund.AccountNumber,
+		&refund.AccountName,
+		&refund.ReviewedBy,
+		&refund.ReviewedAt,
+		&refund.ReviewNotes,
+		&refund.TransferReference,
+		&refund.TransferredAt,
+		&refund.ProofURL,
+		&refund.CompletedBy,
+		&refund.CompletedAt,
+		&refund.CreatedAt,
+		&refund.UpdatedAt,
+	)
+}
+
+func (r *RefundRepository) GetByID(ctx context.Context, id int64) (*model.Refund, error) {
+	query := `
+		SELECT ` + refundColumns + `
+		FROM refunds
+		WHERE id = $1
+	`
+
+	refund := &model.Refund{}
+	err := scanRefund(r.db.QueryRow(ctx, query, id), refund)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("refund not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refund: %w", err)
+	}
+
+	return refund, nil
+}
+
+// List returns refunds, newest first, optionally only those with the given status
+func (r *RefundRepository) List(ctx context.Context, status string) ([]*model.Refund, error) {
+	return r.list(ctx, `WHERE $1 = '' OR status = $1`, status)
+}
+
+func (r *RefundRepository) ListByPayment(ctx context.Context, paymentID int64) ([]*model.Refund, error) {
+	return r.list(ctx, `WHERE payment_id = $1`, paymentID)
+}
+
+func (r *RefundRepository) list(ctx context.Context, where string, arg any) ([]*model.Refund, error) {
+	query := `
+		SELECT ` + refundColumns + `
+		FROM refunds
+		` + where + `
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query refunds: %w", err)
+	}
+	defer rows.Close()
+
+	var refunds []*model.Refund
+	for rows.Next() {
+		refund := &model.Refund{}
+		if err := scanRefund(rows, refund); err != nil {
+			return nil, fmt.Errorf("failed to scan refund: %w", err)
+		}
+		refunds = append(refunds, refund)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating refunds: %w", err)
+	}
+
+	return refunds, nil
+}
+
+// lockPayment reads a payment with a row lock held until the transaction ends
+func lockPayment(ctx context.Context, tx pgx.Tx, id int64) (*model.Payment, error) {
+	payment := &model.Payment{}
+	err := scanPayment(tx.QueryRow(ctx, `
+		SELECT `+paymentColumns+`
+		FROM payments
+		WHERE id = $1
+		FOR UPDATE
+	`, id), payment)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("payment not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock payment: %w", err)
+	}
+
+	return payment, nil
+}
+
+// lockRefund locks a refund together with its registration and payment, in the
+// registration-first order every other writer uses
+func lockRefund(ctx context.Context, tx pgx.Tx, id int64) (*model.Refund, *model.Registration, *model.Payment, error) {
+	var registrationID int64
+	err := tx.QueryRow(ctx, `SELECT registration_id FROM refunds WHERE id = $1`, id).Scan(&registrationID)
+	if err == pgx.ErrNoRows {
+		return nil, nil, nil, fmt.Errorf("refund not found")
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get refund: %w", err)
+	}
+
+	registration, err := lockRegistration(ctx, tx, registrationID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	refund := &model.Refund{}
+	err = scanRefund(tx.QueryRow(ctx, `
+		SELECT `+refundColumns+`
+		FROM refunds
+		WHERE id = $1
+		FOR UPDATE
+	`, id), refund)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to lock refund: %w", err)
+	}
+
+	payment, err := lockPayment(ctx, tx, refund.PaymentID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return refund, registration, payment, nil
+}
+
+// refundable returns how much of a payment is not yet claimed by a refund that was
+// requested, approved or completed
+func refundable(ctx context.Context, tx pgx.Tx, payment *model.Payment) (float64, error) {
+	var claimed float64
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount), 0)::float8
+		FROM refunds
+		WHERE payment_id = $1 AND status <> $2
+	`, payment.ID, model.RefundStatusRejected).Scan(&claimed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum refunds: %w", err)
+	}
+
+	return payment.Amount - claimed, nil
+}
+
+// Create requests a refund of a paid payment whose registration will not go ahead,
+// or of a payment received late. Without an amount everything still refundable is
+// requested.
+func (r *RefundRepository) Create(ctx context.Context, paymentID int64, req *model.CreateRefund, requestedBy string) (*model.Refund, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var registrationID int64
+	err = tx.QueryRow(ctx, `SELECT registration_id FROM payments WHERE id = $1`, paymentID).Scan(&registrationID)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("payment not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	registration, err := lockRegistration(ctx, tx, registrationID)
+	if err != nil {
+		return nil, err
+	}
+	payment, err := lockPayment(ctx, tx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch payment.PaymentStatus {
+	case model.PaymentStatusPaid, model.PaymentStatusVerified, model.PaymentStatusPartiallyRefunded,
+		model.PaymentStatusReceivedLate:
+	default:
+		return nil, fmt.Errorf("cannot request refund: payment is %s", payment.PaymentStatus)
+	}
+	// Money received late never held the seat, so refunding it leaves the registration alone
+	if payment.PaymentStatus != model.PaymentStatusReceivedLate && !registration.RefundRequired &&
+		registration.Status != model.RegistrationStatusCancelled &&
+		registration.Status != model.RegistrationStatusRejected {
+		return nil, fmt.Errorf("cannot request refund: registration is %s; cancel it first", registration.Status)
+	}
+
+	remaining, err := refundable(ctx, tx, payment)
+	if err != nil {
+		return nil, err
+	}
+	if remaining <= 0 {
+		return nil, fmt.Errorf("cannot request refund: payment has nothing left to refund")
+	}
+	amount := req.Amount
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount > remaining {
+		return nil, fmt.Errorf("invalid amount: at most %.2f can still be refunded", remaining)
+	}
+
+	refund := &model.Refund{}
+	err = scanRefund(tx.QueryRow(ctx, `
+		INSERT INTO refunds (
+			payment_id, registration_id, amount, full_refund, reason, status, requested_by,
+			bank_name, account_number, account_name
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		) RETURNING `+refundColumns,
+		payment.ID,
+		registration.ID,
+		amount,
+		amount == remaining,
+		req.Reason,
+		model.RefundStatusRequested,
+		requestedBy,
+		req.BankName,
+		req.AccountNumber,
+		req.AccountName,
+	), refund)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	err = insertHistory(ctx, tx, &model.CreateRegistrationHistory{
+		RegistrationID: registration.ID,
+		Status:         registration.Status,
+		Notes:          fmt.Sprintf("Refund %d of %.2f requested: %s", refund.ID, refund.Amount, refund.Reason),
+		ChangedBy:      requestedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit refund: %w", err)
+	}
+
+	return refund, nil
+}
+
+// Review approves or rejects a requested refund
+func (r *RefundRepository) Review(ctx context.Context, id int64, approve bool, notes, reviewedBy string) (*model.Refund, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	refund, registration, _, err := lockRefund(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if refund.Status != model.RefundStatusRequested {
+		return nil, fmt.Errorf("cannot review refund: refund is %s", refund.Status)
+	}
+
+	status, verb := model.RefundStatusRejected, "rejected"
+	if approve {
+		status, verb = model.RefundStatusApproved, "approved"
+	}
+	err = scanRefund(tx.QueryRow(ctx, `
+		UPDATE refunds
+		SET status = $1, reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP, review_notes = NULLIF($3, ''),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+		RETURNING `+refundColumns,
+		status, reviewedBy, notes, refund.ID,
+	), refund)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review refund: %w", err)
+	}
+
+	historyNotes := fmt.Sprintf("Refund %d of %.2f %s", refund.ID, refund.Amount, verb)
+	if notes != "" {
+		historyNotes += ": " + notes
+	}
+	err = insertHistory(ctx, tx, &model.CreateRegistrationHistory{
+		RegistrationID: registration.ID,
+		Status:         registration.Status,
+		Notes:          historyNotes,
+		ChangedBy:      reviewedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit refund review: %w", err)
+	}
+
+	return refund, nil
+}
+
+// Complete records the transfer of an approved refund. The payment becomes refunded
+// or partially refunded. Once the whole payment went back the registration no longer
+// awaits a refund, and one that is somehow still active is cancelled so the seat is
+// not kept without payment; the waitlist entry offered that seat is returned. A
+// payment received late never held the seat and leaves the registration as it is.
+func (r *RefundRepository) Complete(ctx context.Context, id int64, req *model.CompleteRefund, completedBy string, claimWindow time.Duration) (*model.Refund, *model.Payment, *model.WaitlistEntry, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	refund, registration, payment, err := lockRefund(ctx, tx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if refund.Status != model.RefundStatusApproved {
+		return nil, nil, nil, fmt.Errorf("cannot complete refund: refund is %s", refund.Status)
+	}
+	if req.TransferredAt.After(time.Now()) {
+		return nil, nil, nil, fmt.Errorf("invalid transfer date: it is in the future")
+	}
+
+	err = scanRefund(tx.QueryRow(ctx, `
+		UPDATE refunds
+		SET status = $1, transfer_reference = $2, transferred_at = $3, proof_url = $4,
+		    completed_by = $5, completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6
+		RETURNING `+refundColumns,
+		model.RefundStatusCompleted, req.TransferReference, req.TransferredAt, req.ProofURL, completedBy, refund.ID,
+	), refund)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to complete refund: %w", err)
+	}
+
+	late := payment.PaymentStatus == model.PaymentStatusReceivedLate
+	status := model.PaymentStatusPartiallyRefunded
+	if payment.RefundedAmount+refund.Amount >= payment.Amount {
+		status = model.PaymentStatusRefunded
+	}
+	err = scanPayment(tx.QueryRow(ctx, `
+		UPDATE payments
+		SET refunded_amount = refunded_amount + $1, payment_status = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING `+paymentColumns,
+		refund.Amount, status, payment.ID,
+	), payment)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	if status == model.PaymentStatusRefunded && registration.RefundRequired {
+		_, err = tx.Exec(ctx, `
+			UPDATE registrations SET refund_required = FALSE, updated_at = CURRENT_TIMESTAMP WHERE id = $1
+		`, registration.ID)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to update registration: %w", err)
+		}
+	}
+
+	var offered *model.WaitlistEntry
+	if status == model.PaymentStatusRefunded && !late &&
+		registration.Status != model.RegistrationStatusCancelled &&
+		registration.Status != model.RegistrationStatusRejected {
+		offered, err = cancelLocked(ctx, tx, registration, fmt.Sprintf("Cancelled: payment %d refunded", payment.ID), completedBy, claimWindow)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	notes := fmt.Sprintf("Refund %d of %.2f transferred (ref %s); payment %s",
+		refund.ID, refund.Amount, refund.TransferReference, payment.PaymentStatus)
+	if req.Notes != "" {
+		notes += ": " + req.Notes
+	}
+	err = insertHistory(ctx, tx, &model.CreateRegistrationHistory{
+		RegistrationID: registration.ID,
+		Status:         registration.Status,
+		Notes:          notes,
+		ChangedBy:      completedBy,
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to commit refund: %w", err)
+	}
+
+	return refund, payment, offered, nil
+}
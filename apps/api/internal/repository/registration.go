@@ -0,0 +1,757 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type RegistrationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRegistrationRepository(db *pgxpool.Pool) *RegistrationRepository {
+	return &RegistrationRepository{db: db}
+}
+
+const registrationColumns = `id, reg_number, student_id, test_plot_id, COALESCE(payment_id, 0),
+	       status, test_date, COALESCE(test_location, ''), COALESCE(notes, ''),
+	       approved_at, COALESCE(approved_by, ''), refund_required, reschedule_count,
+	       segment_id, group_registration_id, COALESCE(attendance, ''), checked_in_at,
+	       COALESCE(checked_in_by, ''), seat_number, COALESCE(test_form_code, ''), payment_due_at,
+	       created_at, updated_at`
+
+func scanRegistration(row rowScanner, registration *model.Registration) error {
+	var testDate, approvedAt *time.Time
+	err := row.Scan(
+		&registration.ID,
+		&registration.RegNumber,
+		&registration.StudentID,
+		&registration.TestPlotID,
+		&registration.PaymentID,
+		&registration.Status,
+		&testDate,
+		&registration.TestLocation,
+		&registration.Notes,
+		&approvedAt,
+		&registration.ApprovedBy,
+		&registration.RefundRequired,
+		&registration.RescheduleCount,
+		&registration.SegmentID,
+		&registration.GroupID,
+		&registration.Attendance,
+		&registration.CheckedInAt,
+		&registration.CheckedInBy,
+		&registration.SeatNumber,
+		&registration.TestFormCode,
+		&registration.PaymentDueAt,
+		&registration.CreatedAt,
+		&registration.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if testDate != nil {
+		registration.TestDate = *testDate
+	}
+	if approvedAt != nil {
+		registration.ApprovedAt = *approvedAt
+	}
+	return nil
+}
+
+func (r *RegistrationRepository) GetByID(ctx context.Context, id int64) (*model.Registration, error) {
+	query := `
+		SELECT ` + registrationColumns + `
+		FROM registrations
+		WHERE id = $1
+	`
+
+	registration := &model.Registration{}
+	err := scanRegistration(r.db.QueryRow(ctx, query, id), registration)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("registration not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registration: %w", err)
+	}
+
+	return registration, nil
+}
+
+func (r *RegistrationRepository) ListHistory(ctx context.Context, registrationID int64) ([]*model.RegistrationHistory, error) {
+	query := `
+		SELECT id, registration_id, status, COALESCE(notes, ''), changed_by, created_at
+		FROM registration_histories
+		WHERE registration_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.Query(ctx, query, registrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query registration history: %w", err)
+	}
+	defer rows.Close()
+
+	var histories []*model.RegistrationHistory
+	for rows.Next() {
+		history := &model.RegistrationHistory{}
+		err := rows.Scan(
+			&history.ID,
+			&history.RegistrationID,
+			&history.Status,
+			&history.Notes,
+			&history.ChangedBy,
+			&history.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan registration history: %w", err)
+		}
+		histories = append(histories, history)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating registration history: %w", err)
+	}
+
+	return histories, nil
+}
+
+// lockRegistration reads a registration with a row lock held until the transaction ends
+func lockRegistration(ctx context.Context, tx pgx.Tx, id int64) (*model.Registration, error) {
+	query := `
+		SELECT ` + registrationColumns + `
+		FROM registrations
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	registration := &model.Registration{}
+	err := scanRegistration(tx.QueryRow(ctx, query, id), registration)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("registration not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock registration: %w", err)
+	}
+
+	return registration, nil
+}
+
+func insertHistory(ctx context.Context, tx pgx.Tx, history *model.CreateRegistrationHistory) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO registration_histories (registration_id, status, notes, changed_by)
+		VALUES ($1, $2, $3, $4)
+	`, history.RegistrationID, history.Status, history.Notes, history.ChangedBy)
+	if err != nil {
+		return fmt.Errorf("failed to record registration history: %w", err)
+	}
+	return nil
+}
+
+// ScheduleCheck is evaluated with the schedule locked, before a seat or waitlist
+// position is taken on it.
+type ScheduleCheck func(schedule *model.Schedule) error
+
+// EligibilityCheck applies the eligibility rules to facts read inside the registration
+// transaction. It returns the violations an admin override waived, or an error when
+// the student may not register.
+type EligibilityCheck func(facts *model.EligibilityFacts) ([]model.RuleViolation, error)
+
+// RescheduleCheck is evaluated inside the reschedule transaction with the registration
+// and both schedules locked, so policy decisions see the same state that gets written.
+type RescheduleCheck func(registration *model.Registration, from, to *model.Schedule) error
+
+// Reschedule atomically releases the seat on the current schedule, takes one on the
+// target schedule and records the move in the registration history. The released
+// seat is offered to the old schedule's waitlist; the promoted entry is returned.
+func (r *RegistrationRepository) Reschedule(ctx context.Context, id, targetScheduleID int64, check RescheduleCheck, eligible EligibilityCheck, reason, changedBy string, claimWindow time.Duration) (*model.Registration, *model.WaitlistEntry, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	registration, err := lockRegistration(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fromID int64
+	err = tx.QueryRow(ctx, `SELECT id FROM schedules WHERE plot_id = $1`, registration.TestPlotID).Scan(&fromID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find current schedule: %w", err)
+	}
+	if fromID == targetScheduleID {
+		return nil, nil, fmt.Errorf("registration is already on the target schedule")
+	}
+
+	// Lock both schedules in ID order so concurrent moves cannot deadlock
+	first, second := fromID, targetScheduleID
+	if second < first {
+		first, second = second, first
+	}
+	locked := make(map[int64]*model.Schedule, 2)
+	for _, scheduleID := range []int64{first, second} {
+		schedule, err := lockSchedule(ctx, tx, scheduleID)
+		if err != nil {
+			return nil, nil, err
+		}
+		locked[scheduleID] = schedule
+	}
+
+	from, to := locked[fromID], locked[targetScheduleID]
+	if to == nil {
+		return nil, nil, fmt.Errorf("target schedule not found")
+	}
+
+	if err := check(registration, from, to); err != nil {
+		return nil, nil, err
+	}
+
+	facts, err := loadEligibilityFacts(ctx, tx, registration.StudentID, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The registration being moved is judged against the participant's other registrations
+	others := facts.Registrations[:0]
+	for _, existing := range facts.Registrations {
+		if existing.ID == registration.ID {
+			continue
+		}
+		if existing.TestPlotID == to.PlotID {
+			return nil, nil, fmt.Errorf("cannot reschedule: student is already registered for the target schedule")
+		}
+		others = append(others, existing)
+	}
+	facts.Registrations = others
+	if _, err := eligible(facts); err != nil {
+		return nil, nil, err
+	}
+
+	pool, err := lockSeatPool(ctx, tx, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	segment, ok := pool.allocate(facts.Participant.Type, facts.Participant.Major)
+	if !ok {
+		return nil, nil, fmt.Errorf("cannot reschedule: the remaining seats on the target schedule are reserved for other segments")
+	}
+
+	offered, err := offerNextOrRelease(ctx, tx, from, registration.SegmentID, claimWindow)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := pool.take(ctx, tx, segment); err != nil {
+		return nil, nil, err
+	}
+
+	var segmentID *int64
+	if segment != nil {
+		segmentID = &segment.ID
+	}
+	err = tx.QueryRow(ctx, `
+		UPDATE registrations
+		SET test_plot_id = $1, test_date = $2, test_location = $3, segment_id = $4, seat_number = NULL,
+		    test_form_code = NULL, reschedule_count = reschedule_count + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+		RETURNING test_plot_id, test_date, test_location, segment_id, reschedule_count, updated_at
+	`, to.PlotID, to.DateTime, to.Location, segmentID, registration.ID).Scan(
+		&registration.TestPlotID,
+		&registration.TestDate,
+		&registration.TestLocation,
+		&registration.SegmentID,
+		&registration.RescheduleCount,
+		&registration.UpdatedAt,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update registration: %w", err)
+	}
+	registration.SeatNumber = nil
+	registration.TestFormCode = ""
+
+	notes := fmt.Sprintf("Rescheduled from plot %d to plot %d", from.PlotID, to.PlotID)
+	if reason != "" {
+		notes += ": " + reason
+	}
+	err = insertHistory(ctx, tx, &model.CreateRegistrationHistory{
+		RegistrationID: registration.ID,
+		Status:         registration.Status,
+		Notes:          notes,
+		ChangedBy:      changedBy,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit reschedule: %w", err)
+	}
+
+	return registration, offered, nil
+}
+
+// lockScheduleByPlot reads the schedule of a test plot with a row lock
+func lockScheduleByPlot(ctx context.Context, tx pgx.Tx, plotID int64) (*model.Schedule, error) {
+	var id int64
+	err := tx.QueryRow(ctx, `SELECT id FROM schedules WHERE plot_id = $1`, plotID).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("schedule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find schedule: %w", err)
+	}
+
+	schedule, err := lockSchedule(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, fmt.Errorf("schedule not found")
+	}
+
+	return schedule, nil
+}
+
+var romanMonths = [...]string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X", "XI", "XII"}
+
+// nextRegNumber generates the next registration number of the month, e.g. 001/V/2025.
+// The advisory lock serializes numbering until the transaction ends.
+func nextRegNumber(ctx context.Context, tx pgx.Tx) (string, error) {
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('registrations.reg_number'))`); err != nil {
+		return "", fmt.Errorf("failed to lock registration numbering: %w", err)
+	}
+
+	// Month and year come from the database clock the rows are counted against, so a
+	// number issued around midnight cannot mix one month's count with another's label
+	var count, month, year int
+	err := tx.QueryRow(ctx, `
+		SELECT COUNT(*), EXTRACT(MONTH FROM CURRENT_TIMESTAMP)::int, EXTRACT(YEAR FROM CURRENT_TIMESTAMP)::int
+		FROM registrations
+		WHERE date_trunc('month', created_at) = date_trunc('month', CURRENT_TIMESTAMP)
+	`).Scan(&count, &month, &year)
+	if err != nil {
+		return "", fmt.Errorf("failed to count registrations: %w", err)
+	}
+
+	return fmt.Sprintf("%03d/%s/%d", count+1, romanMonths[month-1], year), nil
+}
+
+// insertRegistration creates a pending registration on a locked schedule. The caller
+// is responsible for the seat and sets registration.SegmentID to the segment it came from
+// (and GroupID for a group registration). An individual registration must open a payment
+// within paymentDeadline, but no later than the test.
+func insertRegistration(ctx context.Context, tx pgx.Tx, registration *model.Registration, schedule *model.Schedule, paymentDeadline time.Duration, notes, changedBy string) error {
+	regNumber, err := nextRegNumber(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	registration.RegNumber = regNumber
+	registration.TestPlotID = schedule.PlotID
+	registration.Status = model.RegistrationStatusPending
+	registration.TestDate = schedule.DateTime
+	registration.TestLocation = schedule.Location
+	if registration.GroupID == nil {
+		dueAt := time.Now().Add(paymentDeadline)
+		if schedule.DateTime.Before(dueAt) {
+			dueAt = schedule.DateTime
+		}
+		registration.PaymentDueAt = &dueAt
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO registrations (
+			reg_number, student_id, test_plot_id, status, test_date, test_location, segment_id,
+			group_registration_id, payment_due_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		) RETURNING id, created_at, updated_at
+	`,
+		registration.RegNumber,
+		registration.StudentID,
+		registration.TestPlotID,
+		registration.Status,
+		registration.TestDate,
+		registration.TestLocation,
+		registration.SegmentID,
+		registration.GroupID,
+		registration.PaymentDueAt,
+	).Scan(
+		&registration.ID,
+		&registration.CreatedAt,
+		&registration.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create registration: %w", err)
+	}
+
+	return insertHistory(ctx, tx, &model.CreateRegistrationHistory{
+		RegistrationID: registration.ID,
+		Status:         registration.Status,
+		Notes:          fmt.Sprintf("%s for plot %d", notes, schedule.PlotID),
+		ChangedBy:      changedBy,
+	})
+}
+
+// Create takes a seat on the schedule of the requested test plot and registers the student
+func (r *RegistrationRepository) Create(ctx context.Context, req *model.CreateRegistration, check ScheduleCheck, eligible EligibilityCheck, paymentDeadline time.Duration, changedBy string) (*model.Registration, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	schedule, err := lockScheduleByPlot(ctx, tx, req.TestPlotID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case schedule.Status == model.ScheduleStatusCancelled:
+		return nil, fmt.Errorf("cannot register: schedule is cancelled")
+	case schedule.DateTime.Before(time.Now()):
+		return nil, fmt.Errorf("cannot register: schedule has already started")
+	case schedule.AllocationMode == model.AllocationLottery:
+		return nil, fmt.Errorf("cannot register: seats on this schedule are allocated by lottery")
+	case schedule.Available <= 0:
+		return nil, fmt.Errorf("cannot register: schedule is full")
+	}
+	if err := check(schedule); err != nil {
+		return nil, err
+	}
+
+	facts, err := loadEligibilityFacts(ctx, tx, req.StudentID, schedule)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range facts.Registrations {
+		if existing.TestPlotID == schedule.PlotID {
+			return nil, fmt.Errorf("cannot register: student is already registered for this schedule")
+		}
+	}
+	waived, err := eligible(facts)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := lockSeatPool(ctx, tx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	segment, ok := pool.allocate(facts.Participant.Type, facts.Participant.Major)
+	if !ok {
+		return nil, fmt.Errorf("cannot register: the remaining seats are reserved for other segments")
+	}
+	if err := pool.take(ctx, tx, segment); err != nil {
+		return nil, err
+	}
+
+	registration := &model.Registration{StudentID: req.StudentID}
+	if segment != nil {
+		registration.SegmentID = &segment.ID
+	}
+	if err := insertRegistration(ctx, tx, registration, schedule, paymentDeadline, "Registered", changedBy); err != nil {
+		return nil, err
+	}
+
+	if len(waived) > 0 {
+		codes := make([]string, len(waived))
+		for i, violation := range waived {
+			codes[i] = violation.Code
+		}
+		err := insertHistory(ctx, tx, &model.CreateRegistrationHistory{
+			RegistrationID: registration.ID,
+			Status:         registration.Status,
+			Notes:          fmt.Sprintf("Eligibility override (%s): %s", strings.Join(codes, ", "), req.OverrideReason),
+			ChangedBy:      changedBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit registration: %w", err)
+	}
+
+	return registration, nil
+}
+
+// cancelLocked cancels a locked registration and its unpaid payments, frees its seat
+// and offers it to the waitlist
+func cancelLocked(ctx context.Context, tx pgx.Tx, registration *model.Registration, notes, changedBy string, claimWindow time.Duration) (*model.WaitlistEntry, error) {
+	schedule, err := lockScheduleByPlot(ctx, tx, registration.TestPlotID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cancelPendingPayments(ctx, tx, registration.ID); err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE registrations SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+		RETURNING status, updated_at
+	`, model.RegistrationStatusCancelled, registration.ID).Scan(&registration.Status, &registration.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel registration: %w", err)
+	}
+
+	err = insertHistory(ctx, tx, &model.CreateRegistrationHistory{
+		RegistrationID: registration.ID,
+		Status:         registration.Status,
+		Notes:          notes,
+		ChangedBy:      changedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return offerNextOrRelease(ctx, tx, schedule, registration.SegmentID, claimWindow)
+}
+
+// Cancel withdraws a registration. The freed seat is offered to the next student on
+// the waitlist, who is returned, or released back to the schedule.
+func (r *RegistrationRepository) Cancel(ctx context.Context, id int64, reason, changedBy string, claimWindow time.Duration) (*model.Registration, *model.WaitlistEntry, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	registration, err := lockRegistration(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if registration.Status == model.RegistrationStatusRejected || registration.Status == model.RegistrationStatusCancelled {
+		return nil, nil, fmt.Errorf("cannot cancel: registration is %s", registration.Status)
+	}
+
+	notes := "Cancelled"
+	if reason != "" {
+		notes += ": " + reason
+	}
+	offered, err := cancelLocked(ctx, tx, registration, notes, changedBy, claimWindow)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit cancellation: %w", err)
+	}
+
+	return registration, offered, nil
+}
+
+// Approve confirms a registration once it has been paid for, through its own payment
+// or its group's invoice. A payment that was paid but not yet verified is verified by
+// the same admin.
+func (r *RegistrationRepository) Approve(ctx context.Context, id int64, notes, changedBy string) (*model.Registration, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	registration, err := lockRegistration(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if registration.Status != model.RegistrationStatusPending && registration.Status != model.RegistrationStatusPaymentVerified {
+		return nil, fmt.Errorf("cannot approve registration: registration is %s", registration.Status)
+	}
+
+	switch {
+	case registration.GroupID != nil:
+		var invoiceStatus string
+		err := tx.QueryRow(ctx, `
+			SELECT status FROM group_invoices WHERE group_registration_id = $1
+		`, *registration.GroupID).Scan(&invoiceStatus)
+		if err != nil && err != pgx.ErrNoRows {
+			return nil, fmt.Errorf("failed to check group invoice: %w", err)
+		}
+		if invoiceStatus != model.GroupInvoiceStatusPaid {
+			return nil, fmt.Errorf("cannot approve registration: group invoice is not paid")
+		}
+
+	case registration.PaymentID == 0:
+		return nil, fmt.Errorf("cannot approve registration: no payment")
+
+	default:
+		payment, err := lockPayment(ctx, tx, registration.PaymentID)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case payment.PaymentStatus == model.PaymentStatusVerified:
+		case payment.PaymentStatus == model.PaymentStatusPaid,
+			payment.PaymentStatus == model.PaymentStatusReceivedLate,
+			payment.PaymentStatus == model.PaymentStatusPending && payment.Amount == 0: // Fully discounted
+			_, err := tx.Exec(ctx, `
+				UPDATE payments
+				SET payment_status = $1, verified_by = $2, verified_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+				WHERE id = $3
+			`, model.PaymentStatusVerified, changedBy, payment.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to verify payment: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("cannot approve registration: payment is %s", payment.PaymentStatus)
+		}
+	}
+
+	err = scanRegistration(tx.QueryRow(ctx, `
+		UPDATE registrations
+		SET status = $1, approved_at = CURRENT_TIMESTAMP, approved_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING `+registrationColumns,
+		model.RegistrationStatusApproved, changedBy, registration.ID,
+	), registration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve registration: %w", err)
+	}
+
+	if notes == "" {
+		notes = "Approved"
+	}
+	err = insertHistory(ctx, tx, &model.CreateRegistrationHistory{
+		RegistrationID: registration.ID,
+		Status:         registration.Status,
+		Notes:          notes,
+		ChangedBy:      changedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit approval: %w", err)
+	}
+
+	return registration, nil
+}
+
+// ExpiredRegistration pairs a registration cancelled for non-payment with the
+// waitlist entry its seat was offered to, if any
+type ExpiredRegistration struct {
+	Registration *model.Registration
+	Offered      *model.WaitlistEntry
+}
+
+// ExpireUnpaid cancels pending registrations whose payment deadline has passed: those
+// whose pending payment expired, and individual registrations that never opened a
+// payment by their payment_due_at
+func (r *RegistrationRepository) ExpireUnpaid(ctx context.Context, claimWindow time.Duration) ([]ExpiredRegistration, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT p.id, p.registration_id, p.expired_at
+		FROM payments p
+		JOIN registrations r ON r.id = p.registration_id
+		WHERE p.payment_status = $1 AND p.expired_at < CURRENT_TIMESTAMP AND r.status = $2
+		UNION ALL
+		SELECT 0, r.id, r.payment_due_at
+		FROM registrations r
+		WHERE r.status = $2 AND r.payment_due_at < CURRENT_TIMESTAMP
+		  AND NOT EXISTS (
+			SELECT 1 FROM payments p
+			WHERE p.registration_id = r.id AND p.payment_status IN ($1, $3, $4, $5)
+		  )
+		ORDER BY 3
+	`, model.PaymentStatusPending, model.RegistrationStatusPending,
+		model.PaymentStatusProcessing, model.PaymentStatusPaid, model.PaymentStatusVerified)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired payments: %w", err)
+	}
+	type overdue struct{ paymentID, registrationID int64 }
+	candidates, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (overdue, error) {
+		var o overdue
+		var dueAt time.Time
+		err := row.Scan(&o.paymentID, &o.registrationID, &dueAt)
+		return o, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan expired payments: %w", err)
+	}
+
+	var results []ExpiredRegistration
+	for _, c := range candidates {
+		result, err := r.expireUnpaid(ctx, c.paymentID, c.registrationID, claimWindow)
+		if err != nil {
+			return results, err
+		}
+		if result != nil {
+			results = append(results, *result)
+		}
+	}
+
+	return results, nil
+}
+
+// expireUnpaid expires the given pending payment and cancels its registration, or with
+// paymentID 0 cancels a registration that has no payment open past its payment_due_at
+func (r *RegistrationRepository) expireUnpaid(ctx context.Context, paymentID, registrationID int64, claimWindow time.Duration) (*ExpiredRegistration, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	registration, err := lockRegistration(ctx, tx, registrationID)
+	if err != nil {
+		return nil, err
+	}
+	if registration.Status != model.RegistrationStatusPending {
+		return nil, nil
+	}
+
+	notes := "Cancelled: payment deadline passed"
+	if paymentID == 0 {
+		// Skip registrations that opened a payment since the scan
+		var active bool
+		err := tx.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM payments
+				WHERE registration_id = $1 AND payment_status IN ($2, $3, $4, $5)
+			)
+		`, registration.ID, model.PaymentStatusPending, model.PaymentStatusProcessing, model.PaymentStatusPaid, model.PaymentStatusVerified).Scan(&active)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check payments: %w", err)
+		}
+		if active || registration.PaymentDueAt == nil || registration.PaymentDueAt.After(time.Now()) {
+			return nil, nil
+		}
+		notes = "Cancelled: no payment was made before the deadline"
+	} else {
+		// Skip payments that were paid or changed since the scan
+		result, err := tx.Exec(ctx, `
+			UPDATE payments SET payment_status = $1, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $2 AND payment_status = $3 AND expired_at < CURRENT_TIMESTAMP
+		`, model.PaymentStatusExpired, paymentID, model.PaymentStatusPending)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expire payment: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return nil, nil
+		}
+		if err := releaseVoucherUse(ctx, tx, paymentID); err != nil {
+			return nil, err
+		}
+	}
+
+	offered, err := cancelLocked(ctx, tx, registration, notes, "system", claimWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit payment expiry: %w", err)
+	}
+
+	return &ExpiredRegistration{Registration: registration, Offered: offered}, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -18,12 +19,58 @@ func NewScheduleRepository(db *pgxpool.Pool) *ScheduleRepository {
 	return &ScheduleRepository{db: db}
 }
 
+// scheduleColumns lists the columns read by scanSchedule, in order
+const scheduleColumns = `id, plot_id, date_time, location,
+	       quota, available, template_id, status, subsidized, allocation_mode, cancelled_at,
+	       COALESCE(cancellation_reason, ''), registration_opens_at,
+	       registration_closes_at, registration_closed_at, COALESCE(test_form_code, ''), created_at, updated_at`
+
+// rowScanner is satisfied by both pgx.Row and pgx.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func scanSchedule(row rowScanner, schedule *model.Schedule) error {
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.PlotID,
+		&schedule.DateTime,
+		&schedule.Location,
+		&schedule.Quota,
+		&schedule.Available,
+		&schedule.TemplateID,
+		&schedule.Status,
+		&schedule.Subsidized,
+		&schedule.AllocationMode,
+		&schedule.CancelledAt,
+		&schedule.CancellationReason,
+		&schedule.RegistrationOpensAt,
+		&schedule.RegistrationClosesAt,
+		&schedule.RegistrationClosedAt,
+		&schedule.TestFormCode,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	schedule.RegistrationStatus = schedule.RegistrationStatusAt(time.Now())
+	return nil
+}
+
 func (r *ScheduleRepository) Create(ctx context.Context, schedule *model.Schedule) error {
 	query := `
 		INSERT INTO schedules (
-			date_time, location, quota
+			date_time, location, quota, registration_opens_at, registration_closes_at, subsidized, allocation_mode
 		) VALUES (
-			$1, $2, $3
+			$1, $2, $3, $4, $5, $6, $7
 		) RETURNING id, plot_id, created_at, updated_at
 	`
 
@@ -31,6 +78,10 @@ func (r *ScheduleRepository) Create(ctx context.Context, schedule *model.Schedul
 		schedule.DateTime,
 		schedule.Location,
 		schedule.Quota,
+		schedule.RegistrationOpensAt,
+		schedule.RegistrationClosesAt,
+		schedule.Subsidized,
+		schedule.AllocationMode,
 	).Scan(
 		&schedule.ID,
 		&schedule.PlotID,
@@ -39,25 +90,74 @@ func (r *ScheduleRepository) Create(ctx context.Context, schedule *model.Schedul
 	)
 }
 
+// CreateBatch inserts all schedules in a single transaction; either every
+// schedule is created or none are.
+func (r *ScheduleRepository) CreateBatch(ctx context.Context, schedules []*model.Schedule) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO schedules (
+			date_time, location, quota, template_id
+		) VALUES (
+			$1, $2, $3, $4
+		) RETURNING id, plot_id, available, created_at, updated_at
+	`
+
+	for _, schedule := range schedules {
+		err := tx.QueryRow(ctx, query,
+			schedule.DateTime,
+			schedule.Location,
+			schedule.Quota,
+			schedule.TemplateID,
+		).Scan(
+			&schedule.ID,
+			&schedule.PlotID,
+			&schedule.Available,
+			&schedule.CreatedAt,
+			&schedule.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create schedule for %s: %w", schedule.DateTime.Format(time.RFC3339), err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit schedules: %w", err)
+	}
+
+	return nil
+}
+
+// ListDateTimesByTemplate returns the start times of schedules already generated from a template
+func (r *ScheduleRepository) ListDateTimesByTemplate(ctx context.Context, templateID int64) ([]time.Time, error) {
+	query := `SELECT date_time FROM schedules WHERE template_id = $1`
+
+	rows, err := r.db.Query(ctx, query, templateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query template schedules: %w", err)
+	}
+
+	dateTimes, err := pgx.CollectRows(rows, pgx.RowTo[time.Time])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan template schedules: %w", err)
+	}
+
+	return dateTimes, nil
+}
+
 func (r *ScheduleRepository) GetByID(ctx context.Context, id int64) (*model.Schedule, error) {
 	query := `
-		SELECT id, plot_id, date_time, location, 
-		       quota, available, created_at, updated_at
+		SELECT ` + scheduleColumns + `
 		FROM schedules
 		WHERE id = $1
 	`
 
 	schedule := &model.Schedule{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&schedule.ID,
-		&schedule.PlotID,
-		&schedule.DateTime,
-		&schedule.Location,
-		&schedule.Quota,
-		&schedule.Available,
-		&schedule.CreatedAt,
-		&schedule.UpdatedAt,
-	)
+	err := scanSchedule(r.db.QueryRow(ctx, query, id), schedule)
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("schedule not found")
 	}
@@ -72,9 +172,16 @@ func (r *ScheduleRepository) Update(ctx context.Context, schedule *model.Schedul
 	query := `
 		UPDATE schedules
 		SET date_time = $1, location = $2,
-		    quota = $3, available = $4, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $5 AND available >= 0
-		RETURNING updated_at
+		    quota = $3, available = $4,
+		    registration_opens_at = $5, registration_closes_at = $6,
+		    -- Reopening a closed window means admins get a new summary when it closes again
+		    registration_closed_at = CASE
+		        WHEN COALESCE($6, $1) > CURRENT_TIMESTAMP THEN NULL
+		        ELSE registration_closed_at
+		    END,
+		    subsidized = $8, allocation_mode = $9, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7 AND available >= 0
+		RETURNING registration_closed_at, updated_at
 	`
 
 	err := r.db.QueryRow(ctx, query,
@@ -82,8 +189,12 @@ func (r *ScheduleRepository) Update(ctx context.Context, schedule *model.Schedul
 		schedule.Location,
 		schedule.Quota,
 		schedule.Available,
+		schedule.RegistrationOpensAt,
+		schedule.RegistrationClosesAt,
 		schedule.ID,
-	).Scan(&schedule.UpdatedAt)
+		schedule.Subsidized,
+		schedule.AllocationMode,
+	).Scan(&schedule.RegistrationClosedAt, &schedule.UpdatedAt)
 
 	if err == pgx.ErrNoRows {
 		return fmt.Errorf("schedule not found or invalid quota")
@@ -95,6 +206,21 @@ func (r *ScheduleRepository) Update(ctx context.Context, schedule *model.Schedul
 	return nil
 }
 
+// HasApplicantsOrRegistrations reports whether anyone applied to or registered for the schedule
+func (r *ScheduleRepository) HasApplicantsOrRegistrations(ctx context.Context, schedule *model.Schedule) (bool, error) {
+	var taken bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM registrations WHERE test_plot_id = $1 AND status NOT IN ($2, $3))
+		    OR EXISTS (SELECT 1 FROM lottery_applications WHERE schedule_id = $4 AND status <> $5)
+	`, schedule.PlotID, model.RegistrationStatusRejected, model.RegistrationStatusCancelled,
+		schedule.ID, model.LotteryStatusWithdrawn).Scan(&taken)
+	if err != nil {
+		return false, fmt.Errorf("failed to check schedule usage: %w", err)
+	}
+
+	return taken, nil
+}
+
 func (r *ScheduleRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM schedules WHERE id = $1 AND available = quota`
 
@@ -113,7 +239,7 @@ func (r *ScheduleRepository) Delete(ctx context.Context, id int64) error {
 func (r *ScheduleRepository) List(ctx context.Context, limit, offset int, sortBy, sortOrder string) ([]*model.Schedule, int64, error) {
 	// Get total count
 	var total int64
-	countQuery := `SELECT COUNT(*) FROM schedules WHERE date_time >= CURRENT_TIMESTAMP`
+	countQuery := `SELECT COUNT(*) FROM schedules WHERE date_time >= CURRENT_TIMESTAMP AND status = 'active'`
 	err := r.db.QueryRow(ctx, countQuery).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get total count: %w", err)
@@ -140,10 +266,9 @@ func (r *ScheduleRepository) List(ctx context.Context, limit, offset int, sortBy
 
 	// Build the ORDER BY clause using a CASE statement to prevent SQL injection
 	query := `
-		SELECT id, plot_id, date_time, location,
-		       quota, available, created_at, updated_at
+		SELECT ` + scheduleColumns + `
 		FROM schedules
-		WHERE date_time >= CURRENT_TIMESTAMP
+		WHERE date_time >= CURRENT_TIMESTAMP AND status = 'active'
 		ORDER BY 
 			CASE $3
 				WHEN 'date_time' THEN date_time::text
@@ -162,17 +287,7 @@ func (r *ScheduleRepository) List(ctx context.Context, limit, offset int, sortBy
 	var schedules []*model.Schedule
 	for rows.Next() {
 		schedule := &model.Schedule{}
-		err := rows.Scan(
-			&schedule.ID,
-			&schedule.PlotID,
-			&schedule.DateTime,
-			&schedule.Location,
-			&schedule.Quota,
-			&schedule.Available,
-			&schedule.CreatedAt,
-			&schedule.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanSchedule(rows, schedule); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan schedule: %w", err)
 		}
 		schedules = append(schedules, schedule)
@@ -184,3 +299,337 @@ func (r *ScheduleRepository) List(ctx context.Context, limit, offset int, sortBy
 
 	return schedules, total, nil
 }
+
+// ListUpcoming returns every schedule that has not started yet, earliest first.
+// Cancelled schedules are included so feeds can announce the cancellation.
+func (r *ScheduleRepository) ListUpcoming(ctx context.Context) ([]*model.Schedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM schedules
+		WHERE date_time >= CURRENT_TIMESTAMP
+		ORDER BY date_time
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []*model.Schedule
+	for rows.Next() {
+		schedule := &model.Schedule{}
+		if err := scanSchedule(rows, schedule); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// lockSchedule reads a schedule with a row lock held until the transaction ends
+func lockSchedule(ctx context.Context, tx pgx.Tx, id int64) (*model.Schedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM schedules
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	schedule := &model.Schedule{}
+	err := scanSchedule(tx.QueryRow(ctx, query, id), schedule)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// Cancel marks a schedule as cancelled and, in the same transaction, either moves
+// its active registrations to the target schedule or cancels them. Every affected
+// registration gets a history entry.
+func (r *ScheduleRepository) Cancel(ctx context.Context, id int64, req *model.CancelSchedule, changedBy string) (*model.CancelScheduleResult, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock both schedules in ID order so concurrent cancellations cannot deadlock
+	ids := []int64{id}
+	if req.Action == "transfer" {
+		if req.TargetScheduleID == id {
+			return nil, fmt.Errorf("target schedule must differ from the cancelled schedule")
+		}
+		ids = append(ids, req.TargetScheduleID)
+		if req.TargetScheduleID < id {
+			ids[0], ids[1] = ids[1], ids[0]
+		}
+	}
+	locked := make(map[int64]*model.Schedule, len(ids))
+	for _, scheduleID := range ids {
+		schedule, err := lockSchedule(ctx, tx, scheduleID)
+		if err != nil {
+			return nil, err
+		}
+		locked[scheduleID] = schedule
+	}
+
+	schedule := locked[id]
+	if schedule == nil {
+		return nil, fmt.Errorf("schedule not found")
+	}
+	if schedule.Status == model.ScheduleStatusCancelled {
+		return nil, fmt.Errorf("schedule is already cancelled")
+	}
+
+	var target *model.Schedule
+	if req.Action == "transfer" {
+		target = locked[req.TargetScheduleID]
+		switch {
+		case target == nil:
+			return nil, fmt.Errorf("target schedule not found")
+		case target.Status == model.ScheduleStatusCancelled:
+			return nil, fmt.Errorf("target schedule is cancelled")
+		case target.DateTime.Before(time.Now()):
+			return nil, fmt.Errorf("target schedule has already started")
+		}
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT r.id, r.reg_number, r.student_id, r.status, p.participant_type, p.major
+		FROM registrations r
+		JOIN participants p ON p.id = r.student_id
+		WHERE r.test_plot_id = $1 AND r.status NOT IN ($2, $3)
+		ORDER BY r.id
+		FOR UPDATE OF r
+	`, schedule.PlotID, model.RegistrationStatusRejected, model.RegistrationStatusCancelled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query registrations: %w", err)
+	}
+	var affected []model.AffectedRegistration
+	var participantTypes, majors []string
+	for rows.Next() {
+		var reg model.AffectedRegistration
+		var participantType, major string
+		if err := rows.Scan(&reg.RegistrationID, &reg.RegNumber, &reg.StudentID, &reg.Status, &participantType, &major); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan registration: %w", err)
+		}
+		affected = append(affected, reg)
+		participantTypes = append(participantTypes, participantType)
+		majors = append(majors, major)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating registrations: %w", err)
+	}
+
+	if target != nil {
+		if target.Available < len(affected) {
+			return nil, fmt.Errorf("target schedule has only %d seats available for %d registrations", target.Available, len(affected))
+		}
+
+		pool, err := lockSeatPool(ctx, tx, target)
+		if err != nil {
+			return nil, err
+		}
+
+		notes := fmt.Sprintf("Transferred from plot %d to plot %d: %s", schedule.PlotID, target.PlotID, req.Reason)
+		for i, reg := range affected {
+			segment, ok := pool.allocate(participantTypes[i], majors[i])
+			if !ok {
+				return nil, fmt.Errorf("target schedule has no seat left for registration %s outside the reserved segments", reg.RegNumber)
+			}
+			if err := pool.take(ctx, tx, segment); err != nil {
+				return nil, err
+			}
+			var segmentID *int64
+			if segment != nil {
+				segmentID = &segment.ID
+			}
+
+			_, err := tx.Exec(ctx, `
+				UPDATE registrations
+				SET test_plot_id = $1, test_date = $2, test_location = $3, segment_id = $4, seat_number = NULL,
+				    test_form_code = NULL, updated_at = CURRENT_TIMESTAMP
+				WHERE id = $5
+			`, target.PlotID, target.DateTime, target.Location, segmentID, reg.RegistrationID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to transfer registration %s: %w", reg.RegNumber, err)
+			}
+			err = insertHistory(ctx, tx, &model.CreateRegistrationHistory{
+				RegistrationID: reg.RegistrationID,
+				Status:         reg.Status,
+				Notes:          notes,
+				ChangedBy:      changedBy,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		for i := range affected {
+			reg := &affected[i]
+			// Only students who already paid are owed money back
+			reg.RefundRequired = reg.Status == model.RegistrationStatusPaymentVerified || reg.Status == model.RegistrationStatusApproved
+			reg.Status = model.RegistrationStatusCancelled
+
+			_, err := tx.Exec(ctx, `
+				UPDATE registrations
+				SET status = $1, refund_required = $2, updated_at = CURRENT_TIMESTAMP
+				WHERE id = $3
+			`, reg.Status, reg.RefundRequired, reg.RegistrationID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to cancel registration %s: %w", reg.RegNumber, err)
+			}
+
+			notes := fmt.Sprintf("Schedule plot %d cancelled: %s", schedule.PlotID, req.Reason)
+			if reg.RefundRequired {
+				notes += " (refund required)"
+			}
+			err = insertHistory(ctx, tx, &model.CreateRegistrationHistory{
+				RegistrationID: reg.RegistrationID,
+				Status:         reg.Status,
+				Notes:          notes,
+				ChangedBy:      changedBy,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Nobody can be offered a seat on a cancelled session
+	_, err = tx.Exec(ctx, `
+		UPDATE waitlist_entries SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE schedule_id = $2 AND status IN ($3, $4)
+	`, model.WaitlistStatusExpired, schedule.ID, model.WaitlistStatusWaiting, model.WaitlistStatusOffered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close waitlist: %w", err)
+	}
+
+	err = scanSchedule(tx.QueryRow(ctx, `
+		UPDATE schedules
+		SET status = $1, cancelled_at = CURRENT_TIMESTAMP, cancellation_reason = $2,
+		    available = quota, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING `+scheduleColumns,
+		model.ScheduleStatusCancelled, req.Reason, schedule.ID), schedule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel schedule: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit schedule cancellation: %w", err)
+	}
+
+	if affected == nil {
+		affected = []model.AffectedRegistration{}
+	}
+
+	return &model.CancelScheduleResult{
+		Schedule:              schedule,
+		Action:                req.Action,
+		TargetSchedule:        target,
+		AffectedRegistrations: affected,
+	}, nil
+}
+
+// CloseRegistrationWindows marks every active schedule whose registration window has
+// passed as closed and returns its final counts. Each schedule is reported only once.
+func (r *ScheduleRepository) CloseRegistrationWindows(ctx context.Context) ([]*model.RegistrationSummary, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		UPDATE schedules SET registration_closed_at = CURRENT_TIMESTAMP
+		WHERE registration_closed_at IS NULL AND status = $1
+		  AND COALESCE(registration_closes_at, date_time) <= CURRENT_TIMESTAMP
+		  -- Lottery schedules are summarized once their seats have been drawn
+		  AND (allocation_mode <> $2 OR EXISTS (SELECT 1 FROM lottery_draws d WHERE d.schedule_id = schedules.id))
+		RETURNING `+scheduleColumns,
+		model.ScheduleStatusActive, model.AllocationLottery,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close registration windows: %w", err)
+	}
+
+	var summaries []*model.RegistrationSummary
+	for rows.Next() {
+		schedule := &model.Schedule{}
+		if err := scanSchedule(rows, schedule); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		summaries = append(summaries, &model.RegistrationSummary{
+			Schedule:     schedule,
+			StatusCounts: map[string]int{},
+		})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedules: %w", err)
+	}
+
+	for _, summary := range summaries {
+		if err := countRegistrations(ctx, tx, summary); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit closed registration windows: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// countRegistrations fills in the registration and waitlist counts of a schedule
+func countRegistrations(ctx context.Context, tx pgx.Tx, summary *model.RegistrationSummary) error {
+	rows, err := tx.Query(ctx, `
+		SELECT status, COUNT(*) FROM registrations
+		WHERE test_plot_id = $1
+		GROUP BY status
+	`, summary.Schedule.PlotID)
+	if err != nil {
+		return fmt.Errorf("failed to count registrations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return fmt.Errorf("failed to scan registration count: %w", err)
+		}
+		summary.StatusCounts[status] = count
+		if status != model.RegistrationStatusRejected && status != model.RegistrationStatusCancelled {
+			summary.Registered += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating registration counts: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM waitlist_entries
+		WHERE schedule_id = $1 AND status IN ($2, $3)
+	`, summary.Schedule.ID, model.WaitlistStatusWaiting, model.WaitlistStatusOffered).Scan(&summary.Waitlisted)
+	if err != nil {
+		return fmt.Errorf("failed to count waitlist entries: %w", err)
+	}
+
+	return nil
+}
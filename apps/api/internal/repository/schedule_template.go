@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type ScheduleTemplateRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewScheduleTemplateRepository(db *pgxpool.Pool) *ScheduleTemplateRepository {
+	return &ScheduleTemplateRepository{db: db}
+}
+
+const scheduleTemplateColumns = `id, name, location, quota, weekdays, times, timezone,
+	       start_date, end_date, excluded_dates, created_at, updated_at`
+
+func scanScheduleTemplate(row rowScanner, template *model.ScheduleTemplate) error {
+	return row.Scan(
+		&template.ID,
+		&template.Name,
+		&template.Location,
+		&template.Quota,
+		&template.Weekdays,
+		&template.Times,
+		&template.Timezone,
+		&template.StartDate,
+		&template.EndDate,
+		&template.ExcludedDates,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+}
+
+func (r *ScheduleTemplateRepository) Create(ctx context.Context, template *model.ScheduleTemplate) error {
+	query := `
+		INSERT INTO schedule_templates (
+			name, location, quota, weekdays, times, timezone,
+			start_date, end_date, excluded_dates
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		) RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(ctx, query,
+		template.Name,
+		template.Location,
+		template.Quota,
+		template.Weekdays,
+		template.Times,
+		template.Timezone,
+		template.StartDate,
+		template.EndDate,
+		template.ExcludedDates,
+	).Scan(
+		&template.ID,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+}
+
+func (r *ScheduleTemplateRepository) GetByID(ctx context.Context, id int64) (*model.ScheduleTemplate, error) {
+	query := `
+		SELECT ` + scheduleTemplateColumns + `
+		FROM schedule_templates
+		WHERE id = $1
+	`
+
+	template := &model.ScheduleTemplate{}
+	err := scanScheduleTemplate(r.db.QueryRow(ctx, query, id), template)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("schedule template not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule template: %w", err)
+	}
+
+	return template, nil
+}
+
+func (r *ScheduleTemplateRepository) Update(ctx context.Context, template *model.ScheduleTemplate) error {
+	query := `
+		UPDATE schedule_templates
+		SET name = $1, location = $2, quota = $3, weekdays = $4, times = $5,
+		    timezone = $6, start_date = $7, end_date = $8, excluded_dates = $9,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $10
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		template.Name,
+		template.Location,
+		template.Quota,
+		template.Weekdays,
+		template.Times,
+		template.Timezone,
+		template.StartDate,
+		template.EndDate,
+		template.ExcludedDates,
+		template.ID,
+	).Scan(&template.UpdatedAt)
+
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("schedule template not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update schedule template: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ScheduleTemplateRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM schedule_templates WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule template: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("schedule template not found")
+	}
+
+	return nil
+}
+
+func (r *ScheduleTemplateRepository) List(ctx context.Context) ([]*model.ScheduleTemplate, error) {
+	query := `
+		SELECT ` + scheduleTemplateColumns + `
+		FROM schedule_templates
+		ORDER BY start_date DESC, id DESC
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedule templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*model.ScheduleTemplate
+	for rows.Next() {
+		template := &model.ScheduleTemplate{}
+		if err := scanScheduleTemplate(rows, template); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedule templates: %w", err)
+	}
+
+	return templates, nil
+}
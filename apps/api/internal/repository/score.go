@@ -0,0 +1,722 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type ScoreRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewScoreRepository(db *pgxpool.Pool) *ScoreRepository {
+	return &ScoreRepository{db: db}
+}
+
+const scoreColumns = `id, registration_id, student_id, test_plot_id, test_form_code,
+	       listening_correct, structure_correct, reading_correct,
+	       listening_comprehension, structure_written_expression, reading_comprehension,
+	       total_score, recorded_by, released_at, COALESCE(released_by, ''), valid_until,
+	       created_at, updated_at`
+
+func scanScore(row rowScanner, score *model.Score) error {
+	err := row.Scan(
+		&score.ID,
+		&score.RegistrationID,
+		&score.StudentID,
+		&score.TestPlotID,
+		&score.TestFormCode,
+		&score.ListeningCorrect,
+		&score.StructureCorrect,
+		&score.ReadingCorrect,
+		&score.ListeningComprehension,
+		&score.StructureWrittenExpression,
+		&score.ReadingComprehension,
+		&score.TotalScore,
+		&score.RecordedBy,
+		&score.ReleasedAt,
+		&score.ReleasedBy,
+		&score.ValidUntil,
+		&score.CreatedAt,
+		&score.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	score.Status = model.ScoreStatusDraft
+	if score.ReleasedAt != nil {
+		score.Status = model.ScoreStatusReleased
+	}
+	score.Validity = model.ScoreValidityExpired
+	if score.ValidAt(time.Now()) {
+		score.Validity = model.ScoreValidityValid
+	}
+	return nil
+}
+
+// Create records the score of an attended registration, scaled with the conversion
+// table of the form the participant took. The score is valid for validityMonths
+// after the test.
+func (r *ScoreRepository) Create(ctx context.Context, score *model.Score, validityMonths int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	registration, err := lockRegistration(ctx, tx, score.RegistrationID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case registration.Status != model.RegistrationStatusApproved:
+		return fmt.Errorf("cannot record score: registration %s is %s", registration.RegNumber, registration.Status)
+	case registration.Attendance != model.AttendanceAttended:
+		return fmt.Errorf("cannot record score: registration %s was not checked in", registration.RegNumber)
+	}
+
+	formCode := registration.TestFormCode
+	if formCode == "" {
+		err := tx.QueryRow(ctx, `
+			SELECT COALESCE(test_form_code, '') FROM schedules WHERE plot_id = $1
+		`, registration.TestPlotID).Scan(&formCode)
+		if err != nil {
+			return fmt.Errorf("failed to get test form: %w", err)
+		}
+	}
+	if formCode == "" {
+		return fmt.Errorf("cannot record score: no test form was recorded for the session")
+	}
+	table, err := getConversion(ctx, tx, formCode)
+	if err != nil {
+		return err
+	}
+
+	score.StudentID = registration.StudentID
+	score.TestPlotID = registration.TestPlotID
+	score.ValidUntil = registration.TestDate.AddDate(0, validityMonths, 0)
+	table.Scale(score)
+
+	err = scanScore(tx.QueryRow(ctx, `
+		INSERT INTO scores (
+			registration_id, student_id, test_plot_id, test_form_code,
+			listening_correct, structure_correct, reading_correct,
+			listening_comprehension, structure_written_expression, reading_comprehension,
+			total_score, recorded_by, valid_until
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+		) RETURNING `+scoreColumns,
+		score.RegistrationID,
+		score.StudentID,
+		score.TestPlotID,
+		score.TestFormCode,
+		score.ListeningCorrect,
+		score.StructureCorrect,
+		score.ReadingCorrect,
+		score.ListeningComprehension,
+		score.StructureWrittenExpression,
+		score.ReadingComprehension,
+		score.TotalScore,
+		score.RecordedBy,
+		score.ValidUntil,
+	), score)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return fmt.Errorf("cannot record score: a score was already recorded for registration %s", registration.RegNumber)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record score: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit score: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ScoreRepository) GetByID(ctx context.Context, id int64) (*model.Score, error) {
+	query := `
+		SELECT ` + scoreColumns + `
+		FROM scores
+		WHERE id = $1
+	`
+
+	score := &model.Score{}
+	err := scanScore(r.db.QueryRow(ctx, query, id), score)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("score not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get score: %w", err)
+	}
+
+	return score, nil
+}
+
+// List returns the scores of one test plot or of one participant, whichever is set;
+// releasedOnly leaves out drafts
+func (r *ScoreRepository) List(ctx context.Context, testPlotID, studentID int64, releasedOnly bool) ([]*model.Score, error) {
+	query := `
+		SELECT ` + scoreColumns + `
+		FROM scores
+		WHERE ($1::bigint = 0 OR test_plot_id = $1) AND ($2::bigint = 0 OR student_id = $2)
+		  AND (NOT $3 OR released_at IS NOT NULL)
+		ORDER BY test_plot_id DESC, id
+	`
+
+	rows, err := r.db.Query(ctx, query, testPlotID, studentID, releasedOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scores: %w", err)
+	}
+	defer rows.Close()
+
+	var scores []*model.Score
+	for rows.Next() {
+		score := &model.Score{}
+		if err := scanScore(rows, score); err != nil {
+			return nil, fmt.Errorf("failed to scan score: %w", err)
+		}
+		scores = append(scores, score)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scores: %w", err)
+	}
+
+	return scores, nil
+}
+
+// lockScore reads a score for update
+func lockScore(ctx context.Context, tx pgx.Tx, id int64) (*model.Score, error) {
+	score := &model.Score{}
+	err := scanScore(tx.QueryRow(ctx, `SELECT `+scoreColumns+` FROM scores WHERE id = $1 FOR UPDATE`, id), score)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("score not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock score: %w", err)
+	}
+	return score, nil
+}
+
+// reviseScore applies corrected raw scores to a locked score, scaling them again with
+// the table of the form it was recorded with. The change is kept as a revision and a
+// released score's certificate is replaced by one with a new verification code.
+func reviseScore(ctx context.Context, tx pgx.Tx, score *model.Score, correction model.ScoreCorrection, appealID *int64, reason, changedBy string) (*model.ScoreRevision, error) {
+	table, err := getConversion(ctx, tx, score.TestFormCode)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := &model.ScoreRevision{
+		ScoreID:   score.ID,
+		AppealID:  appealID,
+		Old:       score.Values(),
+		Reason:    reason,
+		ChangedBy: changedBy,
+	}
+	score.ListeningCorrect = correction.ListeningCorrect
+	score.StructureCorrect = correction.StructureCorrect
+	score.ReadingCorrect = correction.ReadingCorrect
+	table.Scale(score)
+	revision.New = score.Values()
+	if revision.New == revision.Old {
+		return nil, fmt.Errorf("invalid score correction: the raw scores are unchanged")
+	}
+
+	err = scanScore(tx.QueryRow(ctx, `
+		UPDATE scores
+		SET listening_correct = $1, structure_correct = $2, reading_correct = $3,
+		    listening_comprehension = $4, structure_written_expression = $5, reading_comprehension = $6,
+		    total_score = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8
+		RETURNING `+scoreColumns,
+		score.ListeningCorrect,
+		score.StructureCorrect,
+		score.ReadingCorrect,
+		score.ListeningComprehension,
+		score.StructureWrittenExpression,
+		score.ReadingComprehension,
+		score.TotalScore,
+		score.ID,
+	), score)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update score: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO score_revisions (score_id, appeal_id, old_values, new_values, reason, changed_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, revision.ScoreID, revision.AppealID, revision.Old, revision.New, revision.Reason, revision.ChangedBy,
+	).Scan(&revision.ID, &revision.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record score revision: %w", err)
+	}
+
+	if score.ReleasedAt != nil {
+		_, err := tx.Exec(ctx, `
+			UPDATE score_certificates SET revoked_at = CURRENT_TIMESTAMP, revoked_reason = $2
+			WHERE score_id = $1 AND revoked_at IS NULL
+		`, score.ID, reason)
+		if err != nil {
+			return nil, fmt.Errorf("failed to revoke certificate: %w", err)
+		}
+		if _, err := issueCertificate(ctx, tx, score.ID, changedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	return revision, nil
+}
+
+// Update corrects the raw scores of a score, keeping the change as a revision
+func (r *ScoreRepository) Update(ctx context.Context, id int64, correction model.ScoreCorrection, reason, changedBy string) (*model.Score, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	score, err := lockScore(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := reviseScore(ctx, tx, score, correction, nil, reason, changedBy); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit score: %w", err)
+	}
+
+	return score, nil
+}
+
+// ListRevisions returns the changes made to a score, oldest first
+func (r *ScoreRepository) ListRevisions(ctx context.Context, scoreID int64) ([]*model.ScoreRevision, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, score_id, appeal_id, old_values, new_values, reason, changed_by, created_at
+		FROM score_revisions
+		WHERE score_id = $1
+		ORDER BY id
+	`, scoreID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query score revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []*model.ScoreRevision{}
+	for rows.Next() {
+		revision := &model.ScoreRevision{}
+		err := rows.Scan(
+			&revision.ID,
+			&revision.ScoreID,
+			&revision.AppealID,
+			&revision.Old,
+			&revision.New,
+			&revision.Reason,
+			&revision.ChangedBy,
+			&revision.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan score revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating score revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// Verification codes use upper case letters and digits without the easily confused
+// 0, O, 1 and I, printed in groups of four
+const verificationCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+func newVerificationCode() (string, error) {
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	code := make([]byte, 0, 14)
+	for i, b := range raw {
+		if i > 0 && i%4 == 0 {
+			code = append(code, '-')
+		}
+		code = append(code, verificationCodeAlphabet[int(b)%len(verificationCodeAlphabet)])
+	}
+	return string(code), nil
+}
+
+const certificateColumns = `id, score_id, verification_code, issued_by, issued_at, revoked_at, COALESCE(revoked_reason, '')`
+
+func scanCertificate(row rowScanner, certificate *model.ScoreCertificate) error {
+	return row.Scan(
+		&certificate.ID,
+		&certificate.ScoreID,
+		&certificate.VerificationCode,
+		&certificate.IssuedBy,
+		&certificate.IssuedAt,
+		&certificate.RevokedAt,
+		&certificate.RevokedReason,
+	)
+}
+
+// issueCertificate gives a released score a certificate with a new verification code
+func issueCertificate(ctx context.Context, tx pgx.Tx, scoreID int64, issuedBy string) (*model.ScoreCertificate, error) {
+	code, err := newVerificationCode()
+	if err != nil {
+		return nil, err
+	}
+
+	certificate := &model.ScoreCertificate{}
+	err = scanCertificate(tx.QueryRow(ctx, `
+		INSERT INTO score_certificates (score_id, verification_code, issued_by)
+		VALUES ($1, $2, $3)
+		RETURNING `+certificateColumns,
+		scoreID, code, issuedBy,
+	), certificate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue certificate: %w", err)
+	}
+
+	return certificate, nil
+}
+
+// GetCertificate returns the current certificate of a score
+func (r *ScoreRepository) GetCertificate(ctx context.Context, scoreID int64) (*model.ScoreCertificate, error) {
+	certificate := &model.ScoreCertificate{}
+	err := scanCertificate(r.db.QueryRow(ctx, `
+		SELECT `+certificateColumns+`
+		FROM score_certificates
+		WHERE score_id = $1 AND revoked_at IS NULL
+	`, scoreID), certificate)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("certificate not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get certificate: %w", err)
+	}
+
+	return certificate, nil
+}
+
+// VerifyCertificate looks up the score a verification code was issued for. Revoked
+// codes are still found so a replaced certificate can be recognised as such.
+func (r *ScoreRepository) VerifyCertificate(ctx context.Context, code string) (*model.CertificateVerification, error) {
+	query := `
+		SELECT c.verification_code, c.issued_at, c.revoked_at, p.full_name, sc.test_plot_id,
+		       COALESCE(s.date_time, sc.created_at), sc.listening_comprehension,
+		       sc.structure_written_expression, sc.reading_comprehension, sc.total_score, sc.valid_until
+		FROM score_certificates c
+		JOIN scores sc ON sc.id = c.score_id
+		JOIN participants p ON p.id = sc.student_id
+		LEFT JOIN schedules s ON s.plot_id = sc.test_plot_id
+		WHERE c.verification_code = $1
+	`
+
+	verification := &model.CertificateVerification{}
+	err := r.db.QueryRow(ctx, query, code).Scan(
+		&verification.VerificationCode,
+		&verification.IssuedAt,
+		&verification.RevokedAt,
+		&verification.FullName,
+		&verification.TestPlotID,
+		&verification.TestDate,
+		&verification.ListeningComprehension,
+		&verification.StructureWrittenExpression,
+		&verification.ReadingComprehension,
+		&verification.TotalScore,
+		&verification.ValidUntil,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("certificate not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify certificate: %w", err)
+	}
+
+	verification.Status = model.CertificateStatusCurrent
+	if verification.RevokedAt != nil {
+		verification.Status = model.CertificateStatusRevoked
+	}
+	verification.Validity = model.ScoreValidityExpired
+	if time.Now().Before(verification.ValidUntil) {
+		verification.Validity = model.ScoreValidityValid
+	}
+
+	return verification, nil
+}
+
+// joinedScoreRow scans the columns selected after scoreColumns into extra
+type joinedScoreRow struct {
+	rowScanner
+	extra []any
+}
+
+func (r joinedScoreRow) Scan(dest ...any) error {
+	return r.rowScanner.Scan(append(dest, r.extra...)...)
+}
+
+// ListExpiring returns the participants whose latest released score expires before
+// the given time, soonest first. Participants holding a later score are left out.
+func (r *ScoreRepository) ListExpiring(ctx context.Context, before time.Time) ([]*model.ExpiringScore, error) {
+	query := `
+		SELECT ` + scoreColumns + `, full_name, email, phone
+		FROM (
+			SELECT sc.*, p.full_name, p.email, p.phone
+			FROM scores sc
+			JOIN participants p ON p.id = sc.student_id
+			WHERE sc.released_at IS NOT NULL
+			  AND sc.valid_until > CURRENT_TIMESTAMP AND sc.valid_until <= $1
+			  AND NOT EXISTS (
+			      SELECT 1 FROM scores later
+			      WHERE later.student_id = sc.student_id AND later.released_at IS NOT NULL
+			        AND later.valid_until > sc.valid_until
+			  )
+		) expiring
+		ORDER BY valid_until, id
+	`
+
+	rows, err := r.db.Query(ctx, query, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expiring scores: %w", err)
+	}
+	defer rows.Close()
+
+	var expiring []*model.ExpiringScore
+	for rows.Next() {
+		item := &model.ExpiringScore{Score: &model.Score{}}
+		row := joinedScoreRow{rowScanner: rows, extra: []any{&item.FullName, &item.Email, &item.Phone}}
+		if err := scanScore(row, item.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan expiring score: %w", err)
+		}
+		expiring = append(expiring, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating expiring scores: %w", err)
+	}
+
+	return expiring, nil
+}
+
+// getRelease reads the release state of a schedule's scores
+func getRelease(ctx context.Context, q querier, scheduleID int64) (*model.ScoreRelease, error) {
+	query := `
+		SELECT s.id, s.plot_id, s.date_time, s.location,
+		       (SELECT COUNT(*) FROM scores sc WHERE sc.test_plot_id = s.plot_id AND sc.released_at IS NULL),
+		       (SELECT COUNT(*) FROM scores sc WHERE sc.test_plot_id = s.plot_id AND sc.released_at IS NOT NULL),
+		       sr.release_at, COALESCE(sr.scheduled_by, ''), sr.released_at, COALESCE(sr.released_by, '')
+		FROM schedules s
+		LEFT JOIN schedule_score_releases sr ON sr.schedule_id = s.id
+		WHERE s.id = $1
+	`
+
+	release := &model.ScoreRelease{}
+	err := q.QueryRow(ctx, query, scheduleID).Scan(
+		&release.ScheduleID,
+		&release.PlotID,
+		&release.DateTime,
+		&release.Location,
+		&release.Drafts,
+		&release.Released,
+		&release.ReleaseAt,
+		&release.ScheduledBy,
+		&release.ReleasedAt,
+		&release.ReleasedBy,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("schedule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get score release: %w", err)
+	}
+
+	return release, nil
+}
+
+func (r *ScoreRepository) GetRelease(ctx context.Context, scheduleID int64) (*model.ScoreRelease, error) {
+	return getRelease(ctx, r.db, scheduleID)
+}
+
+// ScheduleRelease sets when the draft scores of a schedule are released, replacing
+// any earlier scheduled time
+func (r *ScoreRepository) ScheduleRelease(ctx context.Context, scheduleID int64, releaseAt time.Time, scheduledBy string) (*model.ScoreRelease, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	schedule, err := lockSchedule(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, fmt.Errorf("schedule not found")
+	}
+	if schedule.Status == model.ScheduleStatusCancelled {
+		return nil, fmt.Errorf("cannot schedule score release: session is cancelled")
+	}
+	if releaseAt.Before(schedule.DateTime) {
+		return nil, fmt.Errorf("invalid release time: scores cannot be released before the session")
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO schedule_score_releases (schedule_id, release_at, scheduled_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (schedule_id) DO UPDATE
+		SET release_at = EXCLUDED.release_at, scheduled_by = EXCLUDED.scheduled_by, updated_at = CURRENT_TIMESTAMP
+	`, scheduleID, releaseAt, scheduledBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule score release: %w", err)
+	}
+
+	release, err := getRelease(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit score release: %w", err)
+	}
+
+	return release, nil
+}
+
+// CancelScheduledRelease drops the pending scheduled release of a schedule
+func (r *ScoreRepository) CancelScheduledRelease(ctx context.Context, scheduleID int64) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE schedule_score_releases
+		SET release_at = NULL, scheduled_by = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE schedule_id = $1 AND release_at IS NOT NULL
+	`, scheduleID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel score release: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("scheduled release not found")
+	}
+	return nil
+}
+
+// Release makes every draft score of a schedule visible and returns the scores
+// released. A scheduled release time is kept, so scores recorded after it has passed
+// are still picked up by ListDueReleases.
+func (r *ScoreRepository) Release(ctx context.Context, scheduleID int64, releasedBy string) (*model.ScoreRelease, []*model.Score, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	schedule, err := lockSchedule(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if schedule == nil {
+		return nil, nil, fmt.Errorf("schedule not found")
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE scores SET released_at = CURRENT_TIMESTAMP, released_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE test_plot_id = $1 AND released_at IS NULL
+		RETURNING `+scoreColumns,
+		schedule.PlotID, releasedBy,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to release scores: %w", err)
+	}
+
+	var scores []*model.Score
+	for rows.Next() {
+		score := &model.Score{}
+		if err := scanScore(rows, score); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("failed to scan score: %w", err)
+		}
+		scores = append(scores, score)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating scores: %w", err)
+	}
+	if len(scores) == 0 {
+		return nil, nil, fmt.Errorf("cannot release scores: there are no draft scores for the session")
+	}
+	for _, score := range scores {
+		if _, err := issueCertificate(ctx, tx, score.ID, releasedBy); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO schedule_score_releases (schedule_id, released_at, released_by)
+		VALUES ($1, CURRENT_TIMESTAMP, $2)
+		ON CONFLICT (schedule_id) DO UPDATE
+		SET released_at = EXCLUDED.released_at, released_by = EXCLUDED.released_by,
+		    updated_at = CURRENT_TIMESTAMP
+	`, scheduleID, releasedBy)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to record score release: %w", err)
+	}
+
+	release, err := getRelease(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit score release: %w", err)
+	}
+
+	return release, scores, nil
+}
+
+// ListDueReleases returns the sessions whose scheduled release has passed and that
+// have draft scores to release. Cancelled sessions are left to an admin.
+func (r *ScoreRepository) ListDueReleases(ctx context.Context) ([]int64, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT s.id
+		FROM schedule_score_releases sr
+		JOIN schedules s ON s.id = sr.schedule_id
+		WHERE sr.release_at <= CURRENT_TIMESTAMP
+		  AND s.status <> $1
+		  AND EXISTS (SELECT 1 FROM scores sc WHERE sc.test_plot_id = s.plot_id AND sc.released_at IS NULL)
+		ORDER BY sr.release_at, s.id
+	`, model.ScheduleStatusCancelled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due score releases: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedules: %w", err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type ScoreAppealRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewScoreAppealRepository(db *pgxpool.Pool) *ScoreAppealRepository {
+	return &ScoreAppealRepository{db: db}
+}
+
+const scoreAppealColumns = `id, score_id, student_id, reason, status, COALESCE(review_note, ''),
+	       COALESCE(reviewed_by, ''), reviewed_at, created_at, updated_at`
+
+func scanScoreAppeal(row rowScanner, appeal *model.ScoreAppeal) error {
+	return row.Scan(
+		&appeal.ID,
+		&appeal.ScoreID,
+		&appeal.StudentID,
+		&appeal.Reason,
+		&appeal.Status,
+		&appeal.ReviewNote,
+		&appeal.ReviewedBy,
+		&appeal.ReviewedAt,
+		&appeal.CreatedAt,
+		&appeal.UpdatedAt,
+	)
+}
+
+// Create files an appeal of a released score; a score has at most one pending appeal
+// and cannot be appealed again once an appeal of it has been rejected, until the
+// score is revised
+func (r *ScoreAppealRepository) Create(ctx context.Context, appeal *model.ScoreAppeal) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	score, err := lockScore(ctx, tx, appeal.ScoreID)
+	if err != nil {
+		return err
+	}
+	if score.ReleasedAt == nil {
+		return fmt.Errorf("score not found")
+	}
+	appeal.StudentID = score.StudentID
+
+	var rejected bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM score_appeals a
+			WHERE a.score_id = $1 AND a.status = $2
+			  AND a.reviewed_at > COALESCE(
+				(SELECT MAX(v.created_at) FROM score_revisions v WHERE v.score_id = a.score_id), '-infinity')
+		)
+	`, appeal.ScoreID, model.AppealStatusRejected).Scan(&rejected)
+	if err != nil {
+		return fmt.Errorf("failed to check earlier appeals: %w", err)
+	}
+	if rejected {
+		return fmt.Errorf("cannot appeal score: an appeal of the current score was rejected")
+	}
+
+	err = scanScoreAppeal(tx.QueryRow(ctx, `
+		INSERT INTO score_appeals (score_id, student_id, reason)
+		VALUES ($1, $2, $3)
+		RETURNING `+scoreAppealColumns,
+		appeal.ScoreID, appeal.StudentID, appeal.Reason,
+	), appeal)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return fmt.Errorf("cannot appeal score: an appeal of the score is already pending")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create score appeal: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit score appeal: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ScoreAppealRepository) GetByID(ctx context.Context, id int64) (*model.ScoreAppeal, error) {
+	appeal := &model.ScoreAppeal{}
+	err := scanScoreAppeal(r.db.QueryRow(ctx, `SELECT `+scoreAppealColumns+` FROM score_appeals WHERE id = $1`, id), appeal)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("appeal not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get score appeal: %w", err)
+	}
+
+	return appeal, nil
+}
+
+// List returns appeals with the given status, or every appeal when status is empty,
+// of one participant when studentID is set. Pending appeals come oldest first.
+func (r *ScoreAppealRepository) List(ctx context.Context, status string, studentID int64) ([]*model.ScoreAppeal, error) {
+	query := `
+		SELECT ` + scoreAppealColumns + `
+		FROM score_appeals
+		WHERE ($1 = '' OR status = $1) AND ($2::bigint = 0 OR student_id = $2)
+		ORDER BY status <> 'pending', created_at, id
+	`
+
+	rows, err := r.db.Query(ctx, query, status, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query score appeals: %w", err)
+	}
+	defer rows.Close()
+
+	appeals := []*model.ScoreAppeal{}
+	for rows.Next() {
+		appeal := &model.ScoreAppeal{}
+		if err := scanScoreAppeal(rows, appeal); err != nil {
+			return nil, fmt.Errorf("failed to scan score appeal: %w", err)
+		}
+		appeals = append(appeals, appeal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating score appeals: %w", err)
+	}
+
+	return appeals, nil
+}
+
+// Review closes a pending appeal. Accepting it re-scores the score with the
+// correction, which records a revision and re-issues the certificate; the score
+// is returned in its state after the review.
+func (r *ScoreAppealRepository) Review(ctx context.Context, id int64, req *model.ReviewScoreAppeal, reviewedBy string) (*model.ScoreAppeal, *model.Score, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	appeal := &model.ScoreAppeal{}
+	err = scanScoreAppeal(tx.QueryRow(ctx, `SELECT `+scoreAppealColumns+` FROM score_appeals WHERE id = $1 FOR UPDATE`, id), appeal)
+	if err == pgx.ErrNoRows {
+		return nil, nil, fmt.Errorf("appeal not found")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock score appeal: %w", err)
+	}
+	if appeal.Status != model.AppealStatusPending {
+		return nil, nil, fmt.Errorf("cannot review appeal: appeal is already %s", appeal.Status)
+	}
+
+	score, err := lockScore(ctx, tx, appeal.ScoreID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.Decision == model.AppealStatusAccepted {
+		if _, err := reviseScore(ctx, tx, score, *req.Correction, &appeal.ID, req.Note, reviewedBy); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err = scanScoreAppeal(tx.QueryRow(ctx, `
+		UPDATE score_appeals
+		SET status = $2, review_note = $3, reviewed_by = $4, reviewed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING `+scoreAppealColumns,
+		appeal.ID, req.Decision, req.Note, reviewedBy,
+	), appeal)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to review score appeal: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit score appeal: %w", err)
+	}
+
+	return appeal, score, nil
+}
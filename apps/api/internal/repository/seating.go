@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type SeatingRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSeatingRepository(db *pgxpool.Pool) *SeatingRepository {
+	return &SeatingRepository{db: db}
+}
+
+// SeatArrangement orders the registrations to be seated; the first one gets seat 1
+type SeatArrangement func(plan *model.SeatingPlan, seats []*model.Seat) []*model.Seat
+
+// getPlan reads a schedule's seating settings, falling back to the defaults
+func getPlan(ctx context.Context, q querier, scheduleID int64) (*model.SeatingPlan, error) {
+	plan := &model.SeatingPlan{ScheduleID: scheduleID}
+	err := q.QueryRow(ctx, `
+		SELECT s.plot_id, s.date_time, s.location,
+		       COALESCE(ss.mode, $2), COALESCE(ss.seats_per_row, $3),
+		       ss.assigned_at, COALESCE(ss.assigned_by, '')
+		FROM schedules s
+		LEFT JOIN schedule_seating ss ON ss.schedule_id = s.id
+		WHERE s.id = $1
+	`, scheduleID, model.SeatingSequential, model.DefaultSeatsPerRow).Scan(
+		&plan.PlotID,
+		&plan.DateTime,
+		&plan.Location,
+		&plan.Mode,
+		&plan.SeatsPerRow,
+		&plan.AssignedAt,
+		&plan.AssignedBy,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("schedule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seating plan: %w", err)
+	}
+
+	return plan, nil
+}
+
+// listSeats returns the active registrations of a plot by seat number, unseated
+// ones last in registration order. forUpdate locks the registrations.
+func listSeats(ctx context.Context, q querier, plan *model.SeatingPlan, forUpdate bool) ([]*model.Seat, error) {
+	query := `
+		SELECT r.id, r.reg_number, r.student_id, COALESCE(r.seat_number, 0), r.status,
+		       COALESCE(r.attendance, ''), p.full_name,
+		       COALESCE(NULLIF(p.student_number, ''), p.national_id, ''), COALESCE(p.major, '')
+		FROM registrations r
+		JOIN participants p ON p.id = r.student_id
+		WHERE r.test_plot_id = $1 AND r.status IN ($2, $3, $4)
+		ORDER BY r.seat_number NULLS LAST, r.id
+	`
+	if forUpdate {
+		query += " FOR UPDATE OF r"
+	}
+
+	rows, err := q.Query(ctx, query, plan.PlotID,
+		model.RegistrationStatusPending, model.RegistrationStatusPaymentVerified, model.RegistrationStatusApproved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seats: %w", err)
+	}
+	defer rows.Close()
+
+	var seats []*model.Seat
+	for rows.Next() {
+		seat := &model.Seat{}
+		err := rows.Scan(
+			&seat.RegistrationID,
+			&seat.RegNumber,
+			&seat.StudentID,
+			&seat.Number,
+			&seat.Status,
+			&seat.Attendance,
+			&seat.FullName,
+			&seat.Identity,
+			&seat.Major,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan seat: %w", err)
+		}
+		seat.Label = model.SeatLabel(seat.Number, plan.SeatsPerRow)
+		seats = append(seats, seat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating seats: %w", err)
+	}
+
+	return seats, nil
+}
+
+// GetPlan returns a schedule's seating plan with every active registration
+func (r *SeatingRepository) GetPlan(ctx context.Context, scheduleID int64) (*model.SeatingPlan, error) {
+	plan, err := getPlan(ctx, r.db, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	plan.Seats, err = listSeats(ctx, r.db, plan, false)
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Configure changes how a schedule is seated; existing seats are kept until they are
+// assigned again
+func (r *SeatingRepository) Configure(ctx context.Context, scheduleID int64, mode string, seatsPerRow int) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO schedule_seating (schedule_id, mode, seats_per_row)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (schedule_id) DO UPDATE
+		SET mode = EXCLUDED.mode, seats_per_row = EXCLUDED.seats_per_row, updated_at = CURRENT_TIMESTAMP
+	`, scheduleID, mode, seatsPerRow)
+	if err != nil {
+		return fmt.Errorf("failed to configure seating: %w", err)
+	}
+	return nil
+}
+
+// Assign numbers the seats of a schedule in the order arrange returns, replacing any
+// earlier assignment. Assignments of one schedule are serialized by an advisory lock
+// so the registration locks are still taken before anything else.
+func (r *SeatingRepository) Assign(ctx context.Context, scheduleID int64, arrange SeatArrangement, assignedBy string) (*model.SeatingPlan, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('schedule_seating'), $1::int)`, scheduleID); err != nil {
+		return nil, fmt.Errorf("failed to lock seating: %w", err)
+	}
+
+	plan, err := getPlan(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(plan.DateTime) {
+		return nil, fmt.Errorf("cannot assign seats: the test has started")
+	}
+
+	seats, err := listSeats(ctx, tx, plan, true)
+	if err != nil {
+		return nil, err
+	}
+	seats = arrange(plan, seats)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE registrations SET seat_number = NULL WHERE test_plot_id = $1 AND seat_number IS NOT NULL
+	`, plan.PlotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear seats: %w", err)
+	}
+	for i, seat := range seats {
+		seat.Number = i + 1
+		seat.Label = model.SeatLabel(seat.Number, plan.SeatsPerRow)
+		_, err := tx.Exec(ctx, `
+			UPDATE registrations SET seat_number = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+		`, seat.Number, seat.RegistrationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to assign seat to %s: %w", seat.RegNumber, err)
+		}
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO schedule_seating (schedule_id, mode, seats_per_row, assigned_at, assigned_by)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP, $4)
+		ON CONFLICT (schedule_id) DO UPDATE
+		SET assigned_at = EXCLUDED.assigned_at, assigned_by = EXCLUDED.assigned_by, updated_at = CURRENT_TIMESTAMP
+		RETURNING assigned_at, assigned_by
+	`, scheduleID, plan.Mode, plan.SeatsPerRow, assignedBy).Scan(&plan.AssignedAt, &plan.AssignedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record seat assignment: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit seat assignment: %w", err)
+	}
+
+	plan.Seats = seats
+	return plan, nil
+}
+
+// SeatLate gives the registrations that came in after the seats were assigned, such
+// as waitlist claims and admin registrations, the next free seats in registration
+// order. Seats already handed out are kept. It returns how many were seated.
+func (r *SeatingRepository) SeatLate(ctx context.Context, scheduleID int64) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('schedule_seating'), $1::int)`, scheduleID); err != nil {
+		return 0, fmt.Errorf("failed to lock seating: %w", err)
+	}
+
+	plan, err := getPlan(ctx, tx, scheduleID)
+	if err != nil {
+		return 0, err
+	}
+	if !time.Now().Before(plan.DateTime) {
+		return 0, fmt.Errorf("cannot assign seats: the test has started")
+	}
+
+	seats, err := listSeats(ctx, tx, plan, true)
+	if err != nil {
+		return 0, err
+	}
+
+	var last int
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(MAX(seat_number), 0) FROM registrations WHERE test_plot_id = $1
+	`, plan.PlotID).Scan(&last)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find last seat: %w", err)
+	}
+
+	var seated int
+	for _, seat := range seats {
+		if seat.Number > 0 {
+			continue
+		}
+		last++
+		_, err := tx.Exec(ctx, `
+			UPDATE registrations SET seat_number = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+		`, last, seat.RegistrationID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to assign seat to %s: %w", seat.RegNumber, err)
+		}
+		seated++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit seat assignment: %w", err)
+	}
+
+	return seated, nil
+}
+
+// ListDue returns the schedules whose registration has closed but whose seats were
+// never assigned, before their test starts
+func (r *SeatingRepository) ListDue(ctx context.Context) ([]int64, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT s.id
+		FROM schedules s
+		LEFT JOIN schedule_seating ss ON ss.schedule_id = s.id
+		WHERE s.status = $1 AND s.registration_closed_at IS NOT NULL
+		  AND s.date_time > CURRENT_TIMESTAMP AND ss.assigned_at IS NULL
+		ORDER BY s.date_time, s.id
+	`, model.ScheduleStatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules due for seating: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedules: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ListLate returns the schedules whose seats were assigned but which have since taken
+// active registrations without a seat, before their test starts
+func (r *SeatingRepository) ListLate(ctx context.Context) ([]int64, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT s.id
+		FROM schedules s
+		JOIN schedule_seating ss ON ss.schedule_id = s.id
+		WHERE s.status = $1 AND ss.assigned_at IS NOT NULL AND s.date_time > CURRENT_TIMESTAMP
+		  AND EXISTS (
+			SELECT 1 FROM registrations r
+			WHERE r.test_plot_id = s.plot_id AND r.seat_number IS NULL AND r.status IN ($2, $3, $4)
+		  )
+		ORDER BY s.date_time, s.id
+	`, model.ScheduleStatusActive,
+		model.RegistrationStatusPending, model.RegistrationStatusPaymentVerified, model.RegistrationStatusApproved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules with unseated registrations: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedules: %w", err)
+	}
+
+	return ids, nil
+}
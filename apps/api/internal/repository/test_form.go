@@ -0,0 +1,538 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type TestFormRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTestFormRepository(db *pgxpool.Pool) *TestFormRepository {
+	return &TestFormRepository{db: db}
+}
+
+const testFormColumns = `code, name, active,
+	       EXISTS (SELECT 1 FROM test_form_conversions c WHERE c.form_code = test_forms.code),
+	       created_at, updated_at`
+
+func scanTestForm(row rowScanner, form *model.TestForm) error {
+	return row.Scan(
+		&form.Code,
+		&form.Name,
+		&form.Active,
+		&form.HasTable,
+		&form.CreatedAt,
+		&form.UpdatedAt,
+	)
+}
+
+// previousForm is a subquery for the form the participant of registration r took at
+// their latest attended session before r's own
+const previousForm = `(
+	SELECT COALESCE(pr.test_form_code, ps.test_form_code)
+	FROM registrations pr
+	JOIN schedules ps ON ps.plot_id = pr.test_plot_id
+	WHERE pr.student_id = r.student_id AND pr.id <> r.id AND pr.attendance = 'attended'
+	  AND ps.date_time < (SELECT date_time FROM schedules WHERE plot_id = r.test_plot_id)
+	ORDER BY ps.date_time DESC
+	LIMIT 1
+)`
+
+func (r *TestFormRepository) Create(ctx context.Context, form *model.TestForm) error {
+	query := `
+		INSERT INTO test_forms (code, name)
+		VALUES ($1, $2)
+		ON CONFLICT (code) DO NOTHING
+		RETURNING ` + testFormColumns
+
+	err := scanTestForm(r.db.QueryRow(ctx, query, form.Code, form.Name), form)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("cannot create test form: code %q already exists", form.Code)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create test form: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TestFormRepository) GetByCode(ctx context.Context, code string) (*model.TestForm, error) {
+	query := `
+		SELECT ` + testFormColumns + `
+		FROM test_forms
+		WHERE code = $1
+	`
+
+	form := &model.TestForm{}
+	err := scanTestForm(r.db.QueryRow(ctx, query, code), form)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("test form not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test form: %w", err)
+	}
+
+	return form, nil
+}
+
+func (r *TestFormRepository) List(ctx context.Context) ([]*model.TestForm, error) {
+	query := `
+		SELECT ` + testFormColumns + `
+		FROM test_forms
+		ORDER BY code
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query test forms: %w", err)
+	}
+	defer rows.Close()
+
+	var forms []*model.TestForm
+	for rows.Next() {
+		form := &model.TestForm{}
+		if err := scanTestForm(rows, form); err != nil {
+			return nil, fmt.Errorf("failed to scan test form: %w", err)
+		}
+		forms = append(forms, form)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating test forms: %w", err)
+	}
+
+	return forms, nil
+}
+
+func (r *TestFormRepository) Update(ctx context.Context, form *model.TestForm) error {
+	query := `
+		UPDATE test_forms
+		SET name = $1, active = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE code = $3
+		RETURNING ` + testFormColumns
+
+	err := scanTestForm(r.db.QueryRow(ctx, query, form.Name, form.Active, form.Code), form)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("test form not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update test form: %w", err)
+	}
+
+	return nil
+}
+
+// getConversion reads a form's conversion table
+func getConversion(ctx context.Context, q querier, code string) (*model.ConversionTable, error) {
+	rows, err := q.Query(ctx, `
+		SELECT section, raw_score, scaled_score
+		FROM test_form_conversions
+		WHERE form_code = $1
+		ORDER BY section, raw_score
+	`, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversion table: %w", err)
+	}
+	defer rows.Close()
+
+	table := &model.ConversionTable{
+		FormCode:  code,
+		Listening: make([]int, model.ListeningQuestions+1),
+		Structure: make([]int, model.StructureQuestions+1),
+		Reading:   make([]int, model.ReadingQuestions+1),
+	}
+	sections := map[string][]int{
+		model.SectionListening: table.Listening,
+		model.SectionStructure: table.Structure,
+		model.SectionReading:   table.Reading,
+	}
+	count := 0
+	for rows.Next() {
+		var (
+			section     string
+			raw, scaled int
+		)
+		if err := rows.Scan(&section, &raw, &scaled); err != nil {
+			return nil, fmt.Errorf("failed to scan conversion: %w", err)
+		}
+		if scores := sections[section]; raw < len(scores) {
+			scores[raw] = scaled
+			count++
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating conversion table: %w", err)
+	}
+	if count != len(table.Listening)+len(table.Structure)+len(table.Reading) {
+		return nil, fmt.Errorf("conversion table not found")
+	}
+
+	return table, nil
+}
+
+// GetConversion returns the conversion table of a form
+func (r *TestFormRepository) GetConversion(ctx context.Context, code string) (*model.ConversionTable, error) {
+	return getConversion(ctx, r.db, code)
+}
+
+// SaveConversion replaces a form's conversion table. Tables of forms that already
+// scaled scores are fixed so recorded scores stay reproducible.
+func (r *TestFormRepository) SaveConversion(ctx context.Context, table *model.ConversionTable) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var used bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM scores WHERE test_form_code = code)
+		FROM test_forms
+		WHERE code = $1
+		FOR UPDATE
+	`, table.FormCode).Scan(&used)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("test form not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock test form: %w", err)
+	}
+	if used {
+		return fmt.Errorf("cannot change conversion table: scores were already scaled with form %s", table.FormCode)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM test_form_conversions WHERE form_code = $1`, table.FormCode); err != nil {
+		return fmt.Errorf("failed to clear conversion table: %w", err)
+	}
+
+	var (
+		sections []string
+		raws     []int
+		scaled   []int
+	)
+	for section, scores := range map[string][]int{
+		model.SectionListening: table.Listening,
+		model.SectionStructure: table.Structure,
+		model.SectionReading:   table.Reading,
+	} {
+		for raw, score := range scores {
+			sections = append(sections, section)
+			raws = append(raws, raw)
+			scaled = append(scaled, score)
+		}
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO test_form_conversions (form_code, section, raw_score, scaled_score)
+		SELECT $1, section, raw_score, scaled_score
+		FROM unnest($2::text[], $3::int[], $4::int[]) AS t (section, raw_score, scaled_score)
+	`, table.FormCode, sections, raws, scaled)
+	if err != nil {
+		return fmt.Errorf("failed to save conversion table: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE test_forms SET updated_at = CURRENT_TIMESTAMP WHERE code = $1`, table.FormCode)
+	if err != nil {
+		return fmt.Errorf("failed to update test form: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit conversion table: %w", err)
+	}
+
+	return nil
+}
+
+// checkUsable rejects forms that cannot be given to a session: unknown, inactive or
+// without a conversion table
+func checkUsable(ctx context.Context, tx pgx.Tx, code string) error {
+	form := &model.TestForm{}
+	err := scanTestForm(tx.QueryRow(ctx, `
+		SELECT `+testFormColumns+`
+		FROM test_forms
+		WHERE code = $1
+		FOR SHARE
+	`, code), form)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("test form not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get test form: %w", err)
+	}
+
+	switch {
+	case !form.Active:
+		return fmt.Errorf("cannot assign test form: %s is inactive", form.Code)
+	case !form.HasTable:
+		return fmt.Errorf("cannot assign test form: %s has no conversion table", form.Code)
+	}
+	return nil
+}
+
+// listSeatForms returns the effective form of every active registration of a session
+// next to the form its participant took at their previous attempt
+func listSeatForms(ctx context.Context, q querier, schedule *model.Schedule) (*model.SessionTestForms, error) {
+	rows, err := q.Query(ctx, `
+		SELECT r.id, r.reg_number, r.student_id, p.full_name,
+		       COALESCE(r.test_form_code, ''), COALESCE(`+previousForm+`, '')
+		FROM registrations r
+		JOIN participants p ON p.id = r.student_id
+		WHERE r.test_plot_id = $1 AND r.status IN ($2, $3, $4)
+		ORDER BY r.seat_number NULLS LAST, r.id
+	`, schedule.PlotID,
+		model.RegistrationStatusPending, model.RegistrationStatusPaymentVerified, model.RegistrationStatusApproved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seat test forms: %w", err)
+	}
+	defer rows.Close()
+
+	forms := &model.SessionTestForms{
+		ScheduleID: schedule.ID,
+		PlotID:     schedule.PlotID,
+		FormCode:   schedule.TestFormCode,
+		Seats:      []*model.SeatTestForm{},
+	}
+	for rows.Next() {
+		seat := &model.SeatTestForm{}
+		err := rows.Scan(
+			&seat.RegistrationID,
+			&seat.RegNumber,
+			&seat.StudentID,
+			&seat.FullName,
+			&seat.FormCode,
+			&seat.PreviousFormCode,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan seat test form: %w", err)
+		}
+		seat.Override = seat.FormCode != ""
+		if !seat.Override {
+			seat.FormCode = schedule.TestFormCode
+		}
+		seat.Conflict = seat.FormCode != "" && seat.FormCode == seat.PreviousFormCode
+		if seat.Conflict {
+			forms.Conflicts++
+		}
+		forms.Seats = append(forms.Seats, seat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating seat test forms: %w", err)
+	}
+
+	return forms, nil
+}
+
+// GetSessionForms returns the form each participant of a session gets
+func (r *TestFormRepository) GetSessionForms(ctx context.Context, scheduleID int64) (*model.SessionTestForms, error) {
+	schedule := &model.Schedule{}
+	err := scanSchedule(r.db.QueryRow(ctx, `SELECT `+scheduleColumns+` FROM schedules WHERE id = $1`, scheduleID), schedule)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("schedule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+	return listSeatForms(ctx, r.db, schedule)
+}
+
+// AssignSessionForm records the form used at a session. Participants who would get
+// the form they took at their previous attempt get their own alternate form instead;
+// without an alternate the assignment is refused.
+func (r *TestFormRepository) AssignSessionForm(ctx context.Context, scheduleID int64, formCode, alternateCode, changedBy string) (*model.SessionTestForms, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var plotID int64
+	err = tx.QueryRow(ctx, `SELECT plot_id FROM schedules WHERE id = $1`, scheduleID).Scan(&plotID)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("schedule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+
+	// Registrations are locked before the schedule, as everywhere else
+	_, err = tx.Exec(ctx, `
+		SELECT id FROM registrations WHERE test_plot_id = $1 AND status IN ($2, $3, $4) ORDER BY id FOR UPDATE
+	`, plotID, model.RegistrationStatusPending, model.RegistrationStatusPaymentVerified, model.RegistrationStatusApproved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock registrations: %w", err)
+	}
+	schedule, err := lockSchedule(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	var scored bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM scores WHERE test_plot_id = $1)`, plotID).Scan(&scored); err != nil {
+		return nil, fmt.Errorf("failed to check scores: %w", err)
+	}
+	switch {
+	case schedule.Status == model.ScheduleStatusCancelled:
+		return nil, fmt.Errorf("cannot assign test form: session is cancelled")
+	case scored:
+		return nil, fmt.Errorf("cannot assign test form: scores were already recorded for the session")
+	}
+	if err := checkUsable(ctx, tx, formCode); err != nil {
+		return nil, err
+	}
+	if alternateCode != "" {
+		if err := checkUsable(ctx, tx, alternateCode); err != nil {
+			return nil, err
+		}
+	}
+
+	schedule.TestFormCode = formCode
+	forms, err := listSeatForms(ctx, tx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	var conflicting []int64
+	for _, seat := range forms.Seats {
+		if seat.Conflict && !seat.Override {
+			conflicting = append(conflicting, seat.RegistrationID)
+		}
+	}
+	if len(conflicting) > 0 {
+		if alternateCode == "" {
+			return nil, fmt.Errorf("cannot assign test form: %d participants took %s at their previous attempt; give an alternate form",
+				len(conflicting), formCode)
+		}
+		_, err := tx.Exec(ctx, `
+			UPDATE registrations SET test_form_code = $1, updated_at = CURRENT_TIMESTAMP WHERE id = ANY($2)
+		`, alternateCode, conflicting)
+		if err != nil {
+			return nil, fmt.Errorf("failed to assign alternate test form: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO registration_histories (registration_id, status, notes, changed_by)
+			SELECT id, status, $1, $2 FROM registrations WHERE id = ANY($3)
+		`, fmt.Sprintf("Test form set to %s: took %s at the previous attempt", alternateCode, formCode), changedBy, conflicting)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record registration history: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE schedules SET test_form_code = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+	`, formCode, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign test form: %w", err)
+	}
+
+	forms, err = listSeatForms(ctx, tx, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit test form: %w", err)
+	}
+
+	return forms, nil
+}
+
+// AssignSeatForm gives one registration its own form, or clears it when formCode is
+// empty. A participant never gets the form they took at their previous attempt.
+func (r *TestFormRepository) AssignSeatForm(ctx context.Context, registrationID int64, formCode, changedBy string) (*model.Registration, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	registration, err := lockRegistration(ctx, tx, registrationID)
+	if err != nil {
+		return nil, err
+	}
+	switch registration.Status {
+	case model.RegistrationStatusPending, model.RegistrationStatusPaymentVerified, model.RegistrationStatusApproved:
+	default:
+		return nil, fmt.Errorf("cannot assign test form: registration is %s", registration.Status)
+	}
+
+	var (
+		scored   bool
+		previous string
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM scores WHERE registration_id = r.id), COALESCE(`+previousForm+`, '')
+		FROM registrations r
+		WHERE r.id = $1
+	`, registration.ID).Scan(&scored, &previous)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check previous test form: %w", err)
+	}
+	if scored {
+		return nil, fmt.Errorf("cannot assign test form: a score was already recorded for the registration")
+	}
+	if formCode != "" {
+		if err := checkUsable(ctx, tx, formCode); err != nil {
+			return nil, err
+		}
+		if formCode == previous {
+			return nil, fmt.Errorf("cannot assign test form: the participant took %s at their previous attempt", formCode)
+		}
+	}
+
+	err = scanRegistration(tx.QueryRow(ctx, `
+		UPDATE registrations
+		SET test_form_code = NULLIF($1, ''), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING `+registrationColumns,
+		formCode, registration.ID,
+	), registration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign test form: %w", err)
+	}
+
+	notes := "Test form set to " + formCode
+	if formCode == "" {
+		notes = "Test form reset to the session's form"
+	}
+	err = insertHistory(ctx, tx, &model.CreateRegistrationHistory{
+		RegistrationID: registration.ID,
+		Status:         registration.Status,
+		Notes:          notes,
+		ChangedBy:      changedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit test form: %w", err)
+	}
+
+	return registration, nil
+}
+
+// RegistrationForm returns the form a registration's participant took: the seat's own
+// form, or the session's
+func (r *TestFormRepository) RegistrationForm(ctx context.Context, registrationID int64) (string, error) {
+	var code string
+	err := r.db.QueryRow(ctx, `
+		SELECT COALESCE(r.test_form_code, s.test_form_code, '')
+		FROM registrations r
+		JOIN schedules s ON s.plot_id = r.test_plot_id
+		WHERE r.id = $1
+	`, registrationID).Scan(&code)
+	if err == pgx.ErrNoRows {
+		return "", fmt.Errorf("registration not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get test form: %w", err)
+	}
+	return code, nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type VoucherRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewVoucherRepository(db *pgxpool.Pool) *VoucherRepository {
+	return &VoucherRepository{db: db}
+}
+
+const voucherColumns = `id, code, COALESCE(description, ''), discount_type, discount_value::float8, max_uses,
+	       used_count, valid_from, valid_until, fee_tiers, active, created_at, updated_at`
+
+func scanVoucher(row rowScanner, voucher *model.Voucher) error {
+	return row.Scan(
+		&voucher.ID,
+		&voucher.Code,
+		&voucher.Description,
+		&voucher.DiscountType,
+		&voucher.DiscountValue,
+		&voucher.MaxUses,
+		&voucher.UsedCount,
+		&voucher.ValidFrom,
+		&voucher.ValidUntil,
+		&voucher.FeeTiers,
+		&voucher.Active,
+		&voucher.CreatedAt,
+		&voucher.UpdatedAt,
+	)
+}
+
+func (r *VoucherRepository) Create(ctx context.Context, voucher *model.Voucher) error {
+	query := `
+		INSERT INTO vouchers (
+			code, description, discount_type, discount_value, max_uses, valid_from, valid_until, fee_tiers
+		) VALUES (
+			$1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8
+		)
+		ON CONFLICT (code) DO NOTHING
+		RETURNING ` + voucherColumns
+
+	err := scanVoucher(r.db.QueryRow(ctx, query,
+		voucher.Code,
+		voucher.Description,
+		voucher.DiscountType,
+		voucher.DiscountValue,
+		voucher.MaxUses,
+		voucher.ValidFrom,
+		voucher.ValidUntil,
+		voucher.FeeTiers,
+	), voucher)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("cannot create voucher: code %q already exists", voucher.Code)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create voucher: %w", err)
+	}
+
+	return nil
+}
+
+func (r *VoucherRepository) GetByID(ctx context.Context, id int64) (*model.Voucher, error) {
+	query := `
+		SELECT ` + voucherColumns + `
+		FROM vouchers
+		WHERE id = $1
+	`
+
+	voucher := &model.Voucher{}
+	err := scanVoucher(r.db.QueryRow(ctx, query, id), voucher)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("voucher not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voucher: %w", err)
+	}
+
+	return voucher, nil
+}
+
+func (r *VoucherRepository) List(ctx context.Context) ([]*model.Voucher, error) {
+	query := `
+		SELECT ` + voucherColumns + `
+		FROM vouchers
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vouchers: %w", err)
+	}
+	defer rows.Close()
+
+	var vouchers []*model.Voucher
+	for rows.Next() {
+		voucher := &model.Voucher{}
+		if err := scanVoucher(rows, voucher); err != nil {
+			return nil, fmt.Errorf("failed to scan voucher: %w", err)
+		}
+		vouchers = append(vouchers, voucher)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating vouchers: %w", err)
+	}
+
+	return vouchers, nil
+}
+
+func (r *VoucherRepository) Update(ctx context.Context, voucher *model.Voucher) error {
+	query := `
+		UPDATE vouchers
+		SET description = NULLIF($1, ''), max_uses = $2, valid_from = $3, valid_until = $4,
+		    fee_tiers = $5, active = $6, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7
+		RETURNING ` + voucherColumns
+
+	err := scanVoucher(r.db.QueryRow(ctx, query,
+		voucher.Description,
+		voucher.MaxUses,
+		voucher.ValidFrom,
+		voucher.ValidUntil,
+		voucher.FeeTiers,
+		voucher.Active,
+		voucher.ID,
+	), voucher)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("voucher not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update voucher: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,477 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+)
+
+type WaitlistRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWaitlistRepository(db *pgxpool.Pool) *WaitlistRepository {
+	return &WaitlistRepository{db: db}
+}
+
+// waitlistColumns must be selected FROM waitlist_entries w JOIN schedules s
+const waitlistColumns = `w.id, w.schedule_id, s.plot_id, s.date_time, w.student_id,
+	       CASE WHEN w.status = 'waiting' THEN (
+	           SELECT COUNT(*) FROM waitlist_entries a
+	           WHERE a.schedule_id = w.schedule_id AND a.status = 'waiting' AND a.sequence <= w.sequence
+	       ) ELSE 0 END,
+	       w.status, w.offered_at, w.offer_expires_at, w.registration_id, w.segment_id,
+	       w.created_at, w.updated_at`
+
+func scanWaitlistEntry(row rowScanner, entry *model.WaitlistEntry) error {
+	return row.Scan(
+		&entry.ID,
+		&entry.ScheduleID,
+		&entry.PlotID,
+		&entry.DateTime,
+		&entry.StudentID,
+		&entry.Position,
+		&entry.Status,
+		&entry.OfferedAt,
+		&entry.OfferExpiresAt,
+		&entry.RegistrationID,
+		&entry.SegmentID,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+}
+
+func getWaitlistEntry(ctx context.Context, q querier, id int64, forUpdate bool) (*model.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistColumns + `
+		FROM waitlist_entries w
+		JOIN schedules s ON s.id = w.schedule_id
+		WHERE w.id = $1
+	`
+	if forUpdate {
+		query += ` FOR UPDATE OF w`
+	}
+
+	entry := &model.WaitlistEntry{}
+	err := scanWaitlistEntry(q.QueryRow(ctx, query, id), entry)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("waitlist entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waitlist entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// offerNextOrRelease hands a freed seat on a locked schedule to the next waiting
+// student who may take it, holding it for the claim window. A seat reserved for a
+// quota segment only goes to a student matching that segment. When nobody eligible
+// is waiting, or the schedule can no longer be attended, the seat goes back to the
+// schedule (and its segment) instead.
+func offerNextOrRelease(ctx context.Context, tx pgx.Tx, schedule *model.Schedule, segmentID *int64, claimWindow time.Duration) (*model.WaitlistEntry, error) {
+	var segment *model.QuotaSegment
+	if segmentID != nil {
+		var err error
+		if segment, err = lockQuotaSegment(ctx, tx, *segmentID); err != nil {
+			return nil, err
+		}
+		if segment == nil {
+			segmentID = nil
+		}
+	}
+
+	if schedule.Status == model.ScheduleStatusActive && schedule.DateTime.After(time.Now()) {
+		id, err := nextWaiting(ctx, tx, schedule.ID, segment)
+		if err != nil {
+			return nil, err
+		}
+		if id != 0 {
+			_, err := tx.Exec(ctx, `
+				UPDATE waitlist_entries
+				SET status = $1, offered_at = CURRENT_TIMESTAMP, offer_expires_at = $2, segment_id = $3,
+				    updated_at = CURRENT_TIMESTAMP
+				WHERE id = $4
+			`, model.WaitlistStatusOffered, time.Now().Add(claimWindow), segmentID, id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to offer seat: %w", err)
+			}
+			return getWaitlistEntry(ctx, tx, id, false)
+		}
+	}
+
+	return nil, releaseSeat(ctx, tx, schedule.ID, segmentID)
+}
+
+// nextWaiting returns the first waiting entry whose participant may take a seat of the
+// segment, or of the unreserved seats when segment is nil; 0 when there is none.
+func nextWaiting(ctx context.Context, tx pgx.Tx, scheduleID int64, segment *model.QuotaSegment) (int64, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT w.id, p.participant_type, p.major
+		FROM waitlist_entries w
+		JOIN participants p ON p.id = w.student_id
+		WHERE w.schedule_id = $1 AND w.status = $2
+		ORDER BY w.sequence
+		FOR UPDATE OF w
+	`, scheduleID, model.WaitlistStatusWaiting)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query waitlist: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var participantType, major string
+		if err := rows.Scan(&id, &participantType, &major); err != nil {
+			return 0, fmt.Errorf("failed to scan waitlist entry: %w", err)
+		}
+		if segment == nil || segment.Matches(participantType, major) {
+			return id, nil
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating waitlist: %w", err)
+	}
+
+	return 0, nil
+}
+
+// Join adds a student to the end of a full schedule's waitlist
+func (r *WaitlistRepository) Join(ctx context.Context, scheduleID, studentID int64, check ScheduleCheck) (*model.WaitlistEntry, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	schedule, err := lockSchedule(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case schedule == nil:
+		return nil, fmt.Errorf("schedule not found")
+	case schedule.Status == model.ScheduleStatusCancelled:
+		return nil, fmt.Errorf("cannot join waitlist: schedule is cancelled")
+	case schedule.DateTime.Before(time.Now()):
+		return nil, fmt.Errorf("cannot join waitlist: schedule has already started")
+	case schedule.AllocationMode == model.AllocationLottery:
+		return nil, fmt.Errorf("cannot join waitlist: the waitlist of a lottery schedule is filled by the draw")
+	}
+	if err := check(schedule); err != nil {
+		return nil, err
+	}
+
+	// Seats reserved for other segments do not count as available to this student
+	participantType, major, err := participantProfile(ctx, tx, studentID)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := lockSeatPool(ctx, tx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := pool.allocate(participantType, major); ok {
+		return nil, fmt.Errorf("cannot join waitlist: schedule still has seats available")
+	}
+
+	var exists bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM registrations
+			WHERE student_id = $1 AND test_plot_id = $2 AND status NOT IN ($3, $4)
+		)
+	`, studentID, schedule.PlotID, model.RegistrationStatusRejected, model.RegistrationStatusCancelled).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check registrations: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("cannot join waitlist: student is already registered for this schedule")
+	}
+
+	var id int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO waitlist_entries (schedule_id, student_id, sequence)
+		SELECT $1, $2, COALESCE(MAX(sequence), 0) + 1
+		FROM waitlist_entries
+		WHERE schedule_id = $1
+		ON CONFLICT (schedule_id, student_id) WHERE status IN ('waiting', 'offered') DO NOTHING
+		RETURNING id
+	`, schedule.ID, studentID).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("cannot join waitlist: student is already on the waitlist")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to join waitlist: %w", err)
+	}
+
+	entry, err := getWaitlistEntry(ctx, tx, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit waitlist entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+func (r *WaitlistRepository) GetByID(ctx context.Context, id int64) (*model.WaitlistEntry, error) {
+	return getWaitlistEntry(ctx, r.db, id, false)
+}
+
+// ListBySchedule returns the open entries of a schedule in line order
+func (r *WaitlistRepository) ListBySchedule(ctx context.Context, scheduleID int64) ([]*model.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistColumns + `
+		FROM waitlist_entries w
+		JOIN schedules s ON s.id = w.schedule_id
+		WHERE w.schedule_id = $1 AND w.status IN ('waiting', 'offered')
+		ORDER BY w.sequence
+	`
+
+	return r.list(ctx, query, scheduleID)
+}
+
+// ListByStudent returns every entry of a student, newest first
+func (r *WaitlistRepository) ListByStudent(ctx context.Context, studentID int64) ([]*model.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistColumns + `
+		FROM waitlist_entries w
+		JOIN schedules s ON s.id = w.schedule_id
+		WHERE w.student_id = $1
+		ORDER BY w.created_at DESC
+	`
+
+	return r.list(ctx, query, studentID)
+}
+
+func (r *WaitlistRepository) list(ctx context.Context, query string, args ...any) ([]*model.WaitlistEntry, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query waitlist: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*model.WaitlistEntry
+	for rows.Next() {
+		entry := &model.WaitlistEntry{}
+		if err := scanWaitlistEntry(rows, entry); err != nil {
+			return nil, fmt.Errorf("failed to scan waitlist entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating waitlist: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Leave withdraws a student from the waitlist. Leaving while holding an offer passes
+// the seat on to the next student, who is returned.
+func (r *WaitlistRepository) Leave(ctx context.Context, id int64, claimWindow time.Duration) (*model.WaitlistEntry, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	entry, err := getWaitlistEntry(ctx, r.db, id, false)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := lockSchedule(ctx, tx, entry.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if entry, err = getWaitlistEntry(ctx, tx, id, true); err != nil {
+		return nil, err
+	}
+	if entry.Status != model.WaitlistStatusWaiting && entry.Status != model.WaitlistStatusOffered {
+		return nil, fmt.Errorf("cannot leave waitlist: entry is %s", entry.Status)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE waitlist_entries SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+	`, model.WaitlistStatusLeft, entry.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to leave waitlist: %w", err)
+	}
+
+	var next *model.WaitlistEntry
+	if entry.Status == model.WaitlistStatusOffered {
+		if next, err = offerNextOrRelease(ctx, tx, schedule, entry.SegmentID, claimWindow); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit waitlist change: %w", err)
+	}
+
+	return next, nil
+}
+
+// Claim turns a held seat into a registration. A student who no longer passes the
+// eligibility rules loses the offer: the entry is marked ineligible and the seat is
+// offered to the next student, who is returned along with the eligibility error.
+func (r *WaitlistRepository) Claim(ctx context.Context, id int64, eligible EligibilityCheck, paymentDeadline, claimWindow time.Duration, changedBy string) (*model.Registration, *model.WaitlistEntry, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	entry, err := getWaitlistEntry(ctx, r.db, id, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	schedule, err := lockSchedule(ctx, tx, entry.ScheduleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if entry, err = getWaitlistEntry(ctx, tx, id, true); err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case entry.Status != model.WaitlistStatusOffered:
+		return nil, nil, fmt.Errorf("cannot claim seat: entry is %s", entry.Status)
+	case entry.OfferExpiresAt != nil && entry.OfferExpiresAt.Before(time.Now()):
+		return nil, nil, fmt.Errorf("cannot claim seat: offer has expired")
+	case schedule.Status == model.ScheduleStatusCancelled:
+		return nil, nil, fmt.Errorf("cannot claim seat: schedule is cancelled")
+	case !schedule.DateTime.After(time.Now()):
+		return nil, nil, fmt.Errorf("cannot claim seat: the session has already started")
+	}
+
+	// The participant may have registered elsewhere or taken a test since joining the waitlist
+	facts, err := loadEligibilityFacts(ctx, tx, entry.StudentID, schedule)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The check only fails on rule violations, so the held seat moves on
+	if _, ineligible := eligible(facts); ineligible != nil {
+		_, err := tx.Exec(ctx, `
+			UPDATE waitlist_entries SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+		`, model.WaitlistStatusIneligible, entry.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update waitlist entry: %w", err)
+		}
+		next, err := offerNextOrRelease(ctx, tx, schedule, entry.SegmentID, claimWindow)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, nil, fmt.Errorf("failed to commit waitlist change: %w", err)
+		}
+		return nil, next, ineligible
+	}
+
+	// The seat was held for this student when the offer was made, so available is not touched
+	registration := &model.Registration{StudentID: entry.StudentID, SegmentID: entry.SegmentID}
+	if err := insertRegistration(ctx, tx, registration, schedule, paymentDeadline, "Registered from waitlist", changedBy); err != nil {
+		return nil, nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE waitlist_entries SET status = $1, registration_id = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3
+	`, model.WaitlistStatusClaimed, registration.ID, entry.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to claim seat: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit claim: %w", err)
+	}
+
+	return registration, nil, nil
+}
+
+// ExpiredOffer pairs a lapsed offer with the entry the seat moved on to, if any
+type ExpiredOffer struct {
+	Expired *model.WaitlistEntry
+	Next    *model.WaitlistEntry
+}
+
+// ExpireOffers closes offers whose claim window has passed and moves each held seat
+// to the next student in line.
+func (r *WaitlistRepository) ExpireOffers(ctx context.Context, claimWindow time.Duration) ([]ExpiredOffer, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id FROM waitlist_entries
+		WHERE status = $1 AND offer_expires_at < CURRENT_TIMESTAMP
+		ORDER BY offer_expires_at
+	`, model.WaitlistStatusOffered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired offers: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan expired offers: %w", err)
+	}
+
+	var results []ExpiredOffer
+	for _, id := range ids {
+		result, err := r.expireOffer(ctx, id, claimWindow)
+		if err != nil {
+			return results, err
+		}
+		if result != nil {
+			results = append(results, *result)
+		}
+	}
+
+	return results, nil
+}
+
+func (r *WaitlistRepository) expireOffer(ctx context.Context, id int64, claimWindow time.Duration) (*ExpiredOffer, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	entry, err := getWaitlistEntry(ctx, r.db, id, false)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := lockSchedule(ctx, tx, entry.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if entry, err = getWaitlistEntry(ctx, tx, id, true); err != nil {
+		return nil, err
+	}
+	// Claimed or withdrawn since the scan
+	if entry.Status != model.WaitlistStatusOffered || entry.OfferExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE waitlist_entries SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+	`, model.WaitlistStatusExpired, entry.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire offer: %w", err)
+	}
+	entry.Status = model.WaitlistStatusExpired
+
+	next, err := offerNextOrRelease(ctx, tx, schedule, entry.SegmentID, claimWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit offer expiry: %w", err)
+	}
+
+	return &ExpiredOffer{Expired: entry, Next: next}, nil
+}
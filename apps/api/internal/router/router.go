@@ -3,15 +3,18 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/jatifjr/app-unw-toefl/apps/api/internal/handler"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/middleware"
 )
 
 type Router struct {
-	handlers *handler.Handler
+	handlers  *handler.Handler
+	jwtSecret string
 }
 
-func NewRouter(handlers *handler.Handler) *Router {
+func NewRouter(handlers *handler.Handler, jwtSecret string) *Router {
 	return &Router{
-		handlers: handlers,
+		handlers:  handlers,
+		jwtSecret: jwtSecret,
 	}
 }
 
@@ -19,10 +22,31 @@ func (r *Router) Setup() *gin.Engine {
 	router := gin.Default()
 
 	// API v1 group
-	v1 := router.Group("/api")
+	v1 := router.Group("/api", middleware.Authenticate(r.jwtSecret))
 	{
 		// Register all route handlers
 		r.handlers.Schedule.RegisterRoutes(v1)
+		r.handlers.ScheduleTemplate.RegisterRoutes(v1)
+		r.handlers.Calendar.RegisterRoutes(v1)
+		r.handlers.Feed.RegisterRoutes(v1)
+		r.handlers.Registration.RegisterRoutes(v1)
+		r.handlers.Waitlist.RegisterRoutes(v1)
+		r.handlers.Notification.RegisterRoutes(v1)
+		r.handlers.Eligibility.RegisterRoutes(v1)
+		r.handlers.Lottery.RegisterRoutes(v1)
+		r.handlers.Participant.RegisterRoutes(v1)
+		r.handlers.GroupRegistration.RegisterRoutes(v1)
+		r.handlers.Payment.RegisterRoutes(v1)
+		r.handlers.Voucher.RegisterRoutes(v1)
+		r.handlers.Refund.RegisterRoutes(v1)
+		r.handlers.BankStatement.RegisterRoutes(v1)
+		r.handlers.Admission.RegisterRoutes(v1)
+		r.handlers.Seating.RegisterRoutes(v1)
+		r.handlers.Proctor.RegisterRoutes(v1)
+		r.handlers.TestForm.RegisterRoutes(v1)
+		r.handlers.Score.RegisterRoutes(v1)
+		r.handlers.ScoreAppeal.RegisterRoutes(v1)
+		r.handlers.Coordinator.RegisterRoutes(v1)
 		// Add other route handlers here as needed
 	}
 
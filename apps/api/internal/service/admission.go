@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/pdf"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/qr"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/token"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+// admissionCodePrefix versions the payload of admission card QR codes
+const admissionCodePrefix = "UNWTOEFL1"
+
+type AdmissionService struct {
+	attendance    *repository.AttendanceRepository
+	registrations *repository.RegistrationRepository
+	participants  *repository.ParticipantRepository
+	proctors      *repository.ProctorRepository
+	policy        RegistrationPolicy
+	secret        string // Signs admission codes
+}
+
+func NewAdmissionService(
+	attendance *repository.AttendanceRepository,
+	registrations *repository.RegistrationRepository,
+	participants *repository.ParticipantRepository,
+	proctors *repository.ProctorRepository,
+	policy RegistrationPolicy,
+	secret string,
+) *AdmissionService {
+	return &AdmissionService{
+		attendance:    attendance,
+		registrations: registrations,
+		participants:  participants,
+		proctors:      proctors,
+		policy:        policy,
+		secret:        secret,
+	}
+}
+
+// admissionCode is the signed value printed in the card's QR code. It names the
+// registration and test plot so a card cannot be used for another session.
+func (s *AdmissionService) admissionCode(registration *model.Registration) string {
+	return token.SignValue(fmt.Sprintf("%s.%d.%d", admissionCodePrefix, registration.ID, registration.TestPlotID), s.secret)
+}
+
+// parseAdmissionCode verifies the signature of a scanned code and returns the
+// registration and test plot it names
+func (s *AdmissionService) parseAdmissionCode(code string) (registrationID, plotID int64, err error) {
+	value, err := token.VerifyValue(strings.TrimSpace(code), s.secret)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid admission code")
+	}
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 || parts[0] != admissionCodePrefix {
+		return 0, 0, fmt.Errorf("invalid admission code")
+	}
+	registrationID, err = strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid admission code")
+	}
+	plotID, err = strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid admission code")
+	}
+	return registrationID, plotID, nil
+}
+
+// AdmissionCard renders the card a student shows at the test venue. It is only
+// issued for approved registrations of participants who uploaded a photo.
+func (s *AdmissionService) AdmissionCard(ctx context.Context, registrationID int64, user *model.AuthUser) (*pdf.Document, string, error) {
+	registration, err := s.registrations.GetByID(ctx, registrationID)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := authorize(registration, user); err != nil {
+		return nil, "", err
+	}
+	if registration.Status != model.RegistrationStatusApproved {
+		return nil, "", fmt.Errorf("cannot issue admission card: registration is %s", registration.Status)
+	}
+
+	participant, err := s.participants.GetByID(ctx, registration.StudentID)
+	if err != nil {
+		return nil, "", err
+	}
+	photo, err := s.participants.GetPhoto(ctx, participant.ID)
+	if err != nil {
+		if err.Error() == "photo not found" {
+			return nil, "", fmt.Errorf("cannot issue admission card: no photo uploaded")
+		}
+		return nil, "", err
+	}
+	image, err := pdf.NewImage(photo.Data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read photo: %w", err)
+	}
+
+	code := s.admissionCode(registration)
+	symbol, err := qr.Encode([]byte(code))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode admission code: %w", err)
+	}
+
+	doc := renderAdmissionCard(registration, participant, image, symbol, code)
+	return doc, documentFilename("admission-card", registration.RegNumber), nil
+}
+
+// CheckIn records the arrival of the participant whose admission card was scanned at
+// the session being proctored. The result carries the participant's details for the
+// identity check and the session's updated attendance count.
+func (s *AdmissionService) CheckIn(ctx context.Context, scheduleID int64, req *model.CheckIn, user *model.AuthUser) (*model.CheckInResult, error) {
+	if err := authorizeSession(ctx, s.proctors, scheduleID, user); err != nil {
+		return nil, err
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+	registrationID, plotID, err := s.parseAdmissionCode(req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	registration, err := s.attendance.CheckIn(ctx, scheduleID, registrationID, plotID, s.policy.CheckInOpens, s.policy.CheckInCutoff, user.Identifier())
+	if err != nil {
+		return nil, err
+	}
+	participant, err := s.participants.GetByID(ctx, registration.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := s.attendance.Summary(ctx, scheduleID, s.policy.CheckInCutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CheckInResult{Registration: registration, Participant: participant, Attendance: summary}, nil
+}
+
+// Attendance returns the live attendance count of a session
+func (s *AdmissionService) Attendance(ctx context.Context, scheduleID int64, user *model.AuthUser) (*model.AttendanceSummary, error) {
+	if err := authorizeSession(ctx, s.proctors, scheduleID, user); err != nil {
+		return nil, err
+	}
+	return s.attendance.Summary(ctx, scheduleID, s.policy.CheckInCutoff)
+}
+
+// MarkNoShows is run periodically to record participants who were not checked in
+// by the cut-off of their session
+func (s *AdmissionService) MarkNoShows(ctx context.Context) error {
+	marked, err := s.attendance.MarkNoShows(ctx, s.policy.CheckInCutoff)
+	if len(marked) > 0 {
+		log.Printf("marked %d registrations as no-shows", len(marked))
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/bankstatement"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+type BankStatementService struct {
+	repo *repository.BankStatementRepository
+}
+
+func NewBankStatementService(repo *repository.BankStatementRepository) *BankStatementService {
+	return &BankStatementService{repo: repo}
+}
+
+// ImportStatement reads a CSV or MT940 export and matches its new credits to pending
+// payments. An empty format is detected from the file. Lines that cannot be matched
+// confidently are left for manual review.
+func (s *BankStatementService) ImportStatement(ctx context.Context, filename, format string, content []byte, user *model.AuthUser) (*model.BankStatement, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = bankstatement.DetectFormat(filename, content)
+	}
+
+	parsed, err := bankstatement.Parse(format, bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	statement, err := s.repo.Import(ctx, parsed, filename, user.Identifier())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range statement.Lines {
+		// A payment settled meanwhile only leaves its line unmatched
+		if err := s.repo.AutoMatch(ctx, line); err != nil {
+			log.Printf("failed to match statement line %d: %v", line.ID, err)
+		}
+	}
+	if err := s.repo.CountMatched(ctx, statement.ID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, statement.ID)
+}
+
+func (s *BankStatementService) ListStatements(ctx context.Context) ([]*model.BankStatement, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *BankStatementService) GetStatement(ctx context.Context, id int64) (*model.BankStatement, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListLines returns statement lines by status, unmatched ones by default
+func (s *BankStatementService) ListLines(ctx context.Context, status string) ([]*model.BankStatementLine, error) {
+	switch status {
+	case "":
+		status = model.StatementLineUnmatched
+	case model.StatementLineUnmatched, model.StatementLineMatched, model.StatementLineIgnored, model.StatementLineLate:
+	default:
+		return nil, fmt.Errorf("invalid status %q", status)
+	}
+	return s.repo.ListLines(ctx, status)
+}
+
+func (s *BankStatementService) ListUnmatchedPayments(ctx context.Context) ([]*model.UnmatchedPayment, error) {
+	return s.repo.ListUnmatchedPayments(ctx)
+}
+
+// MatchLine pairs a statement line with a payment an admin picked
+func (s *BankStatementService) MatchLine(ctx context.Context, lineID int64, req *model.MatchStatementLine, user *model.AuthUser) (*model.BankStatementLine, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Match(ctx, lineID, req.PaymentID, req.Notes, user.Identifier()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetLine(ctx, lineID)
+}
+
+func (s *BankStatementService) IgnoreLine(ctx context.Context, lineID int64, req *model.IgnoreStatementLine, user *model.AuthUser) (*model.BankStatementLine, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+	return s.repo.Ignore(ctx, lineID, req.Notes, user.Identifier())
+}
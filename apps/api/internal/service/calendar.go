@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/ical"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+type CalendarService struct {
+	repo *repository.CalendarRepository
+}
+
+func NewCalendarService(repo *repository.CalendarRepository) *CalendarService {
+	return &CalendarService{repo: repo}
+}
+
+func (s *CalendarService) CreateEvent(ctx context.Context, req *model.CreateCalendarEvent) (*model.CalendarEvent, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	if req.StartDate.IsZero() {
+		return nil, fmt.Errorf("invalid calendar event: start_date is required")
+	}
+	if req.EndDate.IsZero() {
+		req.EndDate = req.StartDate
+	}
+	if req.EndDate.Before(req.StartDate.Time) {
+		return nil, fmt.Errorf("invalid calendar event: end_date must not be before start_date")
+	}
+
+	event := &model.CalendarEvent{
+		Name:      req.Name,
+		Type:      req.Type,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Source:    "manual",
+	}
+
+	if err := s.repo.Create(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func (s *CalendarService) DeleteEvent(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *CalendarService) ListEvents(ctx context.Context, from, to model.Date) ([]*model.CalendarEvent, error) {
+	if to.Before(from.Time) {
+		return nil, fmt.Errorf("invalid calendar event: to must not be before from")
+	}
+	return s.repo.ListBetween(ctx, from, to)
+}
+
+// ImportICS loads every VEVENT from an iCalendar file as a blocked date range of the given type
+func (s *CalendarService) ImportICS(ctx context.Context, r io.Reader, eventType model.CalendarEventType) (*model.ImportCalendarResult, error) {
+	if eventType != model.CalendarEventNationalHoliday && eventType != model.CalendarEventCampusBlackout {
+		return nil, fmt.Errorf("invalid calendar event: unknown type %q", eventType)
+	}
+
+	parsed, err := ical.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calendar file: %w", err)
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("invalid calendar file: no events found")
+	}
+
+	events := make([]*model.CalendarEvent, 0, len(parsed))
+	for _, e := range parsed {
+		// DTEND is exclusive; a one-day holiday ends at the next midnight
+		end := e.End
+		if end.After(e.Start) {
+			end = end.Add(-1)
+		}
+
+		uid := e.UID
+		if uid == "" {
+			sum := sha1.Sum([]byte(e.Summary + e.Start.String()))
+			uid = hex.EncodeToString(sum[:])
+		}
+
+		events = append(events, &model.CalendarEvent{
+			Name:        e.Summary,
+			Type:        eventType,
+			StartDate:   model.NewDate(e.Start),
+			EndDate:     model.NewDate(end),
+			Source:      "ics",
+			ExternalUID: uid,
+		})
+	}
+
+	if err := s.repo.Import(ctx, events); err != nil {
+		return nil, err
+	}
+
+	return &model.ImportCalendarResult{Imported: len(events), Events: events}, nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+type CoordinatorService struct {
+	repo *repository.CoordinatorRepository
+}
+
+func NewCoordinatorService(repo *repository.CoordinatorRepository) *CoordinatorService {
+	return &CoordinatorService{repo: repo}
+}
+
+// authorizeCoordinator lets admins manage any coordinator and coordinators only see themselves
+func authorizeCoordinator(coordinatorID int64, user *model.AuthUser) error {
+	if user.HasRole(model.RoleAdmin, model.RoleSuperAdmin) {
+		return nil
+	}
+	if user.HasRole(model.RoleCoordinator) && user.ID == coordinatorID {
+		return nil
+	}
+	return fmt.Errorf("coordinator not found")
+}
+
+// FacultyOf returns the faculty a coordinator acts for, or nil for admins, who act for
+// every faculty. Coordinators without an active account act for none.
+func (s *CoordinatorService) FacultyOf(ctx context.Context, user *model.AuthUser) (*model.Faculty, error) {
+	if user.HasRole(model.RoleAdmin, model.RoleSuperAdmin) {
+		return nil, nil
+	}
+	if !user.HasRole(model.RoleCoordinator) {
+		return nil, fmt.Errorf("cannot act for a faculty: not a coordinator")
+	}
+
+	coordinator, err := s.repo.GetByID(ctx, user.ID)
+	if err != nil && err.Error() != "coordinator not found" {
+		return nil, err
+	}
+	if coordinator == nil || !coordinator.Active {
+		return nil, fmt.Errorf("cannot act for a faculty: coordinator account is not active")
+	}
+
+	return s.repo.GetFaculty(ctx, coordinator.FacultyCode)
+}
+
+func (s *CoordinatorService) CreateFaculty(ctx context.Context, req *model.CreateFaculty) (*model.Faculty, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	faculty := &model.Faculty{
+		Code:   strings.TrimSpace(req.Code),
+		Name:   strings.TrimSpace(req.Name),
+		Majors: req.Majors,
+	}
+	if err := s.repo.CreateFaculty(ctx, faculty); err != nil {
+		return nil, err
+	}
+
+	return faculty, nil
+}
+
+func (s *CoordinatorService) ListFaculties(ctx context.Context) ([]*model.Faculty, error) {
+	return s.repo.ListFaculties(ctx)
+}
+
+// UpdateFaculty changes a faculty's name and majors. Groups already registered are not
+// affected; the majors only decide who coordinators can register from now on.
+func (s *CoordinatorService) UpdateFaculty(ctx context.Context, code string, req *model.UpdateFaculty) (*model.Faculty, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	faculty := &model.Faculty{
+		Code:   code,
+		Name:   strings.TrimSpace(req.Name),
+		Majors: req.Majors,
+	}
+	if err := s.repo.UpdateFaculty(ctx, faculty); err != nil {
+		return nil, err
+	}
+
+	return faculty, nil
+}
+
+func (s *CoordinatorService) CreateCoordinator(ctx context.Context, req *model.CreateCoordinator) (*model.Coordinator, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	coordinator := &model.Coordinator{
+		FullName:    strings.TrimSpace(req.FullName),
+		Email:       strings.TrimSpace(req.Email),
+		FacultyCode: req.FacultyCode,
+	}
+	if err := s.repo.Create(ctx, coordinator); err != nil {
+		return nil, err
+	}
+
+	return coordinator, nil
+}
+
+func (s *CoordinatorService) ListCoordinators(ctx context.Context, facultyCode string) ([]*model.Coordinator, error) {
+	return s.repo.List(ctx, facultyCode)
+}
+
+func (s *CoordinatorService) GetCoordinator(ctx context.Context, id int64, user *model.AuthUser) (*model.Coordinator, error) {
+	if err := authorizeCoordinator(id, user); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// UpdateCoordinator changes a coordinator's details. Moving a coordinator to another
+// faculty also moves which groups they can see.
+func (s *CoordinatorService) UpdateCoordinator(ctx context.Context, id int64, req *model.UpdateCoordinator) (*model.Coordinator, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	coordinator := &model.Coordinator{
+		ID:          id,
+		FullName:    strings.TrimSpace(req.FullName),
+		Email:       strings.TrimSpace(req.Email),
+		FacultyCode: req.FacultyCode,
+		Active:      req.Active,
+	}
+	if err := s.repo.Update(ctx, coordinator); err != nil {
+		return nil, err
+	}
+
+	return coordinator, nil
+}
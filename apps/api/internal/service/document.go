@@ -0,0 +1,456 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/pdf"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/qr"
+)
+
+// Layout of the A4 documents handed to students
+const (
+	docMargin = 56.0
+	docRight  = pdf.PageWidth - docMargin
+	docWidth  = docRight - docMargin
+
+	documentDateLayout = "2 January 2006"
+)
+
+// letterhead draws the language center's header and the document title, and returns
+// the baseline below it
+func letterhead(page *pdf.Page, title, number string) float64 {
+	page.Text(docMargin, 70, pdf.HelveticaBold, 15, "UNW Language Center")
+	page.Text(docMargin, 86, pdf.Helvetica, 9, "Universitas Ngudi Waluyo - TOEFL ITP Test Administration")
+	page.TextRight(docRight, 70, pdf.HelveticaBold, 18, title)
+	if number != "" {
+		page.TextRight(docRight, 86, pdf.Helvetica, 10, "No. "+number)
+	}
+	page.Line(docMargin, 98, docRight, 98, 1)
+	return 126
+}
+
+// field draws a label and its value on one row and returns the next baseline
+func field(page *pdf.Page, y float64, label, value string) float64 {
+	page.Text(docMargin, y, pdf.Helvetica, 10, label)
+	return page.Paragraph(docMargin+150, y, docWidth-150, pdf.HelveticaBold, 10, 15, value)
+}
+
+// section draws a heading and returns the baseline for its first row
+func section(page *pdf.Page, y float64, heading string) float64 {
+	page.Text(docMargin, y, pdf.HelveticaBold, 11, heading)
+	page.Line(docMargin, y+5, docRight, y+5, 0.5)
+	return y + 22
+}
+
+// footer notes when and by what the document was generated
+func footer(page *pdf.Page, note string) {
+	page.Line(docMargin, pdf.PageHeight-70, docRight, pdf.PageHeight-70, 0.5)
+	page.Paragraph(docMargin, pdf.PageHeight-56, docWidth, pdf.Helvetica, 8, 11,
+		fmt.Sprintf("%s Generated on %s.", note, time.Now().Format(notificationTimeLayout)))
+}
+
+func participantIdentity(participant *model.Participant) (string, string) {
+	if participant.StudentNumber != "" {
+		return "Student number", participant.StudentNumber
+	}
+	return "National ID", participant.NationalID
+}
+
+// paymentParties draws who pays for which test
+func paymentParties(page *pdf.Page, y float64, heading string, registration *model.Registration, participant *model.Participant) float64 {
+	y = section(page, y, heading)
+	y = field(page, y, "Name", participant.FullName)
+	label, identity := participantIdentity(participant)
+	y = field(page, y, label, identity)
+	if participant.Institution != "" {
+		y = field(page, y, "Institution", participant.Institution)
+	}
+	y = field(page, y, "Email", participant.Email)
+
+	y = section(page, y+10, "Registration")
+	y = field(page, y, "Registration number", registration.RegNumber)
+	y = field(page, y, "Test", "TOEFL ITP")
+	if !registration.TestDate.IsZero() {
+		y = field(page, y, "Test date", registration.TestDate.Format(notificationTimeLayout))
+	}
+	if registration.TestLocation != "" {
+		y = field(page, y, "Location", registration.TestLocation)
+	}
+	return y
+}
+
+// amountTable draws the price breakdown of a payment and returns the baseline below it
+func amountTable(page *pdf.Page, y float64, payment *model.Payment) float64 {
+	y = section(page, y+10, "Amount")
+	row := func(label, value string, font pdf.Font) {
+		page.Text(docMargin, y, font, 10, label)
+		page.TextRight(docRight, y, font, 10, value)
+		y += 16
+	}
+
+	label := "TOEFL ITP test fee"
+	if payment.FeeTier != "" {
+		label = fmt.Sprintf("TOEFL ITP test fee (%s, %s)", payment.FeeTier, payment.ScheduleType)
+	}
+	row(label, formatRupiah(payment.BaseAmount), pdf.Helvetica)
+	if payment.DiscountAmount > 0 {
+		row("Voucher "+payment.VoucherCode, "- "+formatRupiah(payment.DiscountAmount), pdf.Helvetica)
+	}
+	if payment.UniqueCode > 0 {
+		row("Unique transfer code", formatRupiah(float64(payment.UniqueCode)), pdf.Helvetica)
+	}
+	page.Line(docMargin, y-10, docRight, y-10, 0.5)
+	y += 2
+	row("Total", formatRupiah(payment.Amount), pdf.HelveticaBold)
+	return y
+}
+
+// renderInvoice lays out the invoice of a payment with its transfer instructions
+func renderInvoice(payment *model.Payment, registration *model.Registration, participant *model.Participant, account model.TransferAccount) *pdf.Document {
+	doc := pdf.New("Invoice " + payment.InvoiceNumber)
+	doc.Subject = "TOEFL ITP registration " + registration.RegNumber
+	page := doc.AddPage()
+
+	y := letterhead(page, "INVOICE", payment.InvoiceNumber)
+	if payment.InvoiceIssuedAt != nil {
+		y = field(page, y, "Issued", payment.InvoiceIssuedAt.Format(documentDateLayout))
+	}
+	y = field(page, y, "Status", string(payment.PaymentStatus))
+	if !payment.ExpiredAt.IsZero() {
+		y = field(page, y, "Due", payment.ExpiredAt.Format(notificationTimeLayout))
+	}
+
+	y = paymentParties(page, y+10, "Billed to", registration, participant)
+	y = amountTable(page, y, payment)
+
+	if payment.Instructions != nil {
+		y = section(page, y+16, "How to pay")
+		y = field(page, y, "Bank", account.BankName)
+		y = field(page, y, "Account number", account.AccountNumber)
+		y = field(page, y, "Account name", account.AccountName)
+		y = field(page, y, "Amount to transfer", formatRupiah(payment.Amount))
+		page.Paragraph(docMargin, y+8, docWidth, pdf.Helvetica, 10, 14, payment.Instructions.Notes)
+	}
+
+	footer(page, "This invoice is valid without a signature.")
+	return doc
+}
+
+// renderReceipt lays out the proof that a payment was received
+func renderReceipt(payment *model.Payment, registration *model.Registration, participant *model.Participant, account model.TransferAccount) *pdf.Document {
+	doc := pdf.New("Receipt " + payment.InvoiceNumber)
+	doc.Subject = "TOEFL ITP registration " + registration.RegNumber
+	page := doc.AddPage()
+
+	y := letterhead(page, "RECEIPT", payment.InvoiceNumber)
+	y = field(page, y, "Invoice", payment.InvoiceNumber)
+	if !payment.PaidAt.IsZero() {
+		y = field(page, y, "Paid on", payment.PaidAt.Format(documentDateLayout))
+	}
+	status := "Verified"
+	if payment.VerifiedAt.IsZero() {
+		status = "Received, awaiting verification"
+	}
+	y = field(page, y, "Status", status)
+	if !payment.VerifiedAt.IsZero() {
+		y = field(page, y, "Verified on", payment.VerifiedAt.Format(documentDateLayout))
+	}
+
+	y = paymentParties(page, y+10, "Received from", registration, participant)
+	y = amountTable(page, y, payment)
+
+	y = section(page, y+16, "Paid to")
+	y = field(page, y, "Account", fmt.Sprintf("%s %s (%s)", account.BankName, account.AccountNumber, account.AccountName))
+	if payment.RefundedAmount > 0 {
+		y = field(page, y, "Refunded", formatRupiah(payment.RefundedAmount))
+	}
+
+	page.FillRect(docMargin, y+14, docWidth, 34, 0.92)
+	page.TextCenter(pdf.PageWidth/2, y+36, pdf.HelveticaBold, 14, "PAID "+formatRupiah(payment.Amount))
+
+	footer(page, "This receipt is valid without a signature and may be used for reimbursement.")
+	return doc
+}
+
+// drawQR draws a QR code with its quiet zone into a square box whose top-left
+// corner is (x, y). Runs of dark modules are merged into one rectangle.
+func drawQR(page *pdf.Page, x, y, size float64, symbol *qr.Code) {
+	const quietZone = 4
+	n := symbol.Size()
+	module := size / float64(n+2*quietZone)
+	x += quietZone * module
+	y += quietZone * module
+
+	for row := 0; row < n; row++ {
+		for col := 0; col < n; {
+			if !symbol.Dark(col, row) {
+				col++
+				continue
+			}
+			start := col
+			for col < n && symbol.Dark(col, row) {
+				col++
+			}
+			page.FillRect(x+float64(start)*module, y+float64(row)*module, float64(col-start)*module, module, 0)
+		}
+	}
+}
+
+// renderAdmissionCard lays out the card checked at the venue: who the participant
+// is, where and when they sit the test, and the signed code scanned at check-in
+func renderAdmissionCard(registration *model.Registration, participant *model.Participant, photo *pdf.Image, symbol *qr.Code, code string) *pdf.Document {
+	doc := pdf.New("Admission card " + registration.RegNumber)
+	doc.Subject = "TOEFL ITP admission card for " + participant.FullName
+	page := doc.AddPage()
+
+	top := letterhead(page, "ADMISSION CARD", registration.RegNumber)
+
+	// Photo in a 3x4 frame, scaled to fit
+	const photoWidth, photoHeight = 113.0, 151.0
+	w, h := photo.Size()
+	width, height := photoWidth, photoWidth*float64(h)/float64(w)
+	if height > photoHeight {
+		width, height = photoHeight*float64(w)/float64(h), photoHeight
+	}
+	page.Rect(docMargin, top, photoWidth, photoHeight, 0.5)
+	page.Image(photo, docMargin+(photoWidth-width)/2, top+(photoHeight-height)/2, width, height)
+
+	// Details beside the photo
+	left := docMargin + photoWidth + 24
+	y := top + 14
+	row := func(label, value string) {
+		page.Text(left, y, pdf.Helvetica, 9, label)
+		y = page.Paragraph(left+110, y, docRight-left-110, pdf.HelveticaBold, 11, 15, value) + 4
+	}
+	row("Name", participant.FullName)
+	label, identity := participantIdentity(participant)
+	row(label, identity)
+	row("Registration number", registration.RegNumber)
+	row("Plot ID", fmt.Sprint(registration.TestPlotID))
+	row("Room", registration.TestLocation)
+	row("Date and time", registration.TestDate.Format(notificationTimeLayout))
+
+	// Code scanned at check-in, with its text for manual entry
+	y = max(y, top+photoHeight) + 24
+	const qrSize = 150.0
+	drawQR(page, docMargin, y, qrSize, symbol)
+	page.TextCenter(docMargin+qrSize/2, y+qrSize+10, pdf.Helvetica, 6, code)
+
+	left = docMargin + qrSize + 16
+	page.Text(left, y+12, pdf.HelveticaBold, 11, "On the test day")
+	page.Line(left, y+17, docRight, y+17, 0.5)
+	notes := y + 34
+	for _, line := range []string{
+		"Bring this card, printed or on your phone, and the identity document shown above.",
+		"Arrive at least 30 minutes before the test starts. Late participants may not be admitted.",
+		"Only pencils and erasers are allowed at the desk. Phones must be switched off and stored.",
+		"This card is valid only for the session shown and cannot be transferred.",
+	} {
+		notes = page.Paragraph(left, notes, docRight-left, pdf.Helvetica, 10, 14, "- "+line) + 4
+	}
+
+	footer(page, "Admission cards are issued for approved registrations only and are checked against the registration list.")
+	return doc
+}
+
+// fit shortens s with an ellipsis until it fits in width
+func fit(font pdf.Font, size float64, s string, width float64) string {
+	if pdf.TextWidth(font, size, s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.TextWidth(font, size, string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// sessionHeader draws the letterhead and session details shared by the proctor
+// documents of a schedule and returns the baseline below them
+func sessionHeader(page *pdf.Page, title string, plan *model.SeatingPlan, pageNumber int) float64 {
+	number := fmt.Sprintf("Plot %d", plan.PlotID)
+	if pageNumber > 1 {
+		number += fmt.Sprintf(", page %d", pageNumber)
+	}
+	y := letterhead(page, title, number)
+	y = field(page, y, "Date and time", plan.DateTime.Format(notificationTimeLayout))
+	y = field(page, y, "Room", plan.Location)
+	return y + 8
+}
+
+// renderSeatingChart draws the seats as a grid seen from the proctor's desk, one row
+// of the room per grid row
+func renderSeatingChart(plan *model.SeatingPlan) *pdf.Document {
+	doc := pdf.New(fmt.Sprintf("Seating chart plot %d", plan.PlotID))
+	doc.Subject = "TOEFL ITP seating chart"
+
+	seated := map[int]*model.Seat{}
+	last, unseated := 0, 0
+	for _, seat := range plan.Seats {
+		if seat.Number == 0 {
+			unseated++
+			continue
+		}
+		seated[seat.Number] = seat
+		last = max(last, seat.Number)
+	}
+	rows := (last + plan.SeatsPerRow - 1) / plan.SeatsPerRow
+
+	const cellHeight, gap = 44.0, 4.0
+	cellWidth := (docWidth - gap*float64(plan.SeatsPerRow-1)) / float64(plan.SeatsPerRow)
+	bottom := pdf.PageHeight - 90
+
+	var page *pdf.Page
+	var y float64
+	pages := 0
+	newPage := func() {
+		page = doc.AddPage()
+		pages++
+		y = sessionHeader(page, "SEATING CHART", plan, pages)
+		page.FillRect(docMargin, y, docWidth, 18, 0.9)
+		page.TextCenter(pdf.PageWidth/2, y+12, pdf.HelveticaBold, 9, "FRONT - PROCTOR DESK")
+		y += 30
+		footer(page, fmt.Sprintf("%d seats in %s mode, %d to a row.", last, plan.Mode, plan.SeatsPerRow))
+	}
+	newPage()
+
+	if plan.AssignedAt == nil {
+		page.Text(docMargin, y+10, pdf.Helvetica, 10, "Seats have not been assigned yet.")
+	}
+	for row := 0; row < rows; row++ {
+		if y+cellHeight > bottom {
+			newPage()
+		}
+		for col := 0; col < plan.SeatsPerRow; col++ {
+			number := row*plan.SeatsPerRow + col + 1
+			x := docMargin + float64(col)*(cellWidth+gap)
+			page.Rect(x, y, cellWidth, cellHeight, 0.5)
+			page.Text(x+3, y+10, pdf.HelveticaBold, 8, model.SeatLabel(number, plan.SeatsPerRow))
+			if seat, ok := seated[number]; ok {
+				page.Text(x+3, y+23, pdf.Helvetica, 7, fit(pdf.Helvetica, 7, seat.FullName, cellWidth-6))
+				page.Text(x+3, y+33, pdf.Helvetica, 6, seat.RegNumber)
+				page.Text(x+3, y+41, pdf.Helvetica, 6, fit(pdf.Helvetica, 6, seat.Major, cellWidth-6))
+			}
+		}
+		y += cellHeight + gap
+	}
+
+	if unseated > 0 {
+		if y+20 > bottom {
+			newPage()
+		}
+		page.Paragraph(docMargin, y+14, docWidth, pdf.HelveticaBold, 10, 14,
+			fmt.Sprintf("%d registrations have no seat yet. Assign the seats again to include them.", unseated))
+	}
+
+	return doc
+}
+
+// renderDoorList draws the participants in the given order with a column to sign
+// on arrival
+func renderDoorList(plan *model.SeatingPlan, seats []*model.Seat) *pdf.Document {
+	doc := pdf.New(fmt.Sprintf("Door list plot %d", plan.PlotID))
+	doc.Subject = "TOEFL ITP door list"
+
+	columns := []struct {
+		title string
+		width float64
+	}{
+		{"No.", 26}, {"Name", 150}, {"Registration", 72}, {"Identity", 86}, {"Seat", 36}, {"Signature", 0},
+	}
+	columns[len(columns)-1].width = docWidth
+	for _, column := range columns[:len(columns)-1] {
+		columns[len(columns)-1].width -= column.width
+	}
+
+	const rowHeight = 22.0
+	bottom := pdf.PageHeight - 90
+
+	var page *pdf.Page
+	var y float64
+	pages := 0
+	newPage := func() {
+		page = doc.AddPage()
+		pages++
+		y = sessionHeader(page, "DOOR LIST", plan, pages)
+		x := docMargin
+		page.FillRect(docMargin, y, docWidth, rowHeight, 0.9)
+		for _, column := range columns {
+			page.Text(x+4, y+14, pdf.HelveticaBold, 9, column.title)
+			x += column.width
+		}
+		y += rowHeight
+		footer(page, fmt.Sprintf("%d participants.", len(seats)))
+	}
+	newPage()
+
+	for i, seat := range seats {
+		if y+rowHeight > bottom {
+			newPage()
+		}
+		values := []string{strconv.Itoa(i + 1), seat.FullName, seat.RegNumber, seat.Identity, seat.Label, ""}
+		x := docMargin
+		for j, column := range columns {
+			page.Text(x+4, y+14, pdf.Helvetica, 9, fit(pdf.Helvetica, 9, values[j], column.width-8))
+			x += column.width
+		}
+		page.Line(docMargin, y+rowHeight, docRight, y+rowHeight, 0.3)
+		y += rowHeight
+	}
+
+	return doc
+}
+
+// renderScoreCertificate lays out the certificate of a released score with the code
+// and link anyone can use to check it against the records
+func renderScoreCertificate(score *model.Score, certificate *model.ScoreCertificate, registration *model.Registration, participant *model.Participant, symbol *qr.Code, url string) *pdf.Document {
+	doc := pdf.New("Score certificate " + certificate.VerificationCode)
+	doc.Subject = "TOEFL ITP score of " + participant.FullName
+	page := doc.AddPage()
+
+	y := letterhead(page, "SCORE CERTIFICATE", certificate.VerificationCode)
+	y = field(page, y, "Name", participant.FullName)
+	label, identity := participantIdentity(participant)
+	y = field(page, y, label, identity)
+	y = field(page, y, "Test date", registration.TestDate.Format(documentDateLayout))
+	y = field(page, y, "Test plot", fmt.Sprint(score.TestPlotID))
+	y = field(page, y, "Registration number", registration.RegNumber)
+
+	y = section(page, y+10, "Scores")
+	for _, row := range []struct {
+		name  string
+		value int
+	}{
+		{"Listening Comprehension", score.ListeningComprehension},
+		{"Structure and Written Expression", score.StructureWrittenExpression},
+		{"Reading Comprehension", score.ReadingComprehension},
+	} {
+		page.Text(docMargin, y, pdf.Helvetica, 11, row.name)
+		page.TextRight(docRight, y, pdf.Helvetica, 11, fmt.Sprint(row.value))
+		y += 18
+	}
+	page.FillRect(docMargin, y-4, docWidth, 30, 0.92)
+	page.Text(docMargin+8, y+15, pdf.HelveticaBold, 13, "Total score")
+	page.TextRight(docRight-8, y+15, pdf.HelveticaBold, 16, fmt.Sprint(score.TotalScore))
+	y += 46
+
+	y = field(page, y, "Valid until", score.ValidUntil.Format(documentDateLayout))
+	y = field(page, y, "Issued on", certificate.IssuedAt.Format(documentDateLayout))
+
+	// Verification link for whoever receives the certificate
+	y += 16
+	const qrSize = 110.0
+	drawQR(page, docMargin, y, qrSize, symbol)
+	left := docMargin + qrSize + 16
+	page.Text(left, y+12, pdf.HelveticaBold, 11, "Verification")
+	page.Line(left, y+17, docRight, y+17, 0.5)
+	notes := page.Paragraph(left, y+34, docRight-left, pdf.Helvetica, 10, 14,
+		"Scan the code or open the link below to check this certificate. A certificate is replaced with a new code when its score is corrected; the old code then shows it as revoked.") + 4
+	page.Paragraph(left, notes, docRight-left, pdf.HelveticaBold, 9, 12, url)
+
+	footer(page, "This certificate is valid without a signature when its verification code checks as current.")
+	return doc
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+// EligibilityError reports every rule that rejected a registration
+type EligibilityError struct {
+	Violations []model.RuleViolation
+}
+
+func (e *EligibilityError) Error() string {
+	reasons := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		reasons[i] = violation.Code + ": " + violation.Message
+	}
+	return "cannot register: not eligible (" + strings.Join(reasons, "; ") + ")"
+}
+
+type EligibilityService struct {
+	repo *repository.EligibilityRuleRepository
+}
+
+func NewEligibilityService(repo *repository.EligibilityRuleRepository) *EligibilityService {
+	return &EligibilityService{repo: repo}
+}
+
+// validateParams checks that a rule has the settings its type needs
+func validateParams(ruleType model.EligibilityRuleType, params model.EligibilityParams) error {
+	switch ruleType {
+	case model.EligibilityRetakeInterval:
+		if params.Days <= 0 {
+			return fmt.Errorf("invalid eligibility rule: days must be greater than 0")
+		}
+	case model.EligibilityMaxAttemptsPerYear:
+		if params.MaxAttempts <= 0 {
+			return fmt.Errorf("invalid eligibility rule: max_attempts must be greater than 0")
+		}
+	case model.EligibilityValidScore:
+		if params.MinScore < 0 || params.MinScore > model.TotalScore(model.MaxScaledListening, model.MaxScaledStructure, model.MaxScaledReading) {
+			return fmt.Errorf("invalid eligibility rule: min_score is out of range")
+		}
+	case model.EligibilitySubsidizedMajors:
+		for _, major := range params.Majors {
+			if strings.TrimSpace(major) == "" {
+				return fmt.Errorf("invalid eligibility rule: majors must not be blank")
+			}
+		}
+	}
+	return nil
+}
+
+func (s *EligibilityService) CreateRule(ctx context.Context, req *model.CreateEligibilityRule) (*model.EligibilityRule, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+	if err := validateParams(req.Type, req.Params); err != nil {
+		return nil, err
+	}
+
+	rule := &model.EligibilityRule{
+		Code:        req.Code,
+		Type:        req.Type,
+		Params:      req.Params,
+		Description: req.Description,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+	}
+
+	if err := s.repo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *EligibilityService) ListRules(ctx context.Context) ([]*model.EligibilityRule, error) {
+	return s.repo.List(ctx, false)
+}
+
+func (s *EligibilityService) UpdateRule(ctx context.Context, id int64, req *model.UpdateEligibilityRule) (*model.EligibilityRule, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	rule, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateParams(rule.Type, req.Params); err != nil {
+		return nil, err
+	}
+
+	rule.Params = req.Params
+	rule.Description = req.Description
+	rule.Enabled = req.Enabled
+
+	if err := s.repo.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *EligibilityService) DeleteRule(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Check loads the enabled rules and returns a check that applies them inside the
+// registration transaction. With override set, failed rules are returned as waived
+// instead of rejecting the registration.
+func (s *EligibilityService) Check(ctx context.Context, override bool) (repository.EligibilityCheck, error) {
+	rules, err := s.repo.List(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(facts *model.EligibilityFacts) ([]model.RuleViolation, error) {
+		violations := evaluateRules(rules, facts, time.Now())
+		if len(violations) == 0 || override {
+			return violations, nil
+		}
+		return nil, &EligibilityError{Violations: violations}
+	}, nil
+}
+
+// evaluateRules returns a violation for every rule the facts do not satisfy
+func evaluateRules(rules []*model.EligibilityRule, facts *model.EligibilityFacts, now time.Time) []model.RuleViolation {
+	var violations []model.RuleViolation
+	for _, rule := range rules {
+		if message := evaluateRule(rule, facts, now); message != "" {
+			violations = append(violations, model.RuleViolation{Code: rule.Code, Type: rule.Type, Message: message})
+		}
+	}
+	return violations
+}
+
+// evaluateRule returns why the rule rejects the registration, or an empty string
+func evaluateRule(rule *model.EligibilityRule, facts *model.EligibilityFacts, now time.Time) string {
+	testDate := facts.Schedule.DateTime
+
+	switch rule.Type {
+	case model.EligibilityOneActiveRegistration:
+		for _, registration := range facts.Registrations {
+			if registration.TestDate.After(now) {
+				return fmt.Sprintf("participant already has an upcoming registration (%s)", registration.RegNumber)
+			}
+		}
+
+	case model.EligibilityRetakeInterval:
+		interval := time.Duration(rule.Params.Days) * 24 * time.Hour
+		for _, registration := range facts.Registrations {
+			gap := testDate.Sub(registration.TestDate)
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap < interval {
+				return fmt.Sprintf("tests must be at least %d days apart; registration %s is on %s",
+					rule.Params.Days, registration.RegNumber, registration.TestDate.Format(model.DateLayout))
+			}
+		}
+
+	case model.EligibilitySubsidizedMajors:
+		if !facts.Schedule.Subsidized {
+			return ""
+		}
+		if facts.Participant.Type != model.ParticipantTypeStudent || !facts.Participant.Active {
+			return "only active students may register for a subsidized session"
+		}
+		if len(rule.Params.Majors) == 0 {
+			return ""
+		}
+		for _, major := range rule.Params.Majors {
+			if strings.EqualFold(strings.TrimSpace(major), strings.TrimSpace(facts.Participant.Major)) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("major %q is not eligible for the subsidized session", facts.Participant.Major)
+
+	case model.EligibilityMaxAttemptsPerYear:
+		attempts := 0
+		for _, registration := range facts.Registrations {
+			if registration.TestDate.Year() == testDate.Year() {
+				attempts++
+			}
+		}
+		if attempts >= rule.Params.MaxAttempts {
+			return fmt.Sprintf("limit of %d tests in %d reached", rule.Params.MaxAttempts, testDate.Year())
+		}
+
+	case model.EligibilityValidScore:
+		for _, score := range facts.Scores {
+			if score.ValidAt(now) && score.TotalScore >= rule.Params.MinScore {
+				return ""
+			}
+		}
+		if rule.Params.MinScore > 0 {
+			return fmt.Sprintf("a valid TOEFL ITP score of at least %d is required", rule.Params.MinScore)
+		}
+		return "a valid TOEFL ITP score is required"
+	}
+
+	return ""
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/ical"
+)
+
+const (
+	feedProdID = "-//UNW Language Center//TOEFL ITP Schedules//EN"
+
+	// testSessionDuration is how long a TOEFL ITP session blocks in a calendar
+	testSessionDuration = 2 * time.Hour
+)
+
+type FeedService struct {
+	repo         *repository.FeedRepository
+	scheduleRepo *repository.ScheduleRepository
+	appURL       string
+}
+
+func NewFeedService(repo *repository.FeedRepository, scheduleRepo *repository.ScheduleRepository, appURL string) *FeedService {
+	return &FeedService{repo: repo, scheduleRepo: scheduleRepo, appURL: appURL}
+}
+
+// PublicScheduleFeed lists upcoming schedules that still have seats
+func (s *FeedService) PublicScheduleFeed(ctx context.Context) (*ical.Calendar, error) {
+	schedules, err := s.scheduleRepo.ListUpcoming(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &ical.Calendar{Name: "TOEFL ITP Test Schedules", ProdID: feedProdID}
+	for _, schedule := range schedules {
+		if schedule.Available <= 0 && schedule.Status != model.ScheduleStatusCancelled {
+			continue
+		}
+		event := scheduleEvent(schedule, schedule.UpdatedAt)
+		event.Description = fmt.Sprintf("Plot %d. %d of %d seats available.", schedule.PlotID, schedule.Available, schedule.Quota)
+		cal.Events = append(cal.Events, event)
+	}
+
+	return cal, nil
+}
+
+// IssueToken creates a personal feed URL for the caller, revoking any previous one
+func (s *FeedService) IssueToken(ctx context.Context, user *model.AuthUser) (*model.FeedSubscription, error) {
+	var ownerType model.FeedOwnerType
+	switch {
+	case user.HasRole(model.RoleStudent):
+		ownerType = model.FeedOwnerStudent
+	case user.HasRole(model.RoleProctor):
+		ownerType = model.FeedOwnerProctor
+	default:
+		return nil, fmt.Errorf("personal calendar feeds are only available to students and proctors")
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate feed token: %w", err)
+	}
+
+	feedToken := &model.FeedToken{
+		Token:     hex.EncodeToString(raw),
+		OwnerType: ownerType,
+		OwnerID:   user.ID,
+	}
+	if err := s.repo.RotateToken(ctx, feedToken); err != nil {
+		return nil, err
+	}
+
+	return &model.FeedSubscription{
+		Token: feedToken.Token,
+		URL:   fmt.Sprintf("%s/api/feeds/%s/calendar.ics", s.appURL, feedToken.Token),
+	}, nil
+}
+
+// PersonalFeed renders the sessions belonging to the owner of the token
+func (s *FeedService) PersonalFeed(ctx context.Context, token string) (*ical.Calendar, error) {
+	feedToken, err := s.repo.GetByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &ical.Calendar{Name: "My TOEFL ITP Sessions", ProdID: feedProdID}
+	switch feedToken.OwnerType {
+	case model.FeedOwnerStudent:
+		sessions, err := s.repo.ListStudentSessions(ctx, feedToken.OwnerID)
+		if err != nil {
+			return nil, err
+		}
+		for _, session := range sessions {
+			event := scheduleEvent(&session.Schedule, session.UpdatedAt)
+			// One event per registration so a re-registration is not merged with a rejected one
+			event.UID = fmt.Sprintf("registration-%d@toefl.unw", session.RegistrationID)
+			event.Description = fmt.Sprintf("Registration %s (%s). Plot %d.", session.RegNumber, session.RegistrationStatus, session.Schedule.PlotID)
+			event.Status = registrationEventStatus(session.RegistrationStatus)
+			if session.Schedule.Status == model.ScheduleStatusCancelled {
+				event.Status = "CANCELLED"
+			}
+			cal.Events = append(cal.Events, event)
+		}
+	case model.FeedOwnerProctor:
+		sessions, err := s.repo.ListProctorSessions(ctx, feedToken.OwnerID)
+		if err != nil {
+			return nil, err
+		}
+		for _, session := range sessions {
+			event := scheduleEvent(&session.Schedule, session.UpdatedAt)
+			// One event per assignment so being put back on a session is not merged with the removal
+			event.UID = fmt.Sprintf("proctor-assignment-%d@toefl.unw", session.AssignmentID)
+			event.Summary = "TOEFL ITP Proctoring"
+			event.Description = fmt.Sprintf("Plot %d. %d approved participants.", session.Schedule.PlotID, session.Participants)
+			if session.Removed {
+				event.Status = "CANCELLED"
+			}
+			cal.Events = append(cal.Events, event)
+		}
+	default:
+		return nil, fmt.Errorf("feed not found")
+	}
+
+	return cal, nil
+}
+
+// scheduleEvent builds the calendar entry for a schedule. The UID is stable so a moved
+// schedule updates the existing entry, and SEQUENCE grows with every change.
+func scheduleEvent(schedule *model.Schedule, updatedAt time.Time) ical.Event {
+	status := "CONFIRMED"
+	if schedule.Status == model.ScheduleStatusCancelled {
+		status = "CANCELLED"
+	}
+
+	return ical.Event{
+		UID:          fmt.Sprintf("schedule-%d@toefl.unw", schedule.ID),
+		Summary:      "TOEFL ITP Test",
+		Start:        schedule.DateTime,
+		End:          schedule.DateTime.Add(testSessionDuration),
+		Location:     schedule.Location,
+		Status:       status,
+		Sequence:     int(updatedAt.Sub(schedule.CreatedAt) / time.Second),
+		LastModified: updatedAt,
+	}
+}
+
+func registrationEventStatus(status string) string {
+	switch status {
+	case model.RegistrationStatusApproved:
+		return "CONFIRMED"
+	case model.RegistrationStatusRejected, model.RegistrationStatusCancelled:
+		return "CANCELLED"
+	default:
+		return "TENTATIVE"
+	}
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+type GroupRegistrationService struct {
+	repo          *repository.GroupRegistrationRepository
+	coordinators  *CoordinatorService
+	eligibility   *EligibilityService
+	waitlist      *WaitlistService
+	notifications *NotificationService
+	policy        RegistrationPolicy
+}
+
+func NewGroupRegistrationService(
+	repo *repository.GroupRegistrationRepository,
+	coordinators *CoordinatorService,
+	eligibility *EligibilityService,
+	waitlist *WaitlistService,
+	notifications *NotificationService,
+	policy RegistrationPolicy,
+) *GroupRegistrationService {
+	return &GroupRegistrationService{
+		repo:          repo,
+		coordinators:  coordinators,
+		eligibility:   eligibility,
+		waitlist:      waitlist,
+		notifications: notifications,
+		policy:        policy,
+	}
+}
+
+// authorizeGroup allows admins (faculty nil) to see every group and coordinators only
+// the groups of their faculty
+func authorizeGroup(group *model.GroupRegistration, faculty *model.Faculty) error {
+	if faculty == nil || group.FacultyCode == faculty.Code {
+		return nil
+	}
+	return fmt.Errorf("group registration not found")
+}
+
+// ParseStudentNumbers reads student numbers from the first column of a CSV or plain
+// text upload, one per line. A header row and blank lines are skipped.
+func ParseStudentNumbers(r io.Reader) ([]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var numbers []string
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid student list: %w", err)
+		}
+
+		number := strings.TrimSpace(record[0])
+		if number == "" || (line == 1 && strings.Contains(strings.ToLower(number), "student")) {
+			continue
+		}
+		numbers = append(numbers, number)
+	}
+
+	if len(numbers) == 0 {
+		return nil, fmt.Errorf("invalid student list: no student numbers found")
+	}
+
+	return numbers, nil
+}
+
+// CreateGroupRegistration registers a cohort for a schedule. Coordinators can only
+// register students whose major belongs to their faculty. Every student is checked
+// against the eligibility rules like an individual registration; coordinators cannot
+// override them. The returned report has no ID when an all-or-none batch was rolled back.
+func (s *GroupRegistrationService) CreateGroupRegistration(ctx context.Context, scheduleID int64, req *model.CreateGroupRegistration, user *model.AuthUser) (*model.GroupRegistration, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	faculty, err := s.coordinators.FacultyOf(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	eligible, err := s.eligibility.Check(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &model.GroupRegistration{
+		ScheduleID:    scheduleID,
+		CoordinatorID: user.ID,
+		CreatedBy:     user.Identifier(),
+		AllOrNone:     req.AllOrNone,
+		Notes:         req.Notes,
+	}
+	member := func(participant *model.Participant) error { return nil }
+	if faculty != nil {
+		group.FacultyCode = faculty.Code
+		member = func(participant *model.Participant) error {
+			if !faculty.HasMajor(participant.Major) {
+				return fmt.Errorf("cannot register: student is not in the %s faculty", faculty.Name)
+			}
+			return nil
+		}
+	}
+	err = s.repo.Create(ctx, group, req.StudentNumbers, registrationWindowCheck("register group"), member, eligible, s.policy.GroupInvoiceDue)
+	if err != nil {
+		return nil, err
+	}
+
+	if group.ID != 0 {
+		for _, result := range group.Results {
+			if result.Outcome != model.GroupOutcomeRegistered {
+				continue
+			}
+			s.notifications.NotifyStudent(ctx, result.StudentID,
+				fmt.Sprintf("Registration %s received", result.RegNumber),
+				fmt.Sprintf("Your faculty registered you for TOEFL ITP plot %d. The fee is paid by your faculty through invoice %s.",
+					group.PlotID, group.Invoice.InvoiceNumber))
+		}
+	}
+
+	return group, nil
+}
+
+func (s *GroupRegistrationService) GetGroupRegistration(ctx context.Context, id int64, user *model.AuthUser) (*model.GroupRegistration, error) {
+	faculty, err := s.coordinators.FacultyOf(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeGroup(group, faculty); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// ListGroupRegistrations lists every group for admins and the groups of their own
+// faculty for coordinators
+func (s *GroupRegistrationService) ListGroupRegistrations(ctx context.Context, user *model.AuthUser) ([]*model.GroupRegistration, error) {
+	faculty, err := s.coordinators.FacultyOf(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if faculty == nil {
+		return s.repo.List(ctx, "")
+	}
+	return s.repo.List(ctx, faculty.Code)
+}
+
+// MarkInvoicePaid records the faculty's payment of a group invoice so the group's
+// registrations can be approved
+func (s *GroupRegistrationService) MarkInvoicePaid(ctx context.Context, id int64, user *model.AuthUser) (*model.GroupInvoice, error) {
+	return s.repo.MarkInvoicePaid(ctx, id, user.Identifier())
+}
+
+// CancelInvoice cancels an unpaid group invoice and the registrations it covers,
+// offering their seats to the waitlist
+func (s *GroupRegistrationService) CancelInvoice(ctx context.Context, id int64, req *model.CancelGroupInvoice, user *model.AuthUser) (*model.GroupInvoice, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	invoice, cancelled, offered, err := s.repo.CancelInvoice(ctx, id, req.Reason, user.Identifier(), s.policy.ClaimWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, registration := range cancelled {
+		s.notifications.NotifyStudent(ctx, registration.StudentID,
+			fmt.Sprintf("Registration %s cancelled", registration.RegNumber),
+			fmt.Sprintf("Your faculty's invoice %s for TOEFL ITP plot %d was cancelled, so your registration was cancelled too.",
+				invoice.InvoiceNumber, registration.TestPlotID))
+	}
+	for _, entry := range offered {
+		s.waitlist.NotifyOffer(ctx, entry)
+	}
+
+	return invoice, nil
+}
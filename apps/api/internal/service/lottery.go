@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/lottery"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+type LotteryService struct {
+	repo          *repository.LotteryRepository
+	schedules     *repository.ScheduleRepository
+	eligibility   *EligibilityService
+	notifications *NotificationService
+	policy        RegistrationPolicy
+}
+
+func NewLotteryService(
+	repo *repository.LotteryRepository,
+	schedules *repository.ScheduleRepository,
+	eligibility *EligibilityService,
+	notifications *NotificationService,
+	policy RegistrationPolicy,
+) *LotteryService {
+	return &LotteryService{repo: repo, schedules: schedules, eligibility: eligibility, notifications: notifications, policy: policy}
+}
+
+// authorizeApplication allows admins to act on any application and students only on their own
+func authorizeApplication(application *model.LotteryApplication, user *model.AuthUser) error {
+	if user.HasRole(model.RoleAdmin, model.RoleSuperAdmin) {
+		return nil
+	}
+	if user.HasRole(model.RoleStudent) && user.ID == application.StudentID {
+		return nil
+	}
+	return fmt.Errorf("lottery application not found")
+}
+
+// Apply enters a student into the draw of a lottery schedule. The eligibility rules
+// are checked when applying so that only eligible students can win a seat.
+func (s *LotteryService) Apply(ctx context.Context, scheduleID int64, req *model.ApplyLottery, user *model.AuthUser) (*model.LotteryApplication, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	studentID := user.ID
+	if user.HasRole(model.RoleAdmin, model.RoleSuperAdmin) {
+		if req.StudentID == 0 {
+			return nil, fmt.Errorf("student_id is required")
+		}
+		studentID = req.StudentID
+	}
+
+	eligible, err := s.eligibility.Check(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	application, err := s.repo.Apply(ctx, scheduleID, studentID, registrationWindowCheck("apply"), eligible)
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule, err := s.schedules.GetByID(ctx, scheduleID); err == nil {
+		s.notifications.NotifyStudent(ctx, application.StudentID,
+			fmt.Sprintf("Lottery application for TOEFL ITP plot %d received", schedule.PlotID),
+			fmt.Sprintf("You applied for a seat in the test on %s. Seats are drawn by lottery when applications close; we will notify you of the result.",
+				schedule.DateTime.Format(notificationTimeLayout)))
+	}
+
+	return application, nil
+}
+
+func (s *LotteryService) Withdraw(ctx context.Context, id int64, user *model.AuthUser) (*model.LotteryApplication, error) {
+	application, err := s.repo.GetApplication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeApplication(application, user); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Withdraw(ctx, id)
+}
+
+func (s *LotteryService) ListBySchedule(ctx context.Context, scheduleID int64) ([]*model.LotteryApplication, error) {
+	return s.repo.ListBySchedule(ctx, scheduleID)
+}
+
+func (s *LotteryService) ListMine(ctx context.Context, user *model.AuthUser) ([]*model.LotteryApplication, error) {
+	return s.repo.ListByStudent(ctx, user.ID)
+}
+
+// Draw runs the lottery of a schedule whose application window has closed
+func (s *LotteryService) Draw(ctx context.Context, scheduleID int64, user *model.AuthUser) (*model.LotteryDraw, error) {
+	seed, err := lottery.NewSeed()
+	if err != nil {
+		return nil, err
+	}
+
+	eligible, err := s.eligibility.Check(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	draw, err := s.repo.Draw(ctx, scheduleID, seed, eligible, s.policy.PaymentDeadline, user.Identifier())
+	if err != nil {
+		return nil, err
+	}
+	s.notifyDraw(ctx, draw)
+
+	return draw, nil
+}
+
+// DrawDue is run periodically to draw every lottery whose application window has closed
+func (s *LotteryService) DrawDue(ctx context.Context) error {
+	ids, err := s.repo.ListDue(ctx)
+	if err != nil {
+		return err
+	}
+
+	// One failing schedule must not hold back the others
+	var errs []error
+	for _, id := range ids {
+		if _, err := s.Draw(ctx, id, nil); err != nil {
+			errs = append(errs, fmt.Errorf("failed to draw lottery for schedule %d: %w", id, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *LotteryService) GetDraw(ctx context.Context, scheduleID int64) (*model.LotteryDraw, error) {
+	return s.repo.GetDraw(ctx, scheduleID)
+}
+
+// Verify re-runs a stored draw from its seed and reports whether it produces the
+// same ranking
+func (s *LotteryService) Verify(ctx context.Context, scheduleID int64) (*model.LotteryVerification, error) {
+	draw, err := s.repo.GetDraw(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &model.LotteryVerification{
+		ScheduleID: draw.ScheduleID,
+		Seed:       draw.Seed,
+		Algorithm:  draw.Algorithm,
+	}
+	if draw.Algorithm != lottery.Algorithm {
+		return verification, nil
+	}
+
+	ids := make([]int64, len(draw.Results))
+	for i, result := range draw.Results {
+		ids[i] = result.ApplicationID
+	}
+	slices.Sort(ids)
+
+	order, err := lottery.Draw(draw.Seed, len(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	verification.Reproduced = true
+	for i, index := range order {
+		if draw.Results[i].Rank != i+1 || draw.Results[i].ApplicationID != ids[index] {
+			verification.Reproduced = false
+			break
+		}
+	}
+
+	return verification, nil
+}
+
+// notifyDraw tells every applicant their result
+func (s *LotteryService) notifyDraw(ctx context.Context, draw *model.LotteryDraw) {
+	schedule, err := s.schedules.GetByID(ctx, draw.ScheduleID)
+	if err != nil {
+		return
+	}
+
+	position := 0
+	for _, result := range draw.Results {
+		switch result.Outcome {
+		case model.LotteryStatusWon:
+			s.notifications.NotifyStudent(ctx, result.StudentID,
+				fmt.Sprintf("You won a seat for TOEFL ITP plot %d", schedule.PlotID),
+				fmt.Sprintf("You were drawn for a seat in the test on %s at %s. Please complete the payment to secure it.",
+					schedule.DateTime.Format(notificationTimeLayout), schedule.Location))
+		case model.LotteryStatusWaitlisted:
+			position++
+			s.notifications.NotifyStudent(ctx, result.StudentID,
+				fmt.Sprintf("You are on the waitlist for TOEFL ITP plot %d", schedule.PlotID),
+				fmt.Sprintf("All seats for the test on %s were drawn. You are number %d on the waitlist and will be notified if a seat opens up.",
+					schedule.DateTime.Format(notificationTimeLayout), position))
+		case model.LotteryStatusIneligible:
+			s.notifications.NotifyStudent(ctx, result.StudentID,
+				fmt.Sprintf("Your lottery application for TOEFL ITP plot %d was not accepted", schedule.PlotID),
+				fmt.Sprintf("You were drawn for a seat in the test on %s, but you no longer meet the registration requirements, so the seat went to the next applicant.",
+					schedule.DateTime.Format(notificationTimeLayout)))
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/mailer"
+)
+
+type NotificationService struct {
+	repo        *repository.NotificationRepository
+	mailer      mailer.Mailer
+	adminEmails []string
+}
+
+func NewNotificationService(repo *repository.NotificationRepository, mailer mailer.Mailer, adminEmails []string) *NotificationService {
+	return &NotificationService{repo: repo, mailer: mailer, adminEmails: adminEmails}
+}
+
+// NotifyStudent stores an in-app notification and emails it to the student.
+// Notifications are best effort: failures are logged and never fail the caller.
+func (s *NotificationService) NotifyStudent(ctx context.Context, studentID int64, subject, body string) {
+	notification := &model.Notification{StudentID: studentID, Subject: subject, Body: body}
+	email, err := s.repo.Create(ctx, notification)
+	if err != nil {
+		log.Printf("failed to notify student %d: %v", studentID, err)
+		return
+	}
+
+	go func() {
+		if err := s.mailer.Send([]string{email}, subject, body); err != nil {
+			log.Printf("failed to email student %d: %v", studentID, err)
+		}
+	}()
+}
+
+// NotifyAdmins emails the configured admin addresses. Admins have no in-app inbox,
+// so the message is only logged when no addresses are configured.
+func (s *NotificationService) NotifyAdmins(subject, body string) {
+	if len(s.adminEmails) == 0 {
+		log.Printf("admin notification (no ADMIN_EMAILS configured): %s\n%s", subject, body)
+		return
+	}
+
+	go func() {
+		if err := s.mailer.Send(s.adminEmails, subject, body); err != nil {
+			log.Printf("failed to email admins: %v", err)
+		}
+	}()
+}
+
+func (s *NotificationService) ListNotifications(ctx context.Context, user *model.AuthUser) ([]*model.Notification, error) {
+	return s.repo.ListByStudent(ctx, user.ID, 50)
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, id int64, user *model.AuthUser) error {
+	return s.repo.MarkRead(ctx, id, user.ID)
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Lets image.DecodeConfig check the format and size of uploads
+	_ "image/png"
+	"slices"
+	"strings"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+type ParticipantService struct {
+	repo     *repository.ParticipantRepository
+	proctors *repository.ProctorRepository
+}
+
+func NewParticipantService(repo *repository.ParticipantRepository, proctors *repository.ProctorRepository) *ParticipantService {
+	return &ParticipantService{repo: repo, proctors: proctors}
+}
+
+// identityField is the field every participant of the type must have, whatever the tier
+var identityField = map[string]string{
+	model.ParticipantTypeStudent:  model.ParticipantFieldStudentNumber,
+	model.ParticipantTypeExternal: model.ParticipantFieldNationalID,
+}
+
+// checkFeeTier loads the tier and verifies that the participant may be placed in it
+// and has filled in every field it requires
+func (s *ParticipantService) checkFeeTier(ctx context.Context, participant *model.Participant) error {
+	tier, err := s.repo.GetFeeTier(ctx, participant.FeeTier)
+	if err != nil {
+		if err.Error() == "fee tier not found" {
+			return fmt.Errorf("invalid participant: unknown fee tier %q", participant.FeeTier)
+		}
+		return err
+	}
+	if !tier.Active {
+		return fmt.Errorf("invalid participant: fee tier %q is no longer offered", tier.Code)
+	}
+	if tier.ParticipantType != participant.Type {
+		return fmt.Errorf("invalid participant: fee tier %q is for %s participants", tier.Code, tier.ParticipantType)
+	}
+
+	var missing []string
+	for _, field := range append([]string{identityField[participant.Type]}, tier.RequiredFields...) {
+		if strings.TrimSpace(participant.Field(field)) == "" && !slices.Contains(missing, field) {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("invalid participant: %s required for fee tier %q", strings.Join(missing, ", "), tier.Code)
+	}
+
+	return nil
+}
+
+func (s *ParticipantService) CreateParticipant(ctx context.Context, req *model.CreateParticipant) (*model.Participant, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	participant := &model.Participant{
+		Type:          req.Type,
+		StudentNumber: strings.TrimSpace(req.StudentNumber),
+		NationalID:    strings.TrimSpace(req.NationalID),
+		Institution:   strings.TrimSpace(req.Institution),
+		FullName:      req.FullName,
+		Phone:         req.Phone,
+		Email:         req.Email,
+		Major:         strings.TrimSpace(req.Major),
+		Active:        req.Type == model.ParticipantTypeStudent,
+		FeeTier:       req.FeeTier,
+	}
+	if participant.FeeTier == "" {
+		participant.FeeTier = model.ParticipantTypeStudent
+	}
+	if err := s.checkFeeTier(ctx, participant); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, participant); err != nil {
+		return nil, err
+	}
+
+	return participant, nil
+}
+
+func (s *ParticipantService) GetParticipant(ctx context.Context, id int64) (*model.Participant, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *ParticipantService) ListParticipants(ctx context.Context, participantType string) ([]*model.Participant, error) {
+	if participantType != "" && participantType != model.ParticipantTypeStudent && participantType != model.ParticipantTypeExternal {
+		return nil, fmt.Errorf("invalid participant: unknown type %q", participantType)
+	}
+	return s.repo.List(ctx, participantType)
+}
+
+func (s *ParticipantService) UpdateParticipant(ctx context.Context, id int64, req *model.UpdateParticipant) (*model.Participant, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	participant, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	participant.Phone = req.Phone
+	participant.Email = req.Email
+	if req.Institution != "" {
+		participant.Institution = strings.TrimSpace(req.Institution)
+	}
+	if req.FeeTier != "" && req.FeeTier != participant.FeeTier {
+		participant.FeeTier = req.FeeTier
+		if err := s.checkFeeTier(ctx, participant); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Update(ctx, participant); err != nil {
+		return nil, err
+	}
+
+	return participant, nil
+}
+
+func (s *ParticipantService) CreateFeeTier(ctx context.Context, req *model.CreateFeeTier) (*model.FeeTier, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	tier := &model.FeeTier{
+		Code:            strings.TrimSpace(req.Code),
+		Name:            req.Name,
+		ParticipantType: req.ParticipantType,
+		Amount:          req.Amount,
+		Prices:          req.Prices,
+		RequiredFields:  req.RequiredFields,
+	}
+	if tier.Prices == nil {
+		tier.Prices = map[string]float64{}
+	}
+	if tier.RequiredFields == nil {
+		tier.RequiredFields = []string{}
+	}
+
+	if err := s.repo.CreateFeeTier(ctx, tier); err != nil {
+		return nil, err
+	}
+
+	return tier, nil
+}
+
+func (s *ParticipantService) ListFeeTiers(ctx context.Context) ([]*model.FeeTier, error) {
+	return s.repo.ListFeeTiers(ctx)
+}
+
+func (s *ParticipantService) UpdateFeeTier(ctx context.Context, code string, req *model.UpdateFeeTier) (*model.FeeTier, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	tier := &model.FeeTier{
+		Code:           code,
+		Name:           req.Name,
+		Amount:         req.Amount,
+		Prices:         req.Prices,
+		RequiredFields: req.RequiredFields,
+		Active:         req.Active,
+	}
+	if tier.Prices == nil {
+		tier.Prices = map[string]float64{}
+	}
+	if tier.RequiredFields == nil {
+		tier.RequiredFields = []string{}
+	}
+
+	if err := s.repo.UpdateFeeTier(ctx, tier); err != nil {
+		return nil, err
+	}
+
+	return tier, nil
+}
+
+// authorizeParticipant allows admins to act on any participant and students only on
+// themselves
+func authorizeParticipant(participantID int64, user *model.AuthUser) error {
+	if user.HasRole(model.RoleAdmin, model.RoleSuperAdmin) {
+		return nil
+	}
+	if user.HasRole(model.RoleStudent) && user.ID == participantID {
+		return nil
+	}
+	return fmt.Errorf("participant not found")
+}
+
+// UploadPhoto stores the photo printed on the participant's admission cards. Only
+// portrait or square JPEG and PNG images of a printable size are accepted.
+func (s *ParticipantService) UploadPhoto(ctx context.Context, participantID int64, data []byte, user *model.AuthUser) (*model.ParticipantPhoto, error) {
+	if err := authorizeParticipant(participantID, user); err != nil {
+		return nil, err
+	}
+	if len(data) > model.MaxPhotoSize {
+		return nil, fmt.Errorf("invalid participant photo: larger than %d MB", model.MaxPhotoSize>>20)
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png") {
+		return nil, fmt.Errorf("invalid participant photo: must be a JPEG or PNG image")
+	}
+	if config.Width < 200 || config.Height < 200 {
+		return nil, fmt.Errorf("invalid participant photo: must be at least 200x200 pixels")
+	}
+	if config.Width > model.MaxPhotoDimension || config.Height > model.MaxPhotoDimension {
+		return nil, fmt.Errorf("invalid participant photo: must be at most %dx%d pixels", model.MaxPhotoDimension, model.MaxPhotoDimension)
+	}
+	if config.Width > config.Height {
+		return nil, fmt.Errorf("invalid participant photo: must be portrait or square")
+	}
+
+	photo := &model.ParticipantPhoto{
+		ParticipantID: participantID,
+		ContentType:   "image/" + format,
+		Data:          data,
+		Width:         config.Width,
+		Height:        config.Height,
+	}
+	if err := s.repo.SavePhoto(ctx, photo); err != nil {
+		return nil, err
+	}
+
+	return photo, nil
+}
+
+// GetPhoto returns a participant's photo. Proctors see the photos of participants
+// registered at the sessions they are assigned to so they can check identities at the door.
+func (s *ParticipantService) GetPhoto(ctx context.Context, participantID int64, user *model.AuthUser) (*model.ParticipantPhoto, error) {
+	if user.HasRole(model.RoleProctor) {
+		proctored, err := s.proctors.ProctorsParticipant(ctx, user.ID, participantID)
+		if err != nil {
+			return nil, err
+		}
+		if !proctored {
+			return nil, fmt.Errorf("participant not found")
+		}
+	} else if err := authorizeParticipant(participantID, user); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPhoto(ctx, participantID)
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/pdf"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+type PaymentService struct {
+	repo          *repository.PaymentRepository
+	registrations *repository.RegistrationRepository
+	participants  *repository.ParticipantRepository
+	policy        RegistrationPolicy
+	account       model.TransferAccount
+}
+
+func NewPaymentService(repo *repository.PaymentRepository, registrations *repository.RegistrationRepository, participants *repository.ParticipantRepository, policy RegistrationPolicy, account model.TransferAccount) *PaymentService {
+	return &PaymentService{repo: repo, registrations: registrations, participants: participants, policy: policy, account: account}
+}
+
+// formatRupiah writes an amount the way Indonesian banks print it, e.g. "Rp 150.123"
+func formatRupiah(amount float64) string {
+	digits := strconv.FormatInt(int64(math.Round(amount)), 10)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	return "Rp " + b.String()
+}
+
+// withInstructions tells the student what to transfer while a bank transfer is pending
+func (s *PaymentService) withInstructions(payment *model.Payment) *model.Payment {
+	if payment.PaymentStatus != model.PaymentStatusPending || payment.PaymentMethod != model.PaymentMethodBankTransfer {
+		return payment
+	}
+
+	notes := fmt.Sprintf("Transfer exactly %s before %s.", formatRupiah(payment.Amount), payment.ExpiredAt.Format(notificationTimeLayout))
+	if payment.UniqueCode > 0 {
+		notes += fmt.Sprintf(" The last digits (%03d) identify your payment; a different amount cannot be matched automatically.", payment.UniqueCode)
+	}
+	payment.Instructions = &model.PaymentInstructions{
+		TransferAccount: s.account,
+		Amount:          payment.Amount,
+		UniqueCode:      payment.UniqueCode,
+		TransferBefore:  payment.ExpiredAt,
+		Notes:           notes,
+	}
+	return payment
+}
+
+// Quote shows what a registration costs, optionally with a voucher, without using the voucher
+func (s *PaymentService) Quote(ctx context.Context, registrationID int64, voucherCode string, user *model.AuthUser) (*model.PriceQuote, error) {
+	registration, err := s.registrations.GetByID(ctx, registrationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(registration, user); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Quote(ctx, registration, voucherCode)
+}
+
+// CreatePayment opens a payment for a registration at the price computed by the server
+func (s *PaymentService) CreatePayment(ctx context.Context, req *model.CreatePayment, user *model.AuthUser) (*model.Payment, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	registration, err := s.registrations.GetByID(ctx, req.RegistrationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(registration, user); err != nil {
+		return nil, err
+	}
+
+	payment, err := s.repo.Create(ctx, req, s.policy.PaymentDeadline, user.Identifier())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withInstructions(payment), nil
+}
+
+func (s *PaymentService) GetPayment(ctx context.Context, id int64, user *model.AuthUser) (*model.Payment, error) {
+	payment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	registration, err := s.registrations.GetByID(ctx, payment.RegistrationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(registration, user); err != nil {
+		return nil, fmt.Errorf("payment not found")
+	}
+
+	return s.withInstructions(payment), nil
+}
+
+// document loads what the invoice and receipt of a payment show
+func (s *PaymentService) document(ctx context.Context, id int64, user *model.AuthUser) (*model.Payment, *model.Registration, *model.Participant, error) {
+	if _, err := s.GetPayment(ctx, id, user); err != nil {
+		return nil, nil, nil, err
+	}
+
+	payment, err := s.repo.IssueInvoice(ctx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	registration, err := s.registrations.GetByID(ctx, payment.RegistrationID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	participant, err := s.participants.GetByID(ctx, registration.StudentID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return s.withInstructions(payment), registration, participant, nil
+}
+
+// Invoice renders the invoice of a payment, with transfer instructions while it is pending
+func (s *PaymentService) Invoice(ctx context.Context, id int64, user *model.AuthUser) (*pdf.Document, string, error) {
+	payment, registration, participant, err := s.document(ctx, id, user)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return renderInvoice(payment, registration, participant, s.account), documentFilename("invoice", payment.InvoiceNumber), nil
+}
+
+// Receipt renders the proof of a payment that was received
+func (s *PaymentService) Receipt(ctx context.Context, id int64, user *model.AuthUser) (*pdf.Document, string, error) {
+	payment, registration, participant, err := s.document(ctx, id, user)
+	if err != nil {
+		return nil, "", err
+	}
+	switch payment.PaymentStatus {
+	case model.PaymentStatusPaid, model.PaymentStatusVerified, model.PaymentStatusPartiallyRefunded:
+	default:
+		return nil, "", fmt.Errorf("cannot issue receipt: payment is %s", payment.PaymentStatus)
+	}
+
+	return renderReceipt(payment, registration, participant, s.account), documentFilename("receipt", payment.InvoiceNumber), nil
+}
+
+// documentFilename turns a number such as I001/V/2025 into receipt-I001-V-2025.pdf
+func documentFilename(kind, number string) string {
+	return kind + "-" + strings.ReplaceAll(number, "/", "-") + ".pdf"
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+type ProctorService struct {
+	repo                   *repository.ProctorRepository
+	scheduleRepo           *repository.ScheduleRepository
+	seatingRepo            *repository.SeatingRepository
+	participantsPerProctor int // Minimum ratio; a session needs one proctor per this many seats of its quota
+}
+
+func NewProctorService(
+	repo *repository.ProctorRepository,
+	scheduleRepo *repository.ScheduleRepository,
+	seatingRepo *repository.SeatingRepository,
+	participantsPerProctor int,
+) *ProctorService {
+	return &ProctorService{repo: repo, scheduleRepo: scheduleRepo, seatingRepo: seatingRepo, participantsPerProctor: participantsPerProctor}
+}
+
+// authorizeProctor lets admins manage any proctor and proctors only themselves
+func authorizeProctor(proctorID int64, user *model.AuthUser) error {
+	if user.HasRole(model.RoleAdmin, model.RoleSuperAdmin) {
+		return nil
+	}
+	if user.HasRole(model.RoleProctor) && user.ID == proctorID {
+		return nil
+	}
+	return fmt.Errorf("proctor not found")
+}
+
+// authorizeSession lets admins into every session and proctors into the sessions they
+// are assigned to
+func authorizeSession(ctx context.Context, proctors *repository.ProctorRepository, scheduleID int64, user *model.AuthUser) error {
+	if user.HasRole(model.RoleAdmin, model.RoleSuperAdmin) {
+		return nil
+	}
+	if user.HasRole(model.RoleProctor) {
+		assigned, err := proctors.IsAssigned(ctx, scheduleID, user.ID)
+		if err != nil {
+			return err
+		}
+		if assigned {
+			return nil
+		}
+	}
+	return fmt.Errorf("schedule not found")
+}
+
+func (s *ProctorService) CreateProctor(ctx context.Context, req *model.CreateProctor) (*model.Proctor, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	proctor := &model.Proctor{
+		FullName: strings.TrimSpace(req.FullName),
+		Email:    strings.TrimSpace(req.Email),
+		Phone:    req.Phone,
+	}
+	if err := s.repo.Create(ctx, proctor); err != nil {
+		return nil, err
+	}
+
+	return proctor, nil
+}
+
+func (s *ProctorService) ListProctors(ctx context.Context, activeOnly bool) ([]*model.Proctor, error) {
+	return s.repo.List(ctx, activeOnly)
+}
+
+func (s *ProctorService) GetProctor(ctx context.Context, id int64, user *model.AuthUser) (*model.Proctor, error) {
+	if err := authorizeProctor(id, user); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// UpdateProctor changes a proctor's details. Deactivating keeps existing assignments;
+// the staffing report shows which sessions still need a replacement.
+func (s *ProctorService) UpdateProctor(ctx context.Context, id int64, req *model.UpdateProctor) (*model.Proctor, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	proctor, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	proctor.FullName = strings.TrimSpace(req.FullName)
+	proctor.Email = strings.TrimSpace(req.Email)
+	proctor.Phone = req.Phone
+	proctor.Active = req.Active
+	if err := s.repo.Update(ctx, proctor); err != nil {
+		return nil, err
+	}
+
+	return proctor, nil
+}
+
+// AddAvailability records a period in which the proctor can be assigned to sessions
+func (s *ProctorService) AddAvailability(ctx context.Context, proctorID int64, req *model.CreateProctorAvailability, user *model.AuthUser) (*model.ProctorAvailability, error) {
+	if err := authorizeProctor(proctorID, user); err != nil {
+		return nil, err
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+	if !req.StartsAt.Before(req.EndsAt) {
+		return nil, fmt.Errorf("invalid availability: starts_at must be before ends_at")
+	}
+	if !req.EndsAt.After(time.Now()) {
+		return nil, fmt.Errorf("invalid availability: the period has already ended")
+	}
+
+	availability := &model.ProctorAvailability{
+		ProctorID: proctorID,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		Notes:     req.Notes,
+	}
+	if err := s.repo.AddAvailability(ctx, availability); err != nil {
+		return nil, err
+	}
+
+	return availability, nil
+}
+
+func (s *ProctorService) ListAvailability(ctx context.Context, proctorID int64, user *model.AuthUser) ([]*model.ProctorAvailability, error) {
+	if err := authorizeProctor(proctorID, user); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAvailability(ctx, proctorID)
+}
+
+// DeleteAvailability removes a period unless the proctor is assigned to a session
+// only that period covers
+func (s *ProctorService) DeleteAvailability(ctx context.Context, proctorID, id int64, user *model.AuthUser) error {
+	if err := authorizeProctor(proctorID, user); err != nil {
+		return err
+	}
+	return s.repo.DeleteAvailability(ctx, proctorID, id, testSessionDuration)
+}
+
+// staffing compares a session's proctors with the minimum its quota needs
+func (s *ProctorService) staffing(schedule *model.Schedule, proctors []*model.AssignedProctor) *model.ScheduleStaffing {
+	staffing := &model.ScheduleStaffing{
+		ScheduleID: schedule.ID,
+		PlotID:     schedule.PlotID,
+		DateTime:   schedule.DateTime,
+		Location:   schedule.Location,
+		Status:     schedule.Status,
+		Quota:      schedule.Quota,
+		Required:   model.RequiredProctors(schedule.Quota, s.participantsPerProctor),
+		Proctors:   proctors,
+	}
+	if staffing.Proctors == nil {
+		staffing.Proctors = []*model.AssignedProctor{}
+	}
+	for _, proctor := range proctors {
+		if !proctor.Available || len(proctor.Conflicts) > 0 {
+			staffing.HasConflicts = true
+		}
+	}
+	staffing.Understaffed = schedule.Status == model.ScheduleStatusActive && len(proctors) < staffing.Required
+	return staffing
+}
+
+// GetStaffing returns the proctors of a session and whether it has enough of them
+func (s *ProctorService) GetStaffing(ctx context.Context, scheduleID int64) (*model.ScheduleStaffing, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	assigned, err := s.repo.ListAssigned(ctx, []int64{scheduleID}, testSessionDuration)
+	if err != nil {
+		return nil, err
+	}
+	return s.staffing(schedule, assigned[scheduleID]), nil
+}
+
+// ListStaffing reports the staffing of every upcoming active session, optionally only
+// the sessions that are understaffed or have an unavailable or double-booked proctor
+func (s *ProctorService) ListStaffing(ctx context.Context, problemsOnly bool) ([]*model.ScheduleStaffing, error) {
+	schedules, err := s.scheduleRepo.ListUpcoming(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for _, schedule := range schedules {
+		ids = append(ids, schedule.ID)
+	}
+	assigned, err := s.repo.ListAssigned(ctx, ids, testSessionDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	report := []*model.ScheduleStaffing{}
+	for _, schedule := range schedules {
+		if schedule.Status != model.ScheduleStatusActive {
+			continue
+		}
+		staffing := s.staffing(schedule, assigned[schedule.ID])
+		if problemsOnly && !staffing.Understaffed && !staffing.HasConflicts {
+			continue
+		}
+		report = append(report, staffing)
+	}
+
+	return report, nil
+}
+
+// AssignProctor puts a proctor on a session and returns the session's staffing
+func (s *ProctorService) AssignProctor(ctx context.Context, scheduleID int64, req *model.AssignProctor, user *model.AuthUser) (*model.ScheduleStaffing, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.Assign(ctx, scheduleID, req.ProctorID, testSessionDuration, user.Identifier()); err != nil {
+		return nil, err
+	}
+	return s.GetStaffing(ctx, scheduleID)
+}
+
+// UnassignProctor takes a proctor off a session and returns the session's staffing,
+// which may now be below the minimum
+func (s *ProctorService) UnassignProctor(ctx context.Context, scheduleID, proctorID int64, user *model.AuthUser) (*model.ScheduleStaffing, error) {
+	if err := s.repo.Unassign(ctx, scheduleID, proctorID, user.Identifier()); err != nil {
+		return nil, err
+	}
+	return s.GetStaffing(ctx, scheduleID)
+}
+
+// ProctorSessions lists the sessions a proctor is assigned to that have not ended,
+// with their fellow proctors and the participants to expect by seat
+func (s *ProctorService) ProctorSessions(ctx context.Context, proctorID int64, user *model.AuthUser) ([]*model.ProctorSession, error) {
+	if err := authorizeProctor(proctorID, user); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetByID(ctx, proctorID); err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.repo.ListSessions(ctx, proctorID, testSessionDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for _, schedule := range schedules {
+		ids = append(ids, schedule.ID)
+	}
+	assigned, err := s.repo.ListAssigned(ctx, ids, testSessionDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []*model.ProctorSession{}
+	for _, schedule := range schedules {
+		plan, err := s.seatingRepo.GetPlan(ctx, schedule.ID)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &model.ProctorSession{
+			Schedule:  schedule,
+			Proctors:  assigned[schedule.ID],
+			Attendees: plan.Seats,
+		})
+	}
+
+	return sessions, nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+type RefundService struct {
+	repo          *repository.RefundRepository
+	payments      *repository.PaymentRepository
+	registrations *repository.RegistrationRepository
+	waitlist      *WaitlistService
+	notifications *NotificationService
+	policy        RegistrationPolicy
+}
+
+func NewRefundService(
+	repo *repository.RefundRepository,
+	payments *repository.PaymentRepository,
+	registrations *repository.RegistrationRepository,
+	waitlist *WaitlistService,
+	notifications *NotificationService,
+	policy RegistrationPolicy,
+) *RefundService {
+	return &RefundService{
+		repo:          repo,
+		payments:      payments,
+		registrations: registrations,
+		waitlist:      waitlist,
+		notifications: notifications,
+		policy:        policy,
+	}
+}
+
+// authorizePayment hides payments of other students the same way authorize hides
+// their registrations
+func (s *RefundService) authorizePayment(ctx context.Context, paymentID int64, user *model.AuthUser) error {
+	payment, err := s.payments.GetByID(ctx, paymentID)
+	if err != nil {
+		return err
+	}
+	registration, err := s.registrations.GetByID(ctx, payment.RegistrationID)
+	if err != nil {
+		return err
+	}
+	if err := authorize(registration, user); err != nil {
+		return fmt.Errorf("payment not found")
+	}
+	return nil
+}
+
+// RequestRefund asks for money back from a paid payment of a registration that was
+// cancelled or rejected
+func (s *RefundService) RequestRefund(ctx context.Context, paymentID int64, req *model.CreateRefund, user *model.AuthUser) (*model.Refund, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+	if err := s.authorizePayment(ctx, paymentID, user); err != nil {
+		return nil, err
+	}
+
+	refund, err := s.repo.Create(ctx, paymentID, req, user.Identifier())
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifications.NotifyAdmins(
+		fmt.Sprintf("Refund %d requested", refund.ID),
+		fmt.Sprintf("A refund of %.2f was requested for payment %d: %s", refund.Amount, refund.PaymentID, refund.Reason))
+
+	return refund, nil
+}
+
+func (s *RefundService) ListByPayment(ctx context.Context, paymentID int64, user *model.AuthUser) ([]*model.Refund, error) {
+	if err := s.authorizePayment(ctx, paymentID, user); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByPayment(ctx, paymentID)
+}
+
+func (s *RefundService) ListRefunds(ctx context.Context, status string) ([]*model.Refund, error) {
+	switch status {
+	case "", model.RefundStatusRequested, model.RefundStatusApproved, model.RefundStatusRejected, model.RefundStatusCompleted:
+	default:
+		return nil, fmt.Errorf("invalid status %q", status)
+	}
+	return s.repo.List(ctx, status)
+}
+
+func (s *RefundService) GetRefund(ctx context.Context, id int64, user *model.AuthUser) (*model.Refund, error) {
+	refund, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizePayment(ctx, refund.PaymentID, user); err != nil {
+		return nil, fmt.Errorf("refund not found")
+	}
+	return refund, nil
+}
+
+// ReviewRefund approves or rejects a requested refund and tells the student
+func (s *RefundService) ReviewRefund(ctx context.Context, id int64, approve bool, req *model.ReviewRefund, user *model.AuthUser) (*model.Refund, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	refund, err := s.repo.Review(ctx, id, approve, req.Notes, user.Identifier())
+	if err != nil {
+		return nil, err
+	}
+
+	body := fmt.Sprintf("Your refund request of %.2f was approved. The amount will be transferred to %s %s (%s).",
+		refund.Amount, refund.BankName, refund.AccountNumber, refund.AccountName)
+	if !approve {
+		body = fmt.Sprintf("Your refund request of %.2f was rejected.", refund.Amount)
+		if refund.ReviewNotes != "" {
+			body += " Reason: " + refund.ReviewNotes
+		}
+	}
+	s.notifyStudent(ctx, refund, fmt.Sprintf("Refund %s", refund.Status), body)
+
+	return refund, nil
+}
+
+// CompleteRefund records the transfer of an approved refund
+func (s *RefundService) CompleteRefund(ctx context.Context, id int64, req *model.CompleteRefund, user *model.AuthUser) (*model.Refund, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	refund, payment, offered, err := s.repo.Complete(ctx, id, req, user.Identifier(), s.policy.ClaimWindow)
+	if err != nil {
+		return nil, err
+	}
+	s.waitlist.NotifyOffer(ctx, offered)
+
+	s.notifyStudent(ctx, refund, "Refund transferred",
+		fmt.Sprintf("%.2f was transferred to %s %s on %s (reference %s). Payment %d is now %s.",
+			refund.Amount, refund.BankName, refund.AccountNumber, refund.TransferredAt.Format(notificationTimeLayout),
+			refund.TransferReference, payment.ID, payment.PaymentStatus))
+
+	return refund, nil
+}
+
+func (s *RefundService) notifyStudent(ctx context.Context, refund *model.Refund, subject, body string) {
+	registration, err := s.registrations.GetByID(ctx, refund.RegistrationID)
+	if err != nil {
+		return
+	}
+	s.notifications.NotifyStudent(ctx, registration.StudentID, subject, body)
+}
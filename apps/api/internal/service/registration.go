@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+// RegistrationPolicy holds the configurable limits applied to registrations
+type RegistrationPolicy struct {
+	RescheduleCutoff time.Duration // No self-service changes this close to either test date
+	MaxReschedules   int           // Per registration
+	ClaimWindow      time.Duration // How long a seat offered from the waitlist is held
+	GroupInvoiceDue  time.Duration // Payment term of a group invoice, cut short by the test date
+	PaymentDeadline  time.Duration // Payment term of an individual registration and of each payment, cut short by the test date
+	CheckInOpens     time.Duration // Before the test starts; a card scanned earlier is refused
+	CheckInCutoff    time.Duration // After the test starts; later arrivals are no-shows
+}
+
+type RegistrationService struct {
+	repo          *repository.RegistrationRepository
+	eligibility   *EligibilityService
+	waitlist      *WaitlistService
+	notifications *NotificationService
+	policy        RegistrationPolicy
+}
+
+func NewRegistrationService(
+	repo *repository.RegistrationRepository,
+	eligibility *EligibilityService,
+	waitlist *WaitlistService,
+	notifications *NotificationService,
+	policy RegistrationPolicy,
+) *RegistrationService {
+	return &RegistrationService{repo: repo, eligibility: eligibility, waitlist: waitlist, notifications: notifications, policy: policy}
+}
+
+// authorize allows admins to act on any registration and students only on their own
+func authorize(registration *model.Registration, user *model.AuthUser) error {
+	if user.HasRole(model.RoleAdmin, model.RoleSuperAdmin) {
+		return nil
+	}
+	if user.HasRole(model.RoleStudent) && user.ID == registration.StudentID {
+		return nil
+	}
+	return fmt.Errorf("registration not found")
+}
+
+// registrationWindowCheck rejects schedules whose registration window is not open;
+// action prefixes the error, e.g. "register".
+func registrationWindowCheck(action string) repository.ScheduleCheck {
+	return func(schedule *model.Schedule) error {
+		switch schedule.RegistrationStatusAt(time.Now()) {
+		case model.RegistrationWindowUpcoming:
+			return fmt.Errorf("cannot %s: registration opens at %s", action, schedule.RegistrationOpensAt.Format(notificationTimeLayout))
+		case model.RegistrationWindowClosed:
+			return fmt.Errorf("cannot %s: registration is closed", action)
+		}
+		return nil
+	}
+}
+
+// CreateRegistration takes a seat for the student if every enabled eligibility rule
+// passes. Students always register themselves; admins register the student given in
+// the request and may override failed rules with a reason.
+func (s *RegistrationService) CreateRegistration(ctx context.Context, req *model.CreateRegistration, user *model.AuthUser) (*model.Registration, error) {
+	if user.HasRole(model.RoleStudent) {
+		req.StudentID = user.ID
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	if req.OverrideEligibility && !user.HasRole(model.RoleAdmin, model.RoleSuperAdmin) {
+		return nil, fmt.Errorf("eligibility override requires an admin role")
+	}
+
+	eligible, err := s.eligibility.Check(ctx, req.OverrideEligibility)
+	if err != nil {
+		return nil, err
+	}
+
+	registration, err := s.repo.Create(ctx, req, registrationWindowCheck("register"), eligible, s.policy.PaymentDeadline, user.Identifier())
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifications.NotifyStudent(ctx, registration.StudentID,
+		fmt.Sprintf("Registration %s received", registration.RegNumber),
+		fmt.Sprintf("You are registered for TOEFL ITP plot %d on %s at %s. Please complete the payment to secure your seat.",
+			registration.TestPlotID, registration.TestDate.Format(notificationTimeLayout), registration.TestLocation))
+
+	return registration, nil
+}
+
+// CancelRegistration withdraws a registration and offers its seat to the waitlist
+func (s *RegistrationService) CancelRegistration(ctx context.Context, id int64, req *model.CancelRegistration, user *model.AuthUser) (*model.Registration, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	registration, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(registration, user); err != nil {
+		return nil, err
+	}
+
+	registration, offered, err := s.repo.Cancel(ctx, id, req.Reason, user.Identifier(), s.policy.ClaimWindow)
+	if err != nil {
+		return nil, err
+	}
+	s.waitlist.NotifyOffer(ctx, offered)
+
+	return registration, nil
+}
+
+// ApproveRegistration confirms a paid registration, after which the student can
+// download their admission card
+func (s *RegistrationService) ApproveRegistration(ctx context.Context, id int64, req *model.ApproveRegistration, user *model.AuthUser) (*model.Registration, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	registration, err := s.repo.Approve(ctx, id, req.Notes, user.Identifier())
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifications.NotifyStudent(ctx, registration.StudentID,
+		"Registration "+registration.RegNumber+" approved",
+		fmt.Sprintf("Your registration %s for the TOEFL ITP test on %s at %s has been approved. "+
+			"Download your admission card and bring it with you on the test day.",
+			registration.RegNumber, registration.TestDate.Format(notificationTimeLayout), registration.TestLocation))
+
+	return registration, nil
+}
+
+// ExpireUnpaidRegistrations is run periodically to cancel registrations whose payment
+// deadline passed and hand their seats to the waitlist
+func (s *RegistrationService) ExpireUnpaidRegistrations(ctx context.Context) error {
+	results, err := s.repo.ExpireUnpaid(ctx, s.policy.ClaimWindow)
+	for _, result := range results {
+		s.notifications.NotifyStudent(ctx, result.Registration.StudentID,
+			fmt.Sprintf("Registration %s cancelled", result.Registration.RegNumber),
+			"Your registration was cancelled because the payment deadline passed.")
+		s.waitlist.NotifyOffer(ctx, result.Offered)
+	}
+	return err
+}
+
+func (s *RegistrationService) GetRegistration(ctx context.Context, id int64, user *model.AuthUser) (*model.Registration, error) {
+	registration, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(registration, user); err != nil {
+		return nil, err
+	}
+
+	return registration, nil
+}
+
+func (s *RegistrationService) ListHistory(ctx context.Context, id int64, user *model.AuthUser) ([]*model.RegistrationHistory, error) {
+	if _, err := s.GetRegistration(ctx, id, user); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListHistory(ctx, id)
+}
+
+// RescheduleRegistration moves a registration to another test plot, releasing the
+// old seat and taking a new one in the same transaction.
+func (s *RegistrationService) RescheduleRegistration(ctx context.Context, id int64, req *model.RescheduleRegistration, user *model.AuthUser) (*model.Registration, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	registration, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(registration, user); err != nil {
+		return nil, err
+	}
+
+	check := func(registration *model.Registration, from, to *model.Schedule) error {
+		now := time.Now()
+		cutoff := now.Add(s.policy.RescheduleCutoff)
+		hours := int(s.policy.RescheduleCutoff.Hours())
+
+		switch {
+		case registration.Status == model.RegistrationStatusRejected,
+			registration.Status == model.RegistrationStatusCancelled:
+			return fmt.Errorf("cannot reschedule: registration is %s", registration.Status)
+		case registration.RescheduleCount >= s.policy.MaxReschedules:
+			return fmt.Errorf("cannot reschedule: limit of %d reschedules reached", s.policy.MaxReschedules)
+		case from.DateTime.Before(cutoff):
+			return fmt.Errorf("cannot reschedule: changes close %d hours before the test", hours)
+		case to.Status == model.ScheduleStatusCancelled:
+			return fmt.Errorf("cannot reschedule: target schedule is cancelled")
+		case to.AllocationMode == model.AllocationLottery:
+			return fmt.Errorf("cannot reschedule: seats on the target schedule are allocated by lottery")
+		case to.RegistrationStatusAt(now) != model.RegistrationWindowOpen:
+			return fmt.Errorf("cannot reschedule: registration for the target schedule is not open")
+		case to.DateTime.Before(cutoff):
+			return fmt.Errorf("cannot reschedule: target schedule starts within %d hours", hours)
+		case to.Available <= 0:
+			return fmt.Errorf("cannot reschedule: target schedule is full")
+		}
+		return nil
+	}
+
+	eligible, err := s.eligibility.Check(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	registration, offered, err := s.repo.Reschedule(ctx, id, req.TargetScheduleID, check, eligible, req.Reason, user.Identifier(), s.policy.ClaimWindow)
+	if err != nil {
+		return nil, err
+	}
+	s.waitlist.NotifyOffer(ctx, offered)
+
+	return registration, nil
+}
@@ -2,6 +2,10 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
 	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
@@ -9,28 +13,99 @@ import (
 )
 
 type ScheduleService struct {
-	repo *repository.ScheduleRepository
+	repo          *repository.ScheduleRepository
+	calendarRepo  *repository.CalendarRepository
+	notifications *NotificationService
 }
 
-func NewScheduleService(repo *repository.ScheduleRepository) *ScheduleService {
-	return &ScheduleService{repo: repo}
+func NewScheduleService(repo *repository.ScheduleRepository, calendarRepo *repository.CalendarRepository, notifications *NotificationService) *ScheduleService {
+	return &ScheduleService{repo: repo, calendarRepo: calendarRepo, notifications: notifications}
 }
 
-func (s *ScheduleService) CreateSchedule(ctx context.Context, req *model.CreateSchedule) (*model.Schedule, error) {
+// validateRegistrationWindow checks that an optional registration window ends before the test
+func validateRegistrationWindow(opensAt, closesAt *time.Time, dateTime time.Time) error {
+	switch {
+	case opensAt != nil && !opensAt.Before(dateTime):
+		return fmt.Errorf("invalid registration window: registration must open before the test starts")
+	case closesAt != nil && closesAt.After(dateTime):
+		return fmt.Errorf("invalid registration window: registration must close before the test starts")
+	case opensAt != nil && closesAt != nil && !opensAt.Before(*closesAt):
+		return fmt.Errorf("invalid registration window: registration_opens_at must be before registration_closes_at")
+	}
+	return nil
+}
+
+// validateAllocation checks that a lottery schedule has a closing time to draw at
+func validateAllocation(mode string, closesAt *time.Time) error {
+	if mode == model.AllocationLottery && closesAt == nil {
+		return fmt.Errorf("invalid registration window: lottery schedules need registration_closes_at")
+	}
+	return nil
+}
+
+// checkCalendar rejects dates that fall on a holiday or blackout period unless a
+// super_admin explicitly asked to override the calendar.
+func (s *ScheduleService) checkCalendar(ctx context.Context, dateTime time.Time, override bool, user *model.AuthUser) error {
+	if override {
+		if !user.HasRole(model.RoleSuperAdmin) {
+			return fmt.Errorf("calendar override requires the super_admin role")
+		}
+		return nil
+	}
+
+	// The calendar lists campus dates; a time sent with another offset can fall on the
+	// next or previous day there
+	loc, err := time.LoadLocation(campusTimezone)
+	if err != nil {
+		return fmt.Errorf("failed to load campus timezone: %w", err)
+	}
+	date := model.NewDate(dateTime.In(loc))
+	event, err := s.calendarRepo.FindBlocking(ctx, date)
+	if err != nil {
+		return err
+	}
+	if event != nil {
+		return fmt.Errorf("schedule date %s falls on a blocked calendar date: %s (%s)", date, event.Name, event.Type)
+	}
+
+	return nil
+}
+
+func (s *ScheduleService) CreateSchedule(ctx context.Context, req *model.CreateSchedule, user *model.AuthUser) (*model.Schedule, error) {
 	if err := validator.New().Struct(req); err != nil {
 		return nil, err
 	}
 
+	if err := validateRegistrationWindow(req.RegistrationOpensAt, req.RegistrationClosesAt, req.DateTime); err != nil {
+		return nil, err
+	}
+	if req.AllocationMode == "" {
+		req.AllocationMode = model.AllocationFirstCome
+	}
+	if err := validateAllocation(req.AllocationMode, req.RegistrationClosesAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCalendar(ctx, req.DateTime, req.OverrideCalendar, user); err != nil {
+		return nil, err
+	}
+
 	schedule := &model.Schedule{
-		DateTime:  req.DateTime,
-		Location:  req.Location,
-		Quota:     req.Quota,
-		Available: req.Quota,
+		DateTime:             req.DateTime,
+		Location:             req.Location,
+		Quota:                req.Quota,
+		Available:            req.Quota,
+		Status:               model.ScheduleStatusActive,
+		RegistrationOpensAt:  req.RegistrationOpensAt,
+		RegistrationClosesAt: req.RegistrationClosesAt,
+		Subsidized:           req.Subsidized,
+		AllocationMode:       req.AllocationMode,
 	}
 
 	if err := s.repo.Create(ctx, schedule); err != nil {
 		return nil, err
 	}
+	schedule.RegistrationStatus = schedule.RegistrationStatusAt(time.Now())
 
 	return schedule, nil
 }
@@ -40,27 +115,83 @@ func (s *ScheduleService) GetSchedule(ctx context.Context, id int64) (*model.Sch
 	if err != nil {
 		return nil, err
 	}
+	if err := s.repo.AttachSegments(ctx, schedule); err != nil {
+		return nil, err
+	}
 
 	return schedule, nil
 }
 
-func (s *ScheduleService) UpdateSchedule(ctx context.Context, id int64, req *model.UpdateSchedule) (*model.Schedule, error) {
+func (s *ScheduleService) UpdateSchedule(ctx context.Context, id int64, req *model.UpdateSchedule, user *model.AuthUser) (*model.Schedule, error) {
 	if err := validator.New().Struct(req); err != nil {
 		return nil, err
 	}
 
+	if !req.DateTime.IsZero() {
+		if err := s.checkCalendar(ctx, req.DateTime, req.OverrideCalendar, user); err != nil {
+			return nil, err
+		}
+	}
+
 	schedule, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if schedule.Status == model.ScheduleStatusCancelled {
+		return nil, fmt.Errorf("schedule is already cancelled")
+	}
 
 	schedule.DateTime = req.DateTime
 	schedule.Location = req.Location
 	schedule.Quota = req.Quota
+	if req.ClearRegistrationWindow {
+		schedule.RegistrationOpensAt = nil
+		schedule.RegistrationClosesAt = nil
+	}
+	if req.RegistrationOpensAt != nil {
+		schedule.RegistrationOpensAt = req.RegistrationOpensAt
+	}
+	if req.RegistrationClosesAt != nil {
+		schedule.RegistrationClosesAt = req.RegistrationClosesAt
+	}
+	if req.Subsidized != nil {
+		schedule.Subsidized = *req.Subsidized
+	}
+
+	if req.AllocationMode != "" && req.AllocationMode != schedule.AllocationMode {
+		// Switching modes would strand lottery applicants or first-come registrations
+		taken, err := s.repo.HasApplicantsOrRegistrations(ctx, schedule)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, fmt.Errorf("cannot change allocation mode: schedule already has applicants or registrations")
+		}
+		schedule.AllocationMode = req.AllocationMode
+	}
+	if err := validateAllocation(schedule.AllocationMode, schedule.RegistrationClosesAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AttachSegments(ctx, schedule); err != nil {
+		return nil, err
+	}
+	reserved := 0
+	for _, segment := range schedule.Segments {
+		reserved += segment.Quota
+	}
+	if schedule.Quota < reserved {
+		return nil, fmt.Errorf("invalid quota segments: segments reserve %d seats but the schedule has %d", reserved, schedule.Quota)
+	}
+
+	if err := validateRegistrationWindow(schedule.RegistrationOpensAt, schedule.RegistrationClosesAt, schedule.DateTime); err != nil {
+		return nil, err
+	}
 
 	if err := s.repo.Update(ctx, schedule); err != nil {
 		return nil, err
 	}
+	schedule.RegistrationStatus = schedule.RegistrationStatusAt(time.Now())
 
 	return schedule, nil
 }
@@ -75,6 +206,58 @@ func (s *ScheduleService) DeleteSchedule(ctx context.Context, id int64) error {
 	return s.repo.Delete(ctx, id)
 }
 
+// CancelSchedule cancels a session that can no longer take place, transferring or
+// cancelling its registrations as requested.
+func (s *ScheduleService) CancelSchedule(ctx context.Context, id int64, req *model.CancelSchedule, user *model.AuthUser) (*model.CancelScheduleResult, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Cancel(ctx, id, req, user.Identifier())
+}
+
+// ReplaceSegments splits the schedule's quota into the given segments, replacing any
+// existing ones. Available keeps counting every free seat.
+func (s *ScheduleService) ReplaceSegments(ctx context.Context, id int64, req *model.ReplaceQuotaSegments) (*model.Schedule, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReplaceSegments(ctx, id, req.Segments)
+}
+
+// CloseRegistrationWindows closes every registration window that has passed and
+// sends admins the final counts of each schedule.
+func (s *ScheduleService) CloseRegistrationWindows(ctx context.Context) error {
+	summaries, err := s.repo.CloseRegistrationWindows(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, summary := range summaries {
+		schedule := summary.Schedule
+
+		statuses := make([]string, 0, len(summary.StatusCounts))
+		for status := range summary.StatusCounts {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+
+		var body strings.Builder
+		fmt.Fprintf(&body, "Registration for TOEFL ITP plot %d on %s at %s is now closed.\n\n",
+			schedule.PlotID, schedule.DateTime.Format(notificationTimeLayout), schedule.Location)
+		fmt.Fprintf(&body, "Registered: %d of %d seats\n", summary.Registered, schedule.Quota)
+		for _, status := range statuses {
+			fmt.Fprintf(&body, "  %s: %d\n", status, summary.StatusCounts[status])
+		}
+		fmt.Fprintf(&body, "Waitlisted: %d\n", summary.Waitlisted)
+
+		s.notifications.NotifyAdmins(fmt.Sprintf("Registration closed for TOEFL ITP plot %d", schedule.PlotID), body.String())
+	}
+
+	return nil
+}
+
 func (s *ScheduleService) ListSchedules(ctx context.Context, page, pageSize int, sortBy, sortOrder string) (*model.PaginatedResponse, error) {
 	limit := pageSize
 	offset := (page - 1) * pageSize
@@ -83,6 +266,9 @@ func (s *ScheduleService) ListSchedules(ctx context.Context, page, pageSize int,
 	if err != nil {
 		return nil, err
 	}
+	if err := s.repo.AttachSegments(ctx, schedules...); err != nil {
+		return nil, err
+	}
 
 	// Convert []*model.Schedule to []model.Schedule
 	scheduleList := make([]model.Schedule, len(schedules))
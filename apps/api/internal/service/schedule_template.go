@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+const (
+	// campusTimezone is where sessions take place; templates default to it and calendar
+	// dates are read in it
+	campusTimezone = "Asia/Jakarta"
+
+	// maxTemplateOccurrences guards against a typo in the date range creating thousands of schedules
+	maxTemplateOccurrences = 500
+)
+
+type ScheduleTemplateService struct {
+	repo         *repository.ScheduleTemplateRepository
+	scheduleRepo *repository.ScheduleRepository
+	calendarRepo *repository.CalendarRepository
+}
+
+func NewScheduleTemplateService(
+	repo *repository.ScheduleTemplateRepository,
+	scheduleRepo *repository.ScheduleRepository,
+	calendarRepo *repository.CalendarRepository,
+) *ScheduleTemplateService {
+	return &ScheduleTemplateService{repo: repo, scheduleRepo: scheduleRepo, calendarRepo: calendarRepo}
+}
+
+func (s *ScheduleTemplateService) CreateTemplate(ctx context.Context, req *model.CreateScheduleTemplate) (*model.ScheduleTemplate, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	template := &model.ScheduleTemplate{
+		Name:          req.Name,
+		Location:      req.Location,
+		Quota:         req.Quota,
+		Weekdays:      req.Weekdays,
+		Times:         req.Times,
+		Timezone:      req.Timezone,
+		StartDate:     req.StartDate,
+		EndDate:       req.EndDate,
+		ExcludedDates: req.ExcludedDates,
+	}
+	if err := validateTemplate(template); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (s *ScheduleTemplateService) GetTemplate(ctx context.Context, id int64) (*model.ScheduleTemplate, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *ScheduleTemplateService) UpdateTemplate(ctx context.Context, id int64, req *model.UpdateScheduleTemplate) (*model.ScheduleTemplate, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	template, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		template.Name = req.Name
+	}
+	if req.Location != "" {
+		template.Location = req.Location
+	}
+	if req.Quota != 0 {
+		template.Quota = req.Quota
+	}
+	if len(req.Weekdays) > 0 {
+		template.Weekdays = req.Weekdays
+	}
+	if len(req.Times) > 0 {
+		template.Times = req.Times
+	}
+	if req.Timezone != "" {
+		template.Timezone = req.Timezone
+	}
+	if !req.StartDate.IsZero() {
+		template.StartDate = req.StartDate
+	}
+	if !req.EndDate.IsZero() {
+		template.EndDate = req.EndDate
+	}
+	if req.ExcludedDates != nil {
+		template.ExcludedDates = req.ExcludedDates
+	}
+	if err := validateTemplate(template); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (s *ScheduleTemplateService) DeleteTemplate(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *ScheduleTemplateService) ListTemplates(ctx context.Context) ([]*model.ScheduleTemplate, error) {
+	return s.repo.List(ctx)
+}
+
+// PreviewSchedules expands the template without writing anything
+func (s *ScheduleTemplateService) PreviewSchedules(ctx context.Context, id int64) (*model.GenerateSchedulesResult, error) {
+	return s.generate(ctx, id, true)
+}
+
+// GenerateSchedules creates every non-skipped occurrence of the template in one transaction
+func (s *ScheduleTemplateService) GenerateSchedules(ctx context.Context, id int64) (*model.GenerateSchedulesResult, error) {
+	return s.generate(ctx, id, false)
+}
+
+func (s *ScheduleTemplateService) generate(ctx context.Context, id int64, dryRun bool) (*model.GenerateSchedulesResult, error) {
+	template, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences, err := expandTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.scheduleRepo.ListDateTimesByTemplate(ctx, template.ID)
+	if err != nil {
+		return nil, err
+	}
+	generated := make(map[int64]bool, len(existing))
+	for _, dt := range existing {
+		generated[dt.Unix()] = true
+	}
+
+	excluded := make(map[string]bool, len(template.ExcludedDates))
+	for _, d := range template.ExcludedDates {
+		excluded[d.String()] = true
+	}
+
+	// Holidays and blackout periods from the academic calendar
+	events, err := s.calendarRepo.ListBetween(ctx, template.StartDate, template.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	blocked := make(map[string]string)
+	for _, event := range events {
+		for d := event.StartDate.Time; !d.After(event.EndDate.Time); d = d.AddDate(0, 0, 1) {
+			blocked[d.Format(model.DateLayout)] = event.Name
+		}
+	}
+
+	result := &model.GenerateSchedulesResult{TemplateID: template.ID, DryRun: dryRun}
+	now := time.Now()
+	var schedules []*model.Schedule
+	var pending []int
+	for i := range occurrences {
+		occ := &occurrences[i]
+		date := occ.DateTime.Format(model.DateLayout)
+		switch {
+		case excluded[date]:
+			occ.Skipped, occ.SkipReason = true, "excluded date"
+		case blocked[date] != "":
+			occ.Skipped, occ.SkipReason = true, "blocked by calendar: "+blocked[date]
+		case occ.DateTime.Before(now):
+			occ.Skipped, occ.SkipReason = true, "date is in the past"
+		case generated[occ.DateTime.Unix()]:
+			occ.Skipped, occ.SkipReason = true, "already generated"
+		}
+
+		if occ.Skipped {
+			result.Skipped++
+			continue
+		}
+
+		schedules = append(schedules, &model.Schedule{
+			DateTime:   occ.DateTime,
+			Location:   occ.Location,
+			Quota:      occ.Quota,
+			Available:  occ.Quota,
+			TemplateID: &template.ID,
+		})
+		pending = append(pending, i)
+	}
+
+	if !dryRun && len(schedules) > 0 {
+		if err := s.scheduleRepo.CreateBatch(ctx, schedules); err != nil {
+			return nil, err
+		}
+		for j, i := range pending {
+			occurrences[i].ScheduleID = schedules[j].ID
+		}
+	}
+
+	result.Created = len(schedules)
+	result.Occurrences = occurrences
+
+	return result, nil
+}
+
+func validateTemplate(template *model.ScheduleTemplate) error {
+	if template.Timezone == "" {
+		template.Timezone = campusTimezone
+	}
+	if template.ExcludedDates == nil {
+		template.ExcludedDates = []model.Date{}
+	}
+	if template.StartDate.IsZero() || template.EndDate.IsZero() {
+		return fmt.Errorf("invalid schedule template: start_date and end_date are required")
+	}
+	if template.EndDate.Before(template.StartDate.Time) {
+		return fmt.Errorf("invalid schedule template: end_date must not be before start_date")
+	}
+	if _, err := time.LoadLocation(template.Timezone); err != nil {
+		return fmt.Errorf("invalid schedule template: unknown timezone %q", template.Timezone)
+	}
+	return nil
+}
+
+// expandTemplate lists every session described by the recurrence rule, in chronological order
+func expandTemplate(template *model.ScheduleTemplate) ([]model.ScheduleOccurrence, error) {
+	loc, err := time.LoadLocation(template.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule template: unknown timezone %q", template.Timezone)
+	}
+
+	weekdays := make(map[time.Weekday]bool, len(template.Weekdays))
+	for _, wd := range template.Weekdays {
+		weekdays[time.Weekday(wd)] = true
+	}
+
+	clocks := make([]time.Time, 0, len(template.Times))
+	for _, t := range template.Times {
+		clock, err := time.Parse("15:04", t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule template: time %q must be HH:MM", t)
+		}
+		clocks = append(clocks, clock)
+	}
+	sort.Slice(clocks, func(i, j int) bool { return clocks[i].Before(clocks[j]) })
+
+	var occurrences []model.ScheduleOccurrence
+	for d := template.StartDate.Time; !d.After(template.EndDate.Time); d = d.AddDate(0, 0, 1) {
+		if !weekdays[d.Weekday()] {
+			continue
+		}
+		for _, clock := range clocks {
+			occurrences = append(occurrences, model.ScheduleOccurrence{
+				DateTime: time.Date(d.Year(), d.Month(), d.Day(), clock.Hour(), clock.Minute(), 0, 0, loc),
+				Location: template.Location,
+				Quota:    template.Quota,
+			})
+		}
+		if len(occurrences) > maxTemplateOccurrences {
+			return nil, fmt.Errorf("invalid schedule template: date range produces more than %d schedules", maxTemplateOccurrences)
+		}
+	}
+
+	return occurrences, nil
+}
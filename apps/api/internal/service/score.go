@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/pdf"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/qr"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+type ScoreService struct {
+	repo           *repository.ScoreRepository
+	registrations  *repository.RegistrationRepository
+	participants   *repository.ParticipantRepository
+	notifications  *NotificationService
+	validityMonths int    // How long a score is valid after the test
+	appURL         string // Base of the verification link printed on certificates
+}
+
+func NewScoreService(
+	repo *repository.ScoreRepository,
+	registrations *repository.RegistrationRepository,
+	participants *repository.ParticipantRepository,
+	notifications *NotificationService,
+	validityMonths int,
+	appURL string,
+) *ScoreService {
+	if validityMonths <= 0 {
+		validityMonths = model.DefaultScoreValidityMonths
+	}
+	return &ScoreService{
+		repo:           repo,
+		registrations:  registrations,
+		participants:   participants,
+		notifications:  notifications,
+		validityMonths: validityMonths,
+		appURL:         appURL,
+	}
+}
+
+// RecordScore enters the raw scores of an attended registration as a draft; the scaled
+// scores come from the conversion table of the form the participant took
+func (s *ScoreService) RecordScore(ctx context.Context, req *model.CreateScore, user *model.AuthUser) (*model.Score, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	score := &model.Score{
+		RegistrationID:   req.RegistrationID,
+		ListeningCorrect: req.ListeningCorrect,
+		StructureCorrect: req.StructureCorrect,
+		ReadingCorrect:   req.ReadingCorrect,
+		RecordedBy:       user.Identifier(),
+	}
+	if err := s.repo.Create(ctx, score, s.validityMonths); err != nil {
+		return nil, err
+	}
+
+	return score, nil
+}
+
+func (s *ScoreService) GetScore(ctx context.Context, id int64, user *model.AuthUser) (*model.Score, error) {
+	score, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeParticipant(score.StudentID, user); err != nil {
+		return nil, fmt.Errorf("score not found")
+	}
+	if score.ReleasedAt == nil && !user.HasRole(model.RoleAdmin, model.RoleSuperAdmin) {
+		return nil, fmt.Errorf("score not found")
+	}
+	return score, nil
+}
+
+// ListScores returns the scores of a test plot, or every score when testPlotID is 0
+func (s *ScoreService) ListScores(ctx context.Context, testPlotID int64) ([]*model.Score, error) {
+	return s.repo.List(ctx, testPlotID, 0, false)
+}
+
+func (s *ScoreService) ListParticipantScores(ctx context.Context, participantID int64, user *model.AuthUser) ([]*model.Score, error) {
+	if err := authorizeParticipant(participantID, user); err != nil {
+		return nil, err
+	}
+	// Students only see their released scores
+	return s.repo.List(ctx, 0, participantID, !user.HasRole(model.RoleAdmin, model.RoleSuperAdmin))
+}
+
+// ListExpiring returns the participants whose latest score expires within the given
+// number of days, e.g. to remind them to retake the test
+func (s *ScoreService) ListExpiring(ctx context.Context, days int) ([]*model.ExpiringScore, error) {
+	if days <= 0 {
+		days = model.DefaultScoreExpiryWindowDays
+	}
+
+	now := time.Now()
+	expiring, err := s.repo.ListExpiring(ctx, now.AddDate(0, 0, days))
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range expiring {
+		// A score expiring later today still has a day left
+		item.DaysLeft = int(math.Ceil(item.Score.ValidUntil.Sub(now).Hours() / 24))
+	}
+	return expiring, nil
+}
+
+// UpdateScore corrects the raw scores, e.g. after a data entry mistake. The change is
+// kept as a revision; a released score gets a new certificate and the student is told.
+func (s *ScoreService) UpdateScore(ctx context.Context, id int64, req *model.UpdateScore, user *model.AuthUser) (*model.Score, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	correction := model.ScoreCorrection{
+		ListeningCorrect: req.ListeningCorrect,
+		StructureCorrect: req.StructureCorrect,
+		ReadingCorrect:   req.ReadingCorrect,
+	}
+	score, err := s.repo.Update(ctx, id, correction, req.Reason, user.Identifier())
+	if err != nil {
+		return nil, err
+	}
+
+	if score.ReleasedAt != nil {
+		s.notifications.NotifyStudent(ctx, score.StudentID, "Your TOEFL ITP score was corrected",
+			scoreCorrectedBody(score, req.Reason))
+	}
+	return score, nil
+}
+
+// scoreCorrectedBody tells a student their corrected score and that the old
+// certificate no longer verifies
+func scoreCorrectedBody(score *model.Score, reason string) string {
+	return fmt.Sprintf(
+		"Your TOEFL ITP score for test plot %d was corrected.\n\nReason: %s\n\n"+
+			"Listening Comprehension: %d\nStructure and Written Expression: %d\nReading Comprehension: %d\n"+
+			"Total score: %d\n\n"+
+			"A new certificate with a new verification code was issued; the previous certificate is no longer valid.\n",
+		score.TestPlotID, reason,
+		score.ListeningComprehension, score.StructureWrittenExpression, score.ReadingComprehension,
+		score.TotalScore,
+	)
+}
+
+func (s *ScoreService) ListRevisions(ctx context.Context, id int64) ([]*model.ScoreRevision, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRevisions(ctx, id)
+}
+
+// Certificate renders the score certificate of a released score with its current
+// verification code
+func (s *ScoreService) Certificate(ctx context.Context, id int64, user *model.AuthUser) (*pdf.Document, string, error) {
+	score, err := s.GetScore(ctx, id, user)
+	if err != nil {
+		return nil, "", err
+	}
+	if score.ReleasedAt == nil {
+		return nil, "", fmt.Errorf("cannot issue certificate: the score has not been released")
+	}
+
+	certificate, err := s.repo.GetCertificate(ctx, score.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	registration, err := s.registrations.GetByID(ctx, score.RegistrationID)
+	if err != nil {
+		return nil, "", err
+	}
+	participant, err := s.participants.GetByID(ctx, score.StudentID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	url := fmt.Sprintf("%s/api/certificates/%s", s.appURL, certificate.VerificationCode)
+	symbol, err := qr.Encode([]byte(url))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode verification link: %w", err)
+	}
+
+	doc := renderScoreCertificate(score, certificate, registration, participant, symbol, url)
+	return doc, documentFilename("score-certificate", certificate.VerificationCode), nil
+}
+
+// VerifyCertificate lets anyone holding a certificate check it against the records
+func (s *ScoreService) VerifyCertificate(ctx context.Context, code string) (*model.CertificateVerification, error) {
+	return s.repo.VerifyCertificate(ctx, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+func (s *ScoreService) GetRelease(ctx context.Context, scheduleID int64) (*model.ScoreRelease, error) {
+	return s.repo.GetRelease(ctx, scheduleID)
+}
+
+// ReleaseScores makes the draft scores of a schedule visible and emails each student
+// their score. Scores recorded afterwards stay in draft until released again, unless
+// a scheduled release time has already passed.
+func (s *ScoreService) ReleaseScores(ctx context.Context, scheduleID int64, user *model.AuthUser) (*model.ScoreRelease, error) {
+	return s.release(ctx, scheduleID, user.Identifier())
+}
+
+// ScheduleRelease releases the draft scores of a schedule at the given time. The time
+// stays set after it runs, so scores recorded later are released on the next periodic
+// run until the scheduled release is cancelled.
+func (s *ScoreService) ScheduleRelease(ctx context.Context, scheduleID int64, req *model.ScheduleScoreRelease, user *model.AuthUser) (*model.ScoreRelease, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+	if !req.ReleaseAt.After(time.Now()) {
+		return nil, fmt.Errorf("invalid release time: must be in the future")
+	}
+	return s.repo.ScheduleRelease(ctx, scheduleID, req.ReleaseAt, user.Identifier())
+}
+
+func (s *ScoreService) CancelScheduledRelease(ctx context.Context, scheduleID int64) error {
+	return s.repo.CancelScheduledRelease(ctx, scheduleID)
+}
+
+// ReleaseDue is run periodically to release scores whose scheduled time has passed
+func (s *ScoreService) ReleaseDue(ctx context.Context) error {
+	ids, err := s.repo.ListDueReleases(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		release, err := s.release(ctx, id, "system")
+		if err != nil {
+			log.Printf("failed to release scores of schedule %d: %v", id, err)
+			continue
+		}
+		log.Printf("released scores of schedule %d, %d released in total", id, release.Released)
+	}
+	return nil
+}
+
+func (s *ScoreService) release(ctx context.Context, scheduleID int64, releasedBy string) (*model.ScoreRelease, error) {
+	release, scores, err := s.repo.Release(ctx, scheduleID, releasedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, score := range scores {
+		body := fmt.Sprintf(
+			"Your TOEFL ITP score for the test on %s at %s is now available.\n\n"+
+				"Listening Comprehension: %d\nStructure and Written Expression: %d\nReading Comprehension: %d\n"+
+				"Total score: %d\n",
+			release.DateTime.Format(notificationTimeLayout), release.Location,
+			score.ListeningComprehension, score.StructureWrittenExpression, score.ReadingComprehension,
+			score.TotalScore,
+		)
+		s.notifications.NotifyStudent(ctx, score.StudentID, "Your TOEFL ITP score is available", body)
+	}
+
+	return release, nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+type ScoreAppealService struct {
+	repo          *repository.ScoreAppealRepository
+	scores        *ScoreService
+	notifications *NotificationService
+}
+
+func NewScoreAppealService(repo *repository.ScoreAppealRepository, scores *ScoreService, notifications *NotificationService) *ScoreAppealService {
+	return &ScoreAppealService{repo: repo, scores: scores, notifications: notifications}
+}
+
+// SubmitAppeal lets a student dispute one of their released scores
+func (s *ScoreAppealService) SubmitAppeal(ctx context.Context, scoreID int64, req *model.CreateScoreAppeal, user *model.AuthUser) (*model.ScoreAppeal, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	score, err := s.scores.GetScore(ctx, scoreID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	appeal := &model.ScoreAppeal{ScoreID: score.ID, Reason: req.Reason}
+	if err := s.repo.Create(ctx, appeal); err != nil {
+		return nil, err
+	}
+
+	s.notifications.NotifyAdmins(
+		fmt.Sprintf("Score appeal for TOEFL ITP plot %d", score.TestPlotID),
+		fmt.Sprintf("Participant %d appealed score %d (total %d).\n\nReason: %s\n", score.StudentID, score.ID, score.TotalScore, appeal.Reason),
+	)
+	return appeal, nil
+}
+
+// ListAppeals returns the appeals with the given status, or every appeal when it is empty
+func (s *ScoreAppealService) ListAppeals(ctx context.Context, status string) ([]*model.ScoreAppeal, error) {
+	switch status {
+	case "", model.AppealStatusPending, model.AppealStatusAccepted, model.AppealStatusRejected:
+	default:
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+	return s.repo.List(ctx, status, 0)
+}
+
+func (s *ScoreAppealService) ListParticipantAppeals(ctx context.Context, participantID int64, user *model.AuthUser) ([]*model.ScoreAppeal, error) {
+	if err := authorizeParticipant(participantID, user); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, "", participantID)
+}
+
+func (s *ScoreAppealService) GetAppeal(ctx context.Context, id int64, user *model.AuthUser) (*model.ScoreAppeal, error) {
+	appeal, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeParticipant(appeal.StudentID, user); err != nil {
+		return nil, fmt.Errorf("appeal not found")
+	}
+	return appeal, nil
+}
+
+// ReviewAppeal accepts an appeal by re-scoring with the corrected raw scores, or
+// rejects it, and tells the student the outcome
+func (s *ScoreAppealService) ReviewAppeal(ctx context.Context, id int64, req *model.ReviewScoreAppeal, user *model.AuthUser) (*model.ScoreAppeal, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	appeal, score, err := s.repo.Review(ctx, id, req, user.Identifier())
+	if err != nil {
+		return nil, err
+	}
+
+	if appeal.Status == model.AppealStatusAccepted {
+		s.notifications.NotifyStudent(ctx, score.StudentID, "Your TOEFL ITP score appeal was accepted",
+			scoreCorrectedBody(score, appeal.ReviewNote))
+	} else {
+		s.notifications.NotifyStudent(ctx, score.StudentID, "Your TOEFL ITP score appeal was rejected",
+			fmt.Sprintf("Your appeal of your TOEFL ITP score for test plot %d was reviewed and the score stands at %d.\n\nReviewer's note: %s\n",
+				score.TestPlotID, score.TotalScore, appeal.ReviewNote))
+	}
+	return appeal, nil
+}
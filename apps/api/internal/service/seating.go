@@ -0,0 +1,221 @@
+package service
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/pdf"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+type SeatingService struct {
+	repo     *repository.SeatingRepository
+	proctors *repository.ProctorRepository
+}
+
+func NewSeatingService(repo *repository.SeatingRepository, proctors *repository.ProctorRepository) *SeatingService {
+	return &SeatingService{repo: repo, proctors: proctors}
+}
+
+// arrangeSeats orders registrations by registration time or at random, then fills
+// the seats row by row. Each seat goes to a participant whose major differs from the
+// neighbours on the left and in front, preferring the major with the most people
+// still to seat and then the order. When nobody fits the next in order is seated.
+func arrangeSeats(plan *model.SeatingPlan, seats []*model.Seat) []*model.Seat {
+	order := slices.Clone(seats)
+	if plan.Mode == model.SeatingRandom {
+		rand.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	} else {
+		slices.SortFunc(order, func(a, b *model.Seat) int { return cmp.Compare(a.RegistrationID, b.RegistrationID) })
+	}
+
+	conflicts := func(arranged []*model.Seat, major string) bool {
+		if major == "" {
+			return false
+		}
+		pos := len(arranged)
+		if pos%plan.SeatsPerRow != 0 && arranged[pos-1].Major == major {
+			return true
+		}
+		return pos >= plan.SeatsPerRow && arranged[pos-plan.SeatsPerRow].Major == major
+	}
+
+	remaining := map[string]int{}
+	for _, seat := range order {
+		remaining[seat.Major]++
+	}
+
+	arranged := make([]*model.Seat, 0, len(order))
+	for len(order) > 0 {
+		pick := -1
+		for i, seat := range order {
+			if conflicts(arranged, seat.Major) {
+				continue
+			}
+			// Seat the largest majors first so they are not left over for the last rows
+			if pick < 0 || (seat.Major != "" && remaining[seat.Major] > remaining[order[pick].Major]) {
+				pick = i
+			}
+		}
+		if pick < 0 {
+			pick = 0
+		}
+		remaining[order[pick].Major]--
+		arranged = append(arranged, order[pick])
+		order = slices.Delete(order, pick, pick+1)
+	}
+	return arranged
+}
+
+func (s *SeatingService) GetPlan(ctx context.Context, scheduleID int64, user *model.AuthUser) (*model.SeatingPlan, error) {
+	if err := authorizeSession(ctx, s.proctors, scheduleID, user); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPlan(ctx, scheduleID)
+}
+
+// ConfigureSeating changes the seating mode and room width of a schedule. Seats that
+// were already assigned stay until they are assigned again.
+func (s *SeatingService) ConfigureSeating(ctx context.Context, scheduleID int64, req *model.UpdateSeating) (*model.SeatingPlan, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetPlan(ctx, scheduleID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Configure(ctx, scheduleID, req.Mode, req.SeatsPerRow); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPlan(ctx, scheduleID)
+}
+
+// AssignSeats (re)assigns every seat of a schedule, e.g. after late registrations
+func (s *SeatingService) AssignSeats(ctx context.Context, scheduleID int64, user *model.AuthUser) (*model.SeatingPlan, error) {
+	return s.repo.Assign(ctx, scheduleID, arrangeSeats, user.Identifier())
+}
+
+// AssignDue is run periodically to seat schedules whose registration has closed, and
+// to give registrations that came in after that the next free seats
+func (s *SeatingService) AssignDue(ctx context.Context) error {
+	ids, err := s.repo.ListDue(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		plan, err := s.repo.Assign(ctx, id, arrangeSeats, "system")
+		if err != nil {
+			log.Printf("failed to assign seats of schedule %d: %v", id, err)
+			continue
+		}
+		log.Printf("assigned %d seats of schedule %d", len(plan.Seats), id)
+	}
+
+	ids, err = s.repo.ListLate(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		seated, err := s.repo.SeatLate(ctx, id)
+		if err != nil {
+			log.Printf("failed to seat late registrations of schedule %d: %v", id, err)
+			continue
+		}
+		log.Printf("seated %d late registrations of schedule %d", seated, id)
+	}
+	return nil
+}
+
+// SeatingChart renders the room layout proctors use to seat participants
+func (s *SeatingService) SeatingChart(ctx context.Context, scheduleID int64, user *model.AuthUser) (*pdf.Document, string, error) {
+	if err := authorizeSession(ctx, s.proctors, scheduleID, user); err != nil {
+		return nil, "", err
+	}
+	plan, err := s.repo.GetPlan(ctx, scheduleID)
+	if err != nil {
+		return nil, "", err
+	}
+	return renderSeatingChart(plan), fmt.Sprintf("seating-chart-plot-%d.pdf", plan.PlotID), nil
+}
+
+// DoorList renders the alphabetical list checked and signed at the door
+func (s *SeatingService) DoorList(ctx context.Context, scheduleID int64, user *model.AuthUser) (*pdf.Document, string, error) {
+	if err := authorizeSession(ctx, s.proctors, scheduleID, user); err != nil {
+		return nil, "", err
+	}
+	plan, err := s.repo.GetPlan(ctx, scheduleID)
+	if err != nil {
+		return nil, "", err
+	}
+	return renderDoorList(plan, byName(plan.Seats)), fmt.Sprintf("door-list-plot-%d.pdf", plan.PlotID), nil
+}
+
+// SeatingChartCSV lists the seats in seat order
+func (s *SeatingService) SeatingChartCSV(ctx context.Context, scheduleID int64, user *model.AuthUser) ([]byte, string, error) {
+	if err := authorizeSession(ctx, s.proctors, scheduleID, user); err != nil {
+		return nil, "", err
+	}
+	plan, err := s.repo.GetPlan(ctx, scheduleID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	records := [][]string{{"seat", "label", "row", "position", "reg_number", "full_name", "identity", "major", "status"}}
+	for _, seat := range plan.Seats {
+		var number, row, position string
+		if seat.Number > 0 {
+			number = strconv.Itoa(seat.Number)
+			row = strings.TrimRight(seat.Label, "0123456789")
+			position = strconv.Itoa((seat.Number-1)%plan.SeatsPerRow + 1)
+		}
+		records = append(records, []string{number, seat.Label, row, position, seat.RegNumber, seat.FullName, seat.Identity, seat.Major, seat.Status})
+	}
+
+	data, err := writeCSV(records)
+	return data, fmt.Sprintf("seating-chart-plot-%d.csv", plan.PlotID), err
+}
+
+// DoorListCSV lists the participants by name
+func (s *SeatingService) DoorListCSV(ctx context.Context, scheduleID int64, user *model.AuthUser) ([]byte, string, error) {
+	if err := authorizeSession(ctx, s.proctors, scheduleID, user); err != nil {
+		return nil, "", err
+	}
+	plan, err := s.repo.GetPlan(ctx, scheduleID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	records := [][]string{{"full_name", "reg_number", "identity", "seat", "status", "attendance"}}
+	for _, seat := range byName(plan.Seats) {
+		records = append(records, []string{seat.FullName, seat.RegNumber, seat.Identity, seat.Label, seat.Status, seat.Attendance})
+	}
+
+	data, err := writeCSV(records)
+	return data, fmt.Sprintf("door-list-plot-%d.csv", plan.PlotID), err
+}
+
+func byName(seats []*model.Seat) []*model.Seat {
+	sorted := slices.Clone(seats)
+	slices.SortStableFunc(sorted, func(a, b *model.Seat) int {
+		return strings.Compare(strings.ToLower(a.FullName), strings.ToLower(b.FullName))
+	})
+	return sorted
+}
+
+func writeCSV(records [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+type TestFormService struct {
+	repo *repository.TestFormRepository
+}
+
+func NewTestFormService(repo *repository.TestFormRepository) *TestFormService {
+	return &TestFormService{repo: repo}
+}
+
+func (s *TestFormService) CreateTestForm(ctx context.Context, req *model.CreateTestForm) (*model.TestForm, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	form := &model.TestForm{Code: strings.ToUpper(req.Code), Name: req.Name}
+	if err := s.repo.Create(ctx, form); err != nil {
+		return nil, err
+	}
+
+	return form, nil
+}
+
+func (s *TestFormService) ListTestForms(ctx context.Context) ([]*model.TestForm, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *TestFormService) UpdateTestForm(ctx context.Context, code string, req *model.UpdateTestForm) (*model.TestForm, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	form, err := s.repo.GetByCode(ctx, strings.ToUpper(code))
+	if err != nil {
+		return nil, err
+	}
+
+	form.Name = req.Name
+	form.Active = req.Active
+	if err := s.repo.Update(ctx, form); err != nil {
+		return nil, err
+	}
+
+	return form, nil
+}
+
+func (s *TestFormService) GetConversion(ctx context.Context, code string) (*model.ConversionTable, error) {
+	return s.repo.GetConversion(ctx, strings.ToUpper(code))
+}
+
+// SaveConversion uploads the conversion table published for a form. It can be
+// corrected until the first score is scaled with it.
+func (s *TestFormService) SaveConversion(ctx context.Context, code string, table *model.ConversionTable) (*model.ConversionTable, error) {
+	if err := validator.New().Struct(table); err != nil {
+		return nil, fmt.Errorf("invalid conversion table: %w", err)
+	}
+	if err := table.Check(); err != nil {
+		return nil, err
+	}
+
+	table.FormCode = strings.ToUpper(code)
+	if err := s.repo.SaveConversion(ctx, table); err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
+
+func (s *TestFormService) GetSessionForms(ctx context.Context, scheduleID int64) (*model.SessionTestForms, error) {
+	return s.repo.GetSessionForms(ctx, scheduleID)
+}
+
+// AssignSessionForm records the form used at a session, giving participants who took
+// it at their previous attempt the alternate form
+func (s *TestFormService) AssignSessionForm(ctx context.Context, scheduleID int64, req *model.AssignTestForm, user *model.AuthUser) (*model.SessionTestForms, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	formCode := strings.ToUpper(req.FormCode)
+	alternateCode := strings.ToUpper(req.AlternateFormCode)
+	if formCode == alternateCode {
+		return nil, fmt.Errorf("invalid test form: the alternate form must differ from the session's form")
+	}
+
+	return s.repo.AssignSessionForm(ctx, scheduleID, formCode, alternateCode, user.Identifier())
+}
+
+// AssignSeatForm gives one registration its own form, e.g. a spare booklet handed out
+// on the day; an empty code falls back to the session's form
+func (s *TestFormService) AssignSeatForm(ctx context.Context, registrationID int64, req *model.AssignSeatTestForm, user *model.AuthUser) (*model.Registration, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+	return s.repo.AssignSeatForm(ctx, registrationID, strings.ToUpper(req.FormCode), user.Identifier())
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+type VoucherService struct {
+	repo *repository.VoucherRepository
+}
+
+func NewVoucherService(repo *repository.VoucherRepository) *VoucherService {
+	return &VoucherService{repo: repo}
+}
+
+func (s *VoucherService) CreateVoucher(ctx context.Context, req *model.CreateVoucher) (*model.Voucher, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+	if req.DiscountType == model.DiscountPercentage && req.DiscountValue > 100 {
+		return nil, fmt.Errorf("invalid voucher: a percentage discount cannot exceed 100")
+	}
+	if req.ValidFrom != nil && req.ValidUntil != nil && !req.ValidFrom.Before(*req.ValidUntil) {
+		return nil, fmt.Errorf("invalid voucher: valid_from must be before valid_until")
+	}
+
+	voucher := &model.Voucher{
+		Code:          strings.ToUpper(req.Code),
+		Description:   req.Description,
+		DiscountType:  req.DiscountType,
+		DiscountValue: req.DiscountValue,
+		MaxUses:       req.MaxUses,
+		ValidFrom:     req.ValidFrom,
+		ValidUntil:    req.ValidUntil,
+		FeeTiers:      req.FeeTiers,
+	}
+	if voucher.FeeTiers == nil {
+		voucher.FeeTiers = []string{}
+	}
+
+	if err := s.repo.Create(ctx, voucher); err != nil {
+		return nil, err
+	}
+
+	return voucher, nil
+}
+
+func (s *VoucherService) ListVouchers(ctx context.Context) ([]*model.Voucher, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *VoucherService) UpdateVoucher(ctx context.Context, id int64, req *model.UpdateVoucher) (*model.Voucher, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+	if req.ValidFrom != nil && req.ValidUntil != nil && !req.ValidFrom.Before(*req.ValidUntil) {
+		return nil, fmt.Errorf("invalid voucher: valid_from must be before valid_until")
+	}
+
+	voucher, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.MaxUses != nil && *req.MaxUses < voucher.UsedCount {
+		return nil, fmt.Errorf("invalid voucher: max_uses cannot be below the %d uses so far", voucher.UsedCount)
+	}
+
+	voucher.Description = req.Description
+	voucher.MaxUses = req.MaxUses
+	voucher.ValidFrom = req.ValidFrom
+	voucher.ValidUntil = req.ValidUntil
+	voucher.FeeTiers = req.FeeTiers
+	voucher.Active = req.Active
+	if voucher.FeeTiers == nil {
+		voucher.FeeTiers = []string{}
+	}
+
+	if err := s.repo.Update(ctx, voucher); err != nil {
+		return nil, err
+	}
+
+	return voucher, nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/model"
+	"github.com/jatifjr/app-unw-toefl/apps/api/internal/repository"
+	"github.com/jatifjr/app-unw-toefl/apps/api/pkg/validator"
+)
+
+// notificationTimeLayout formats deadlines and test times in notifications
+const notificationTimeLayout = "Monday, 2 January 2006 15:04 MST"
+
+type WaitlistService struct {
+	repo          *repository.WaitlistRepository
+	eligibility   *EligibilityService
+	notifications *NotificationService
+	policy        RegistrationPolicy
+}
+
+func NewWaitlistService(repo *repository.WaitlistRepository, eligibility *EligibilityService, notifications *NotificationService, policy RegistrationPolicy) *WaitlistService {
+	return &WaitlistService{repo: repo, eligibility: eligibility, notifications: notifications, policy: policy}
+}
+
+// authorizeEntry allows admins to act on any entry and students only on their own
+func authorizeEntry(entry *model.WaitlistEntry, user *model.AuthUser) error {
+	if user.HasRole(model.RoleAdmin, model.RoleSuperAdmin) {
+		return nil
+	}
+	if user.HasRole(model.RoleStudent) && user.ID == entry.StudentID {
+		return nil
+	}
+	return fmt.Errorf("waitlist entry not found")
+}
+
+func (s *WaitlistService) JoinWaitlist(ctx context.Context, scheduleID int64, req *model.JoinWaitlist, user *model.AuthUser) (*model.WaitlistEntry, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	studentID := user.ID
+	if user.HasRole(model.RoleAdmin, model.RoleSuperAdmin) {
+		if req.StudentID == 0 {
+			return nil, fmt.Errorf("student_id is required")
+		}
+		studentID = req.StudentID
+	}
+
+	entry, err := s.repo.Join(ctx, scheduleID, studentID, registrationWindowCheck("join waitlist"))
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifications.NotifyStudent(ctx, entry.StudentID,
+		fmt.Sprintf("You are on the waitlist for TOEFL ITP plot %d", entry.PlotID),
+		fmt.Sprintf("You are number %d on the waitlist for the test on %s. We will notify you as soon as a seat opens up.",
+			entry.Position, entry.DateTime.Format(notificationTimeLayout)))
+
+	return entry, nil
+}
+
+func (s *WaitlistService) ListBySchedule(ctx context.Context, scheduleID int64) ([]*model.WaitlistEntry, error) {
+	return s.repo.ListBySchedule(ctx, scheduleID)
+}
+
+func (s *WaitlistService) ListMine(ctx context.Context, user *model.AuthUser) ([]*model.WaitlistEntry, error) {
+	return s.repo.ListByStudent(ctx, user.ID)
+}
+
+func (s *WaitlistService) LeaveWaitlist(ctx context.Context, id int64, user *model.AuthUser) error {
+	entry, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorizeEntry(entry, user); err != nil {
+		return err
+	}
+
+	next, err := s.repo.Leave(ctx, id, s.policy.ClaimWindow)
+	if err != nil {
+		return err
+	}
+	s.NotifyOffer(ctx, next)
+
+	return nil
+}
+
+// ClaimSeat registers a student whose waitlist entry was offered a seat. A student who
+// fails the eligibility rules gives up the offer.
+func (s *WaitlistService) ClaimSeat(ctx context.Context, id int64, user *model.AuthUser) (*model.Registration, error) {
+	entry, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeEntry(entry, user); err != nil {
+		return nil, err
+	}
+
+	eligible, err := s.eligibility.Check(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	registration, next, err := s.repo.Claim(ctx, id, eligible, s.policy.PaymentDeadline, s.policy.ClaimWindow, user.Identifier())
+	if err != nil {
+		// An ineligible claimant's seat has already been offered to the next student
+		s.NotifyOffer(ctx, next)
+		return nil, err
+	}
+
+	s.notifications.NotifyStudent(ctx, registration.StudentID,
+		fmt.Sprintf("Registration %s confirmed", registration.RegNumber),
+		fmt.Sprintf("You claimed your seat for TOEFL ITP plot %d on %s. Please complete the payment to secure it.",
+			registration.TestPlotID, registration.TestDate.Format(notificationTimeLayout)))
+
+	return registration, nil
+}
+
+// ExpireOffers is run periodically to pass lapsed offers on to the next student
+func (s *WaitlistService) ExpireOffers(ctx context.Context) error {
+	results, err := s.repo.ExpireOffers(ctx, s.policy.ClaimWindow)
+	for _, result := range results {
+		s.notifications.NotifyStudent(ctx, result.Expired.StudentID,
+			fmt.Sprintf("Your seat offer for plot %d has expired", result.Expired.PlotID),
+			"The seat held for you was not claimed in time and has been offered to the next student on the waitlist.")
+		s.NotifyOffer(ctx, result.Next)
+	}
+	return err
+}
+
+// NotifyOffer tells a promoted student that a seat is held for them
+func (s *WaitlistService) NotifyOffer(ctx context.Context, entry *model.WaitlistEntry) {
+	if entry == nil || entry.OfferExpiresAt == nil {
+		return
+	}
+
+	s.notifications.NotifyStudent(ctx, entry.StudentID,
+		fmt.Sprintf("A seat opened up for TOEFL ITP plot %d", entry.PlotID),
+		fmt.Sprintf("A seat for the test on %s is being held for you. Claim it before %s or it will be offered to the next student.",
+			entry.DateTime.Format(notificationTimeLayout), entry.OfferExpiresAt.Format(notificationTimeLayout)))
+}
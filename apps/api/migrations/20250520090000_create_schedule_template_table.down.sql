@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_schedules_template_date_time;
+ALTER TABLE schedules DROP COLUMN IF EXISTS template_id;
+DROP TABLE IF EXISTS schedule_templates;
@@ -0,0 +1,25 @@
+-- Create the schedule_templates table
+CREATE TABLE IF NOT EXISTS schedule_templates (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    location VARCHAR(255) NOT NULL,
+    quota INTEGER NOT NULL CHECK (quota > 0),
+    weekdays INTEGER[] NOT NULL,
+    times TEXT[] NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta',
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    excluded_dates DATE[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+-- Link generated schedules back to their template
+ALTER TABLE schedules
+    ADD COLUMN template_id BIGINT REFERENCES schedule_templates(id) ON DELETE SET NULL;
+
+-- A template never generates the same session twice
+CREATE UNIQUE INDEX IF NOT EXISTS idx_schedules_template_date_time
+    ON schedules (template_id, date_time)
+    WHERE template_id IS NOT NULL;
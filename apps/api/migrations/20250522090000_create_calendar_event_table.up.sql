@@ -0,0 +1,21 @@
+-- Create the calendar_events table for holidays and campus blackout dates
+CREATE TABLE IF NOT EXISTS calendar_events (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(32) NOT NULL CHECK (type IN ('national_holiday', 'campus_blackout')),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    source VARCHAR(16) NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'ics')),
+    external_uid VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+-- Re-importing an ICS file updates events by UID
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_events_external_uid
+    ON calendar_events (external_uid)
+    WHERE external_uid IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_calendar_events_dates
+    ON calendar_events (start_date, end_date);
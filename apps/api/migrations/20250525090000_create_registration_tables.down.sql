@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS registration_histories;
+DROP TABLE IF EXISTS registrations;
+DROP TABLE IF EXISTS students;
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS schedules_plot_id_key;
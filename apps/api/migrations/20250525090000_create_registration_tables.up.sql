@@ -0,0 +1,47 @@
+-- Plot IDs identify a test session across registrations and scores
+ALTER TABLE schedules
+    ADD CONSTRAINT schedules_plot_id_key UNIQUE (plot_id);
+
+-- Create the students table
+CREATE TABLE IF NOT EXISTS students (
+    id BIGSERIAL PRIMARY KEY,
+    student_number VARCHAR(20) NOT NULL UNIQUE,
+    full_name VARCHAR(100) NOT NULL,
+    phone VARCHAR(15) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    major VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create the registrations table
+CREATE TABLE IF NOT EXISTS registrations (
+    id BIGSERIAL PRIMARY KEY,
+    reg_number VARCHAR(32) NOT NULL UNIQUE,
+    student_id BIGINT NOT NULL REFERENCES students(id),
+    test_plot_id BIGINT NOT NULL REFERENCES schedules(plot_id) ON UPDATE CASCADE,
+    payment_id BIGINT,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    test_date TIMESTAMP WITH TIME ZONE,
+    test_location VARCHAR(255),
+    notes TEXT,
+    approved_at TIMESTAMP WITH TIME ZONE,
+    approved_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_registrations_student_id ON registrations (student_id);
+CREATE INDEX IF NOT EXISTS idx_registrations_test_plot_id ON registrations (test_plot_id);
+
+-- Create the registration_histories table
+CREATE TABLE IF NOT EXISTS registration_histories (
+    id BIGSERIAL PRIMARY KEY,
+    registration_id BIGINT NOT NULL REFERENCES registrations(id) ON DELETE CASCADE,
+    status VARCHAR(32) NOT NULL,
+    notes TEXT,
+    changed_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_registration_histories_registration_id ON registration_histories (registration_id);
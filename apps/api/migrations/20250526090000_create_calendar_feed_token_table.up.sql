@@ -0,0 +1,14 @@
+-- Create the calendar_feed_tokens table for personal iCalendar subscriptions
+CREATE TABLE IF NOT EXISTS calendar_feed_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    owner_type VARCHAR(16) NOT NULL CHECK (owner_type IN ('student')),
+    owner_id BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- One active feed per owner; rotating revokes the previous token
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_feed_tokens_owner
+    ON calendar_feed_tokens (owner_type, owner_id)
+    WHERE revoked_at IS NULL;
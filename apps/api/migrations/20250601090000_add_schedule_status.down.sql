@@ -0,0 +1,5 @@
+ALTER TABLE registrations DROP COLUMN IF EXISTS refund_required;
+ALTER TABLE schedules
+    DROP COLUMN IF EXISTS cancellation_reason,
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,9 @@
+-- Schedules can be cancelled instead of deleted once students have registered
+ALTER TABLE schedules
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'cancelled')),
+    ADD COLUMN cancelled_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN cancellation_reason TEXT;
+
+-- Registrations cancelled by the language center are owed a refund
+ALTER TABLE registrations
+    ADD COLUMN refund_required BOOLEAN NOT NULL DEFAULT FALSE;
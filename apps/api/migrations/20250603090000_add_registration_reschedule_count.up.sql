@@ -0,0 +1,3 @@
+-- Track how often a student has moved their registration to another session
+ALTER TABLE registrations
+    ADD COLUMN reschedule_count INTEGER NOT NULL DEFAULT 0 CHECK (reschedule_count >= 0);
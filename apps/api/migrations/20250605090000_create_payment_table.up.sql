@@ -0,0 +1,39 @@
+-- Create the payments table
+CREATE TABLE IF NOT EXISTS payments (
+    id BIGSERIAL PRIMARY KEY,
+    registration_id BIGINT NOT NULL REFERENCES registrations(id),
+    amount NUMERIC(12, 2) NOT NULL CHECK (amount >= 0),
+    payment_method VARCHAR(32) NOT NULL,
+    payment_status VARCHAR(32) NOT NULL DEFAULT 'pending',
+
+    -- Common fields
+    receipt_image TEXT,
+    notes TEXT,
+    expired_at TIMESTAMP WITH TIME ZONE,
+    paid_at TIMESTAMP WITH TIME ZONE,
+    verified_by VARCHAR(100),
+    verified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Bank Transfer specific fields
+    bank_name VARCHAR(100),
+    account_number VARCHAR(50),
+    account_name VARCHAR(100),
+    transfer_date TIMESTAMP WITH TIME ZONE,
+
+    -- Virtual Account specific fields (kept for future use)
+    virtual_account_number VARCHAR(50),
+    bank_code VARCHAR(16),
+    va_expired_at TIMESTAMP WITH TIME ZONE,
+
+    -- Payment Gateway specific fields (kept for future use)
+    gateway_transaction_id VARCHAR(100),
+    gateway_name VARCHAR(50),
+    gateway_response JSONB,
+    gateway_redirect_url TEXT,
+    gateway_callback_url TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_registration_id ON payments (registration_id);
+CREATE INDEX IF NOT EXISTS idx_payments_pending_expiry ON payments (expired_at) WHERE payment_status = 'pending';
@@ -0,0 +1,35 @@
+-- Create the waitlist_entries table
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    student_id BIGINT NOT NULL REFERENCES students(id),
+    sequence INTEGER NOT NULL, -- Join order within the schedule
+    status VARCHAR(16) NOT NULL DEFAULT 'waiting' CHECK (status IN ('waiting', 'offered', 'claimed', 'expired', 'left')),
+    offered_at TIMESTAMP WITH TIME ZONE,
+    offer_expires_at TIMESTAMP WITH TIME ZONE,
+    registration_id BIGINT REFERENCES registrations(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (schedule_id, sequence)
+);
+
+-- A student holds at most one open place per schedule
+CREATE UNIQUE INDEX IF NOT EXISTS idx_waitlist_entries_open
+    ON waitlist_entries (schedule_id, student_id)
+    WHERE status IN ('waiting', 'offered');
+
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_offer_expiry
+    ON waitlist_entries (offer_expires_at)
+    WHERE status = 'offered';
+
+-- Create the notifications table for in-app messages
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    student_id BIGINT NOT NULL REFERENCES students(id),
+    subject VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_student_id ON notifications (student_id, created_at DESC);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_schedules_registration_pending_close;
+ALTER TABLE schedules
+    DROP CONSTRAINT IF EXISTS schedules_registration_window_check,
+    DROP COLUMN IF EXISTS registration_closed_at,
+    DROP COLUMN IF EXISTS registration_closes_at,
+    DROP COLUMN IF EXISTS registration_opens_at;
@@ -0,0 +1,14 @@
+-- Optional registration window; without a closing time registration stays open until the test starts
+ALTER TABLE schedules
+    ADD COLUMN registration_opens_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN registration_closes_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN registration_closed_at TIMESTAMP WITH TIME ZONE,
+    ADD CONSTRAINT schedules_registration_window_check
+        CHECK (registration_opens_at IS NULL OR registration_closes_at IS NULL OR registration_opens_at < registration_closes_at);
+
+-- Windows of past sessions are already closed; admins do not need a summary for them
+UPDATE schedules SET registration_closed_at = date_time WHERE date_time <= CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_schedules_registration_pending_close
+    ON schedules (COALESCE(registration_closes_at, date_time))
+    WHERE registration_closed_at IS NULL AND status = 'active';
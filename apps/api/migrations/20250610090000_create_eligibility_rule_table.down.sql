@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS eligibility_rules;
+ALTER TABLE schedules DROP COLUMN IF EXISTS subsidized;
+ALTER TABLE students DROP COLUMN IF EXISTS active;
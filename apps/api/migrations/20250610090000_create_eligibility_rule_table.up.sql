@@ -0,0 +1,25 @@
+-- Only active students may take subsidized sessions
+ALTER TABLE students
+    ADD COLUMN active BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE schedules
+    ADD COLUMN subsidized BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Create the eligibility_rules table
+CREATE TABLE IF NOT EXISTS eligibility_rules (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(64) NOT NULL UNIQUE,
+    type VARCHAR(32) NOT NULL CHECK (type IN ('one_active_registration', 'retake_interval', 'subsidized_majors', 'max_attempts_per_year')),
+    params JSONB NOT NULL DEFAULT '{}',
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Default policy
+INSERT INTO eligibility_rules (code, type, params, description) VALUES
+    ('one_active_registration', 'one_active_registration', '{}', 'A student may hold only one upcoming registration'),
+    ('retake_interval', 'retake_interval', '{"days": 30}', 'No retake within 30 days of another test'),
+    ('subsidized_majors', 'subsidized_majors', '{"majors": []}', 'Subsidized sessions are for active students of the listed majors; an empty list allows every major'),
+    ('max_attempts_per_year', 'max_attempts_per_year', '{"max_attempts": 3}', 'At most 3 tests per calendar year');
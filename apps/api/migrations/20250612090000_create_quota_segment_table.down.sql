@@ -0,0 +1,3 @@
+ALTER TABLE waitlist_entries DROP COLUMN IF EXISTS segment_id;
+ALTER TABLE registrations DROP COLUMN IF EXISTS segment_id;
+DROP TABLE IF EXISTS schedule_quota_segments;
@@ -0,0 +1,22 @@
+-- Seats of a schedule reserved for one major or participant type. Seats not covered
+-- by a segment are open to everyone; schedules.available remains the total.
+CREATE TABLE IF NOT EXISTS schedule_quota_segments (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(32) NOT NULL CHECK (kind IN ('major', 'participant_type')),
+    value VARCHAR(100) NOT NULL,
+    quota INTEGER NOT NULL CHECK (quota > 0),
+    available INTEGER NOT NULL CHECK (available >= 0 AND available <= quota),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (schedule_id, name)
+);
+
+-- The segment a seat was taken from; NULL for unreserved seats
+ALTER TABLE registrations
+    ADD COLUMN segment_id BIGINT REFERENCES schedule_quota_segments(id) ON DELETE SET NULL;
+
+-- The segment of the seat held for an offered waitlist entry
+ALTER TABLE waitlist_entries
+    ADD COLUMN segment_id BIGINT REFERENCES schedule_quota_segments(id) ON DELETE SET NULL;
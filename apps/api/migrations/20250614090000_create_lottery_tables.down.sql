@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS lottery_draws;
+DROP TABLE IF EXISTS lottery_applications;
+ALTER TABLE schedules DROP COLUMN IF EXISTS allocation_mode;
@@ -0,0 +1,34 @@
+-- Seats of a lottery schedule are drawn when its registration window closes
+ALTER TABLE schedules
+    ADD COLUMN allocation_mode VARCHAR(16) NOT NULL DEFAULT 'fcfs' CHECK (allocation_mode IN ('fcfs', 'lottery'));
+
+-- Create the lottery_applications table
+CREATE TABLE IF NOT EXISTS lottery_applications (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    student_id BIGINT NOT NULL REFERENCES students(id),
+    status VARCHAR(16) NOT NULL DEFAULT 'applied' CHECK (status IN ('applied', 'won', 'waitlisted', 'withdrawn')),
+    draw_rank INTEGER,
+    registration_id BIGINT REFERENCES registrations(id),
+    waitlist_entry_id BIGINT REFERENCES waitlist_entries(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_lottery_applications_open
+    ON lottery_applications (schedule_id, student_id) WHERE status <> 'withdrawn';
+CREATE INDEX IF NOT EXISTS idx_lottery_applications_student_id ON lottery_applications (student_id);
+
+-- One draw per schedule; seed and ordered results are kept for auditing
+CREATE TABLE IF NOT EXISTS lottery_draws (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT NOT NULL UNIQUE REFERENCES schedules(id) ON DELETE CASCADE,
+    seed VARCHAR(64) NOT NULL,
+    algorithm VARCHAR(64) NOT NULL,
+    applicants INTEGER NOT NULL,
+    winners INTEGER NOT NULL,
+    waitlisted INTEGER NOT NULL,
+    results JSONB NOT NULL,
+    drawn_by VARCHAR(100) NOT NULL,
+    drawn_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
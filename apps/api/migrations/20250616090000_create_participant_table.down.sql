@@ -0,0 +1,37 @@
+-- External participants cannot be represented as students; their records go first so
+-- the foreign keys into participants do not block the delete
+DELETE FROM lottery_applications
+WHERE student_id IN (SELECT id FROM participants WHERE participant_type = 'external');
+DELETE FROM waitlist_entries
+WHERE student_id IN (SELECT id FROM participants WHERE participant_type = 'external');
+DELETE FROM payments
+WHERE registration_id IN (
+    SELECT r.id FROM registrations r
+    JOIN participants p ON p.id = r.student_id
+    WHERE p.participant_type = 'external'
+);
+DELETE FROM registrations
+WHERE student_id IN (SELECT id FROM participants WHERE participant_type = 'external');
+DELETE FROM notifications
+WHERE student_id IN (SELECT id FROM participants WHERE participant_type = 'external');
+DELETE FROM calendar_feed_tokens
+WHERE owner_type = 'student' AND owner_id IN (SELECT id FROM participants WHERE participant_type = 'external');
+DELETE FROM participants WHERE participant_type = 'external';
+
+DROP INDEX IF EXISTS idx_participants_participant_type;
+DROP INDEX IF EXISTS idx_participants_national_id;
+DROP INDEX IF EXISTS idx_participants_student_number;
+
+ALTER TABLE participants
+    DROP CONSTRAINT IF EXISTS participants_identity_check,
+    DROP COLUMN IF EXISTS fee_tier,
+    DROP COLUMN IF EXISTS institution,
+    DROP COLUMN IF EXISTS national_id,
+    DROP COLUMN IF EXISTS participant_type,
+    ALTER COLUMN major DROP DEFAULT,
+    ALTER COLUMN student_number SET NOT NULL,
+    ADD CONSTRAINT students_student_number_key UNIQUE (student_number);
+
+ALTER TABLE participants RENAME TO students;
+
+DROP TABLE IF EXISTS fee_tiers;
@@ -0,0 +1,40 @@
+-- Fee tiers decide what a participant pays and which profile fields they must provide
+CREATE TABLE IF NOT EXISTS fee_tiers (
+    code VARCHAR(32) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    participant_type VARCHAR(16) NOT NULL CHECK (participant_type IN ('student', 'external')),
+    amount NUMERIC(12, 2) NOT NULL CHECK (amount >= 0),
+    required_fields TEXT[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO fee_tiers (code, name, participant_type, amount, required_fields) VALUES
+    ('student', 'UNW student', 'student', 150000, '{student_number,major}'),
+    ('alumni', 'UNW alumni', 'external', 250000, '{national_id,student_number}'),
+    ('public', 'General public', 'external', 350000, '{national_id,institution}');
+
+-- Students and external participants (public, alumni) share one table so registrations,
+-- waitlist entries and scores can reference either; the student_id columns elsewhere
+-- keep their name and now point at a participant.
+ALTER TABLE students RENAME TO participants;
+
+ALTER TABLE participants
+    ADD COLUMN participant_type VARCHAR(16) NOT NULL DEFAULT 'student' CHECK (participant_type IN ('student', 'external')),
+    ADD COLUMN national_id VARCHAR(32),
+    ADD COLUMN institution VARCHAR(255),
+    ADD COLUMN fee_tier VARCHAR(32) NOT NULL DEFAULT 'student' REFERENCES fee_tiers(code) ON UPDATE CASCADE,
+    ALTER COLUMN student_number DROP NOT NULL,
+    ALTER COLUMN major SET DEFAULT '',
+    ADD CONSTRAINT participants_identity_check CHECK (
+        (participant_type = 'student' AND student_number IS NOT NULL)
+        OR (participant_type = 'external' AND national_id IS NOT NULL)
+    );
+
+-- Alumni keep their old student number, so it is only unique among students
+ALTER TABLE participants DROP CONSTRAINT students_student_number_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_participants_student_number
+    ON participants (student_number) WHERE participant_type = 'student';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_participants_national_id ON participants (national_id);
+CREATE INDEX IF NOT EXISTS idx_participants_participant_type ON participants (participant_type);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS group_invoices;
+ALTER TABLE registrations DROP COLUMN IF EXISTS group_registration_id;
+DROP TABLE IF EXISTS group_registrations;
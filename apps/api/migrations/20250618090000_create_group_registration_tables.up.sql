@@ -0,0 +1,33 @@
+-- Create the group_registrations table
+CREATE TABLE IF NOT EXISTS group_registrations (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT NOT NULL REFERENCES schedules(id),
+    coordinator_id BIGINT NOT NULL,
+    created_by VARCHAR(100) NOT NULL,
+    all_or_none BOOLEAN NOT NULL DEFAULT FALSE,
+    requested INTEGER NOT NULL,
+    registered INTEGER NOT NULL,
+    failed INTEGER NOT NULL,
+    notes TEXT,
+    results JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_registrations_schedule_id ON group_registrations (schedule_id);
+CREATE INDEX IF NOT EXISTS idx_group_registrations_coordinator_id ON group_registrations (coordinator_id);
+
+ALTER TABLE registrations
+    ADD COLUMN group_registration_id BIGINT REFERENCES group_registrations(id) ON DELETE SET NULL;
+
+-- One consolidated invoice per group instead of a payment per registration
+CREATE TABLE IF NOT EXISTS group_invoices (
+    id BIGSERIAL PRIMARY KEY,
+    group_registration_id BIGINT NOT NULL UNIQUE REFERENCES group_registrations(id) ON DELETE CASCADE,
+    invoice_number VARCHAR(32) NOT NULL UNIQUE,
+    amount NUMERIC(12, 2) NOT NULL CHECK (amount >= 0),
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'cancelled')),
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    paid_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
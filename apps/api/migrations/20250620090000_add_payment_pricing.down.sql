@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_payments_voucher_id;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS voucher_code,
+    DROP COLUMN IF EXISTS voucher_id,
+    DROP COLUMN IF EXISTS discount_amount,
+    DROP COLUMN IF EXISTS base_amount,
+    DROP COLUMN IF EXISTS schedule_type,
+    DROP COLUMN IF EXISTS fee_tier;
+
+DROP TABLE IF EXISTS vouchers;
+
+ALTER TABLE fee_tiers DROP COLUMN IF EXISTS prices;
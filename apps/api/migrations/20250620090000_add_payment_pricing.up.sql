@@ -0,0 +1,40 @@
+-- Fee tiers can charge a different amount per schedule type; amount is the fallback
+ALTER TABLE fee_tiers
+    ADD COLUMN prices JSONB NOT NULL DEFAULT '{}';
+
+UPDATE fee_tiers SET prices = '{"subsidized": 75000}' WHERE code = 'student';
+
+-- Create the vouchers table
+CREATE TABLE IF NOT EXISTS vouchers (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(32) NOT NULL UNIQUE CHECK (code = UPPER(code)),
+    description VARCHAR(255),
+    discount_type VARCHAR(16) NOT NULL CHECK (discount_type IN ('percentage', 'fixed')),
+    discount_value NUMERIC(12, 2) NOT NULL CHECK (discount_value > 0),
+    max_uses INTEGER CHECK (max_uses > 0),
+    used_count INTEGER NOT NULL DEFAULT 0 CHECK (used_count >= 0),
+    valid_from TIMESTAMP WITH TIME ZONE,
+    valid_until TIMESTAMP WITH TIME ZONE,
+    fee_tiers TEXT[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (discount_type <> 'percentage' OR discount_value <= 100),
+    CHECK (valid_from IS NULL OR valid_until IS NULL OR valid_from < valid_until)
+);
+
+-- The server prices every payment; these record how the amount was computed
+ALTER TABLE payments
+    ADD COLUMN fee_tier VARCHAR(32),
+    ADD COLUMN schedule_type VARCHAR(16),
+    ADD COLUMN base_amount NUMERIC(12, 2),
+    ADD COLUMN discount_amount NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (discount_amount >= 0),
+    ADD COLUMN voucher_id BIGINT REFERENCES vouchers(id),
+    ADD COLUMN voucher_code VARCHAR(32);
+
+UPDATE payments SET base_amount = amount WHERE base_amount IS NULL;
+
+ALTER TABLE payments
+    ALTER COLUMN base_amount SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_payments_voucher_id ON payments (voucher_id) WHERE voucher_id IS NOT NULL;
@@ -0,0 +1,36 @@
+-- Create the refunds table
+CREATE TABLE IF NOT EXISTS refunds (
+    id BIGSERIAL PRIMARY KEY,
+    payment_id BIGINT NOT NULL REFERENCES payments(id),
+    registration_id BIGINT NOT NULL REFERENCES registrations(id),
+    amount NUMERIC(12, 2) NOT NULL CHECK (amount > 0),
+    full_refund BOOLEAN NOT NULL DEFAULT FALSE,
+    reason TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'requested' CHECK (status IN ('requested', 'approved', 'rejected', 'completed')),
+    requested_by VARCHAR(100) NOT NULL,
+
+    -- Destination account
+    bank_name VARCHAR(100) NOT NULL,
+    account_number VARCHAR(50) NOT NULL,
+    account_name VARCHAR(100) NOT NULL,
+
+    reviewed_by VARCHAR(100),
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    review_notes TEXT,
+
+    -- Transfer back to the student
+    transfer_reference VARCHAR(100),
+    transferred_at TIMESTAMP WITH TIME ZONE,
+    proof_url TEXT,
+    completed_by VARCHAR(100),
+    completed_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_payment_id ON refunds (payment_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_open ON refunds (status) WHERE status IN ('requested', 'approved');
+
+ALTER TABLE payments
+    ADD COLUMN refunded_amount NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (refunded_amount >= 0);
@@ -0,0 +1,39 @@
+-- Create the bank statement tables
+CREATE TABLE IF NOT EXISTS bank_statements (
+    id BIGSERIAL PRIMARY KEY,
+    format VARCHAR(16) NOT NULL CHECK (format IN ('csv', 'mt940')),
+    filename VARCHAR(255) NOT NULL,
+    account_number VARCHAR(50),
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    credit_count INTEGER NOT NULL DEFAULT 0,
+    duplicate_count INTEGER NOT NULL DEFAULT 0,
+    matched_count INTEGER NOT NULL DEFAULT 0,
+    imported_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One row per credit; debits are not imported
+CREATE TABLE IF NOT EXISTS bank_statement_lines (
+    id BIGSERIAL PRIMARY KEY,
+    statement_id BIGINT NOT NULL REFERENCES bank_statements(id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL,
+    booked_on DATE NOT NULL,
+    amount NUMERIC(12, 2) NOT NULL CHECK (amount > 0),
+    description TEXT NOT NULL DEFAULT '',
+    reference VARCHAR(100),
+    -- Identifies the transaction across overlapping statements
+    fingerprint CHAR(64) NOT NULL UNIQUE,
+    status VARCHAR(16) NOT NULL DEFAULT 'unmatched' CHECK (status IN ('unmatched', 'matched', 'ignored')),
+    payment_id BIGINT REFERENCES payments(id),
+    match_method VARCHAR(16) CHECK (match_method IN ('reference', 'amount', 'manual')),
+    match_notes TEXT,
+    matched_by VARCHAR(100),
+    matched_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((status = 'matched') = (payment_id IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_statement_lines_statement_id ON bank_statement_lines (statement_id, line_number);
+CREATE INDEX IF NOT EXISTS idx_bank_statement_lines_unmatched ON bank_statement_lines (booked_on) WHERE status = 'unmatched';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bank_statement_lines_payment_id ON bank_statement_lines (payment_id) WHERE payment_id IS NOT NULL;
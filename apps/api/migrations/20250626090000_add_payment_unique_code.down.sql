@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_payments_pending_unique_amount;
+ALTER TABLE payments DROP COLUMN IF EXISTS unique_code;
@@ -0,0 +1,7 @@
+-- Bank transfers are paid with a small unique offset so identical fees can be told apart
+ALTER TABLE payments
+    ADD COLUMN unique_code SMALLINT NOT NULL DEFAULT 0 CHECK (unique_code BETWEEN 0 AND 999);
+
+-- A coded amount is reserved while its payment is pending and recycled afterwards
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_pending_unique_amount ON payments (amount)
+    WHERE payment_status = 'pending' AND unique_code > 0;
@@ -0,0 +1,3 @@
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS invoice_issued_at,
+    DROP COLUMN IF EXISTS invoice_number;
@@ -0,0 +1,4 @@
+-- Sequential invoice numbers for individual payments, e.g. I001/V/2025
+ALTER TABLE payments
+    ADD COLUMN invoice_number VARCHAR(32) UNIQUE,
+    ADD COLUMN invoice_issued_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,9 @@
+-- Passport-style photo printed on admission cards, one per participant
+CREATE TABLE IF NOT EXISTS participant_photos (
+    participant_id BIGINT PRIMARY KEY REFERENCES participants(id) ON DELETE CASCADE,
+    content_type VARCHAR(32) NOT NULL CHECK (content_type IN ('image/jpeg', 'image/png')),
+    data BYTEA NOT NULL,
+    width INTEGER NOT NULL CHECK (width > 0),
+    height INTEGER NOT NULL CHECK (height > 0),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_registrations_attendance;
+
+ALTER TABLE registrations
+    DROP COLUMN IF EXISTS checked_in_by,
+    DROP COLUMN IF EXISTS checked_in_at,
+    DROP COLUMN IF EXISTS attendance;
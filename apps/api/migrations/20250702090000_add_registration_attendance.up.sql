@@ -0,0 +1,9 @@
+-- Exam-day attendance: set when a proctor scans the admission card, or to no_show by
+-- the job that runs once the check-in cut-off has passed
+ALTER TABLE registrations
+    ADD COLUMN attendance VARCHAR(16) CHECK (attendance IN ('attended', 'no_show')),
+    ADD COLUMN checked_in_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN checked_in_by VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_registrations_attendance ON registrations (test_plot_id, attendance)
+    WHERE status = 'approved';
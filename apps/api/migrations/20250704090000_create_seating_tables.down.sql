@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_registrations_seat;
+
+ALTER TABLE registrations
+    DROP COLUMN IF EXISTS seat_number;
+
+DROP TABLE IF EXISTS schedule_seating;
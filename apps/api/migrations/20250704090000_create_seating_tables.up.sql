@@ -0,0 +1,18 @@
+-- How a schedule's seats are arranged and when they were assigned; schedules without
+-- a row are seated sequentially, ten seats to a row
+CREATE TABLE IF NOT EXISTS schedule_seating (
+    schedule_id BIGINT PRIMARY KEY REFERENCES schedules(id) ON DELETE CASCADE,
+    mode VARCHAR(16) NOT NULL DEFAULT 'sequential' CHECK (mode IN ('sequential', 'random')),
+    seats_per_row INTEGER NOT NULL DEFAULT 10 CHECK (seats_per_row BETWEEN 1 AND 12),
+    assigned_at TIMESTAMP WITH TIME ZONE,
+    assigned_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Seats are numbered from 1 within a test plot; rescheduling clears the seat
+ALTER TABLE registrations
+    ADD COLUMN seat_number INTEGER CHECK (seat_number > 0);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_registrations_seat ON registrations (test_plot_id, seat_number)
+    WHERE seat_number IS NOT NULL;
@@ -0,0 +1,9 @@
+DELETE FROM calendar_feed_tokens WHERE owner_type = 'proctor';
+
+ALTER TABLE calendar_feed_tokens
+    DROP CONSTRAINT IF EXISTS calendar_feed_tokens_owner_type_check,
+    ADD CONSTRAINT calendar_feed_tokens_owner_type_check CHECK (owner_type IN ('student'));
+
+DROP TABLE IF EXISTS schedule_proctors;
+DROP TABLE IF EXISTS proctor_availability;
+DROP TABLE IF EXISTS proctors;
@@ -0,0 +1,46 @@
+-- Staff who invigilate test sessions. Proctors sign in with tokens whose subject is
+-- their proctor ID.
+CREATE TABLE IF NOT EXISTS proctors (
+    id BIGSERIAL PRIMARY KEY,
+    full_name VARCHAR(100) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    phone VARCHAR(15) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_proctors_email ON proctors (LOWER(email));
+
+-- Periods a proctor can be assigned to sessions in
+CREATE TABLE IF NOT EXISTS proctor_availability (
+    id BIGSERIAL PRIMARY KEY,
+    proctor_id BIGINT NOT NULL REFERENCES proctors(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    notes VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_proctor_availability_proctor ON proctor_availability (proctor_id, starts_at);
+
+-- Removed assignments are kept so proctor calendar feeds can show them cancelled
+CREATE TABLE IF NOT EXISTS schedule_proctors (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    proctor_id BIGINT NOT NULL REFERENCES proctors(id),
+    assigned_by VARCHAR(100) NOT NULL,
+    assigned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    removed_by VARCHAR(100),
+    removed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_schedule_proctors_active ON schedule_proctors (schedule_id, proctor_id)
+    WHERE removed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_schedule_proctors_proctor ON schedule_proctors (proctor_id);
+
+-- Proctors subscribe to their assignments
+ALTER TABLE calendar_feed_tokens
+    DROP CONSTRAINT IF EXISTS calendar_feed_tokens_owner_type_check,
+    ADD CONSTRAINT calendar_feed_tokens_owner_type_check CHECK (owner_type IN ('student', 'proctor'));
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS scores;
+
+ALTER TABLE registrations
+    DROP COLUMN IF EXISTS test_form_code;
+
+ALTER TABLE schedules
+    DROP COLUMN IF EXISTS test_form_code;
+
+DROP TABLE IF EXISTS test_form_conversions;
+DROP TABLE IF EXISTS test_forms;
@@ -0,0 +1,48 @@
+-- Test forms (booklet versions) rotated between sessions for test security
+CREATE TABLE IF NOT EXISTS test_forms (
+    code VARCHAR(32) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Scaled section score for every raw score (questions answered correctly) of a form
+CREATE TABLE IF NOT EXISTS test_form_conversions (
+    form_code VARCHAR(32) NOT NULL REFERENCES test_forms(code) ON DELETE CASCADE,
+    section VARCHAR(16) NOT NULL CHECK (section IN ('listening', 'structure', 'reading')),
+    raw_score INTEGER NOT NULL CHECK (raw_score >= 0),
+    scaled_score INTEGER NOT NULL CHECK (scaled_score > 0),
+    PRIMARY KEY (form_code, section, raw_score)
+);
+
+-- Form used at a session; a registration's form overrides it for that seat, e.g. for
+-- a participant who took the session's form at their previous attempt. Rescheduling
+-- clears the override.
+ALTER TABLE schedules
+    ADD COLUMN test_form_code VARCHAR(32) REFERENCES test_forms(code);
+
+ALTER TABLE registrations
+    ADD COLUMN test_form_code VARCHAR(32) REFERENCES test_forms(code);
+
+-- One score per registration, scaled with the conversion table of the form used
+CREATE TABLE IF NOT EXISTS scores (
+    id BIGSERIAL PRIMARY KEY,
+    registration_id BIGINT NOT NULL UNIQUE REFERENCES registrations(id),
+    student_id BIGINT NOT NULL REFERENCES participants(id),
+    test_plot_id BIGINT NOT NULL,
+    test_form_code VARCHAR(32) NOT NULL REFERENCES test_forms(code),
+    listening_correct INTEGER NOT NULL CHECK (listening_correct BETWEEN 0 AND 50),
+    structure_correct INTEGER NOT NULL CHECK (structure_correct BETWEEN 0 AND 40),
+    reading_correct INTEGER NOT NULL CHECK (reading_correct BETWEEN 0 AND 50),
+    listening_comprehension INTEGER NOT NULL,
+    structure_written_expression INTEGER NOT NULL,
+    reading_comprehension INTEGER NOT NULL,
+    total_score INTEGER NOT NULL,
+    recorded_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_scores_student_id ON scores (student_id);
+CREATE INDEX IF NOT EXISTS idx_scores_test_plot_id ON scores (test_plot_id);
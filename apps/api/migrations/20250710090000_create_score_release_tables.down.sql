@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS schedule_score_releases;
+
+ALTER TABLE scores
+    DROP COLUMN IF EXISTS released_by,
+    DROP COLUMN IF EXISTS released_at;
@@ -0,0 +1,19 @@
+-- Scores are recorded as drafts and become visible to students once released
+ALTER TABLE scores
+    ADD COLUMN released_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN released_by VARCHAR(100);
+
+-- When the scores of a schedule are to be and were last released; release_at is
+-- cleared once the scheduled release has run
+CREATE TABLE IF NOT EXISTS schedule_score_releases (
+    schedule_id BIGINT PRIMARY KEY REFERENCES schedules(id) ON DELETE CASCADE,
+    release_at TIMESTAMP WITH TIME ZONE,
+    scheduled_by VARCHAR(100),
+    released_at TIMESTAMP WITH TIME ZONE,
+    released_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_score_releases_release_at ON schedule_score_releases (release_at)
+    WHERE release_at IS NOT NULL;
@@ -0,0 +1,10 @@
+DELETE FROM eligibility_rules WHERE type = 'valid_score';
+
+ALTER TABLE eligibility_rules
+    DROP CONSTRAINT IF EXISTS eligibility_rules_type_check,
+    ADD CONSTRAINT eligibility_rules_type_check CHECK (type IN ('one_active_registration', 'retake_interval', 'subsidized_majors', 'max_attempts_per_year'));
+
+DROP INDEX IF EXISTS idx_scores_valid_until;
+
+ALTER TABLE scores
+    DROP COLUMN IF EXISTS valid_until;
@@ -0,0 +1,22 @@
+-- A score is valid for a configurable period after the test; the end is fixed when
+-- the score is recorded. Existing scores get the default two years.
+ALTER TABLE scores
+    ADD COLUMN valid_until TIMESTAMP WITH TIME ZONE;
+
+UPDATE scores sc SET valid_until = s.date_time + INTERVAL '24 months'
+FROM schedules s
+WHERE s.plot_id = sc.test_plot_id;
+
+UPDATE scores SET valid_until = created_at + INTERVAL '24 months'
+WHERE valid_until IS NULL;
+
+ALTER TABLE scores
+    ALTER COLUMN valid_until SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_scores_valid_until ON scores (valid_until)
+    WHERE released_at IS NOT NULL;
+
+-- Eligibility rules can require a currently valid score
+ALTER TABLE eligibility_rules
+    DROP CONSTRAINT IF EXISTS eligibility_rules_type_check,
+    ADD CONSTRAINT eligibility_rules_type_check CHECK (type IN ('one_active_registration', 'retake_interval', 'subsidized_majors', 'max_attempts_per_year', 'valid_score'));
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS score_revisions_immutable ON score_revisions;
+DROP FUNCTION IF EXISTS prevent_score_revision_change();
+DROP TABLE IF EXISTS score_revisions;
+DROP TABLE IF EXISTS score_appeals;
+DROP TABLE IF EXISTS score_certificates;
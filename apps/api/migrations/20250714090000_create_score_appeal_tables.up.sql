@@ -0,0 +1,74 @@
+-- Certificates of released scores. Correcting a score revokes its certificate and
+-- issues a new one with a new verification code.
+CREATE TABLE IF NOT EXISTS score_certificates (
+    id BIGSERIAL PRIMARY KEY,
+    score_id BIGINT NOT NULL REFERENCES scores(id),
+    verification_code VARCHAR(16) NOT NULL UNIQUE,
+    issued_by VARCHAR(100) NOT NULL,
+    issued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_reason VARCHAR(1000)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_score_certificates_current ON score_certificates (score_id)
+    WHERE revoked_at IS NULL;
+
+-- Scores released before certificates existed, with codes in the same XXXX-XXXX-XXXX
+-- format and alphabet the application issues. The subquery refers to the score so it
+-- is evaluated, and random() drawn, once per row.
+INSERT INTO score_certificates (score_id, verification_code, issued_by, issued_at)
+SELECT sc.id,
+       (SELECT string_agg(
+                   substr('ABCDEFGHJKLMNPQRSTUVWXYZ23456789', 1 + floor(random() * 32)::int, 1)
+                       || CASE WHEN i IN (4, 8) THEN '-' ELSE '' END,
+                   '' ORDER BY i)
+        FROM generate_series(1, 12) AS i
+        WHERE sc.id IS NOT NULL),
+       COALESCE(sc.released_by, 'system'), sc.released_at
+FROM scores sc
+WHERE sc.released_at IS NOT NULL;
+
+-- A student's request to have a released score checked again
+CREATE TABLE IF NOT EXISTS score_appeals (
+    id BIGSERIAL PRIMARY KEY,
+    score_id BIGINT NOT NULL REFERENCES scores(id),
+    student_id BIGINT NOT NULL REFERENCES participants(id),
+    reason VARCHAR(1000) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'rejected')),
+    review_note VARCHAR(1000),
+    reviewed_by VARCHAR(100),
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_score_appeals_pending ON score_appeals (score_id)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_score_appeals_student_id ON score_appeals (student_id);
+
+-- Every change to a score's values, with the values before and after
+CREATE TABLE IF NOT EXISTS score_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    score_id BIGINT NOT NULL REFERENCES scores(id),
+    appeal_id BIGINT REFERENCES score_appeals(id),
+    old_values JSONB NOT NULL,
+    new_values JSONB NOT NULL,
+    reason VARCHAR(1000) NOT NULL,
+    changed_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_score_revisions_score_id ON score_revisions (score_id);
+
+-- Revisions are an audit trail and are never changed or removed
+CREATE OR REPLACE FUNCTION prevent_score_revision_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'Score revisions cannot be changed or deleted';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER score_revisions_immutable
+    BEFORE UPDATE OR DELETE ON score_revisions
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_score_revision_change();
@@ -0,0 +1,5 @@
+UPDATE lottery_applications SET status = 'withdrawn' WHERE status = 'ineligible';
+
+ALTER TABLE lottery_applications
+    DROP CONSTRAINT IF EXISTS lottery_applications_status_check,
+    ADD CONSTRAINT lottery_applications_status_check CHECK (status IN ('applied', 'won', 'waitlisted', 'withdrawn'));
@@ -0,0 +1,4 @@
+-- Applicants who no longer pass the eligibility rules at draw time are skipped
+ALTER TABLE lottery_applications
+    DROP CONSTRAINT IF EXISTS lottery_applications_status_check,
+    ADD CONSTRAINT lottery_applications_status_check CHECK (status IN ('applied', 'won', 'waitlisted', 'ineligible', 'withdrawn'));
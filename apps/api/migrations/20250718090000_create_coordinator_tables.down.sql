@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_group_registrations_faculty_code;
+
+ALTER TABLE group_registrations
+    DROP COLUMN IF EXISTS faculty_code;
+
+DROP TABLE IF EXISTS coordinators;
+DROP TABLE IF EXISTS faculties;
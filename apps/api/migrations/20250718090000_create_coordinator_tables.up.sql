@@ -0,0 +1,29 @@
+-- Faculties and the majors whose students their coordinators may register
+CREATE TABLE IF NOT EXISTS faculties (
+    code VARCHAR(32) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    majors TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Faculty staff who register cohorts. Coordinators sign in with tokens whose subject is
+-- their coordinator ID.
+CREATE TABLE IF NOT EXISTS coordinators (
+    id BIGSERIAL PRIMARY KEY,
+    full_name VARCHAR(100) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    faculty_code VARCHAR(32) NOT NULL REFERENCES faculties(code) ON UPDATE CASCADE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coordinators_email ON coordinators (LOWER(email));
+
+-- Coordinators see the groups of their own faculty; groups registered before faculties
+-- existed, or by an admin, have none and are only visible to admins
+ALTER TABLE group_registrations
+    ADD COLUMN faculty_code VARCHAR(32) REFERENCES faculties(code) ON UPDATE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_group_registrations_faculty_code ON group_registrations (faculty_code, created_at DESC);
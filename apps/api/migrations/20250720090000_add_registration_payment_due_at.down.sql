@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_registrations_payment_due_at;
+ALTER TABLE registrations DROP COLUMN IF EXISTS payment_due_at;
@@ -0,0 +1,11 @@
+-- When an individual registration is cancelled if no payment has been opened for it.
+-- Group registrations are paid through their invoice and have none.
+ALTER TABLE registrations ADD COLUMN IF NOT EXISTS payment_due_at TIMESTAMP WITH TIME ZONE;
+
+-- Pending registrations that never opened a payment get the default two days from now
+UPDATE registrations r
+SET payment_due_at = LEAST(CURRENT_TIMESTAMP + INTERVAL '48 hours', r.test_date)
+WHERE r.status = 'pending' AND r.group_registration_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_registrations_payment_due_at ON registrations (payment_due_at)
+    WHERE status = 'pending' AND payment_due_at IS NOT NULL;
@@ -0,0 +1,5 @@
+UPDATE waitlist_entries SET status = 'expired' WHERE status = 'ineligible';
+
+ALTER TABLE waitlist_entries
+    DROP CONSTRAINT IF EXISTS waitlist_entries_status_check,
+    ADD CONSTRAINT waitlist_entries_status_check CHECK (status IN ('waiting', 'offered', 'claimed', 'expired', 'left'));
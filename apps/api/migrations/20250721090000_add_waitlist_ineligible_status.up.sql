@@ -0,0 +1,4 @@
+-- Students who no longer pass the eligibility rules when claiming an offered seat
+ALTER TABLE waitlist_entries
+    DROP CONSTRAINT IF EXISTS waitlist_entries_status_check,
+    ADD CONSTRAINT waitlist_entries_status_check CHECK (status IN ('waiting', 'offered', 'claimed', 'expired', 'ineligible', 'left'));
@@ -0,0 +1 @@
+ALTER TABLE group_invoices DROP COLUMN IF EXISTS paid_by;
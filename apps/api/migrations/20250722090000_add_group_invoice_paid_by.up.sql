@@ -0,0 +1,2 @@
+-- The admin who recorded the faculty's payment of a group invoice
+ALTER TABLE group_invoices ADD COLUMN IF NOT EXISTS paid_by VARCHAR(100);
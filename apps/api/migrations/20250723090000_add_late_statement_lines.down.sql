@@ -0,0 +1,5 @@
+UPDATE bank_statement_lines SET status = 'unmatched' WHERE status = 'late';
+
+ALTER TABLE bank_statement_lines
+    DROP CONSTRAINT IF EXISTS bank_statement_lines_status_check,
+    ADD CONSTRAINT bank_statement_lines_status_check CHECK (status IN ('unmatched', 'matched', 'ignored'));
@@ -0,0 +1,4 @@
+-- Credits for payments that had already expired or been cancelled
+ALTER TABLE bank_statement_lines
+    DROP CONSTRAINT IF EXISTS bank_statement_lines_status_check,
+    ADD CONSTRAINT bank_statement_lines_status_check CHECK (status IN ('unmatched', 'matched', 'ignored', 'late'));
@@ -0,0 +1,184 @@
+// Package bankstatement reads the account mutation exports banks hand out, as CSV
+// or SWIFT MT940, into a list of transactions. Credits have a positive amount and
+// debits a negative one.
+package bankstatement
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported formats
+const (
+	FormatCSV   = "csv"
+	FormatMT940 = "mt940"
+)
+
+// Transaction is one mutation on the account
+type Transaction struct {
+	Date        time.Time
+	Amount      float64 // Positive for credits, negative for debits
+	Description string
+	Reference   string
+	// Fingerprint identifies the transaction across imports of overlapping statements
+	Fingerprint string
+}
+
+// Statement is a parsed export
+type Statement struct {
+	Format       string
+	Account      string
+	Transactions []Transaction
+}
+
+// DetectFormat guesses the format from the file name and falls back to the content
+func DetectFormat(filename string, content []byte) string {
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".csv"):
+		return FormatCSV
+	case strings.HasSuffix(name, ".sta"), strings.HasSuffix(name, ".mt940"), strings.HasSuffix(name, ".940"):
+		return FormatMT940
+	}
+	if bytes.Contains(content, []byte(":61:")) {
+		return FormatMT940
+	}
+	return FormatCSV
+}
+
+// Parse reads a statement in the given format
+func Parse(format string, r io.Reader) (*Statement, error) {
+	var (
+		statement *Statement
+		err       error
+	)
+	switch format {
+	case FormatCSV:
+		statement, err = ParseCSV(r)
+	case FormatMT940:
+		statement, err = ParseMT940(r)
+	default:
+		return nil, fmt.Errorf("invalid statement format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(statement.Transactions) == 0 {
+		return nil, fmt.Errorf("invalid statement: no transactions found")
+	}
+
+	fingerprint(statement)
+	return statement, nil
+}
+
+// fingerprint hashes every transaction. Identical transactions on the same day are
+// told apart by their position among the duplicates, so importing the same export
+// twice yields the same fingerprints.
+func fingerprint(statement *Statement) {
+	seen := make(map[string]int)
+	for i := range statement.Transactions {
+		t := &statement.Transactions[i]
+		key := strings.Join([]string{
+			statement.Account,
+			t.Date.Format(time.DateOnly),
+			strconv.FormatFloat(t.Amount, 'f', 2, 64),
+			strings.Join(strings.Fields(t.Description), " "),
+			t.Reference,
+		}, "|")
+		seen[key]++
+		sum := sha256.Sum256([]byte(key + "|" + strconv.Itoa(seen[key])))
+		t.Fingerprint = hex.EncodeToString(sum[:])
+	}
+}
+
+// ParseAmount reads an amount written with either "." or "," as the decimal
+// separator, optionally with thousands separators, a currency prefix or a CR/DB
+// suffix. A DB/D suffix or a leading minus makes it negative.
+func ParseAmount(s string) (float64, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	sign := 1.0
+	for _, suffix := range []string{" CR", " DB", "CR", "DB", " C", " D"} {
+		if strings.HasSuffix(v, suffix) {
+			if strings.TrimSpace(suffix)[0] == 'D' {
+				sign = -1
+			}
+			v = strings.TrimSpace(strings.TrimSuffix(v, suffix))
+			break
+		}
+	}
+	v = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(v, "IDR"), "RP"))
+	if strings.HasPrefix(v, "-") {
+		sign = -sign
+		v = v[1:]
+	}
+	v = strings.ReplaceAll(v, " ", "")
+
+	// With both separators the last one is the decimal separator. With only one kind,
+	// it groups thousands when it repeats or is followed by exactly three digits,
+	// e.g. "150.000" or "1,500,000".
+	dot, comma := strings.LastIndex(v, "."), strings.LastIndex(v, ",")
+	switch {
+	case dot >= 0 && comma >= 0 && dot > comma:
+		v = strings.ReplaceAll(v, ",", "")
+	case dot >= 0 && comma >= 0:
+		v = strings.ReplaceAll(strings.ReplaceAll(v, ".", ""), ",", ".")
+	case comma >= 0 && (strings.Count(v, ",") > 1 || len(v)-comma-1 == 3):
+		v = strings.ReplaceAll(v, ",", "")
+	case comma >= 0:
+		v = strings.ReplaceAll(v, ",", ".")
+	case dot >= 0 && (strings.Count(v, ".") > 1 || len(v)-dot-1 == 3):
+		v = strings.ReplaceAll(v, ".", "")
+	}
+
+	amount, err := strconv.ParseFloat(v, 64)
+	if err != nil || v == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return sign * amount, nil
+}
+
+var dateLayouts = []string{
+	"2006-01-02",
+	"02/01/2006",
+	"02-01-2006",
+	"02.01.2006",
+	"2006/01/02",
+	"02/01/06",
+	"02 Jan 2006",
+	"02-Jan-2006",
+	"2006-01-02 15:04:05",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+}
+
+// ParseDate reads the day-first and ISO date formats used in bank exports
+func ParseDate(s string) (time.Time, error) {
+	v := strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// scanLines splits input on any line ending
+func scanLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid statement: %w", err)
+	}
+	return lines, nil
+}
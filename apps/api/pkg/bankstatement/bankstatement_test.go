@@ -0,0 +1,174 @@
+package bankstatement
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{in: "150000", want: 150000},
+		{in: "150.000", want: 150000},
+		{in: "1.500.000", want: 1500000},
+		{in: "1,500,000", want: 1500000},
+		{in: "150.000,00", want: 150000},
+		{in: "150,000.00", want: 150000},
+		{in: "150.123,45", want: 150123.45},
+		{in: "1,5", want: 1.5},
+		{in: "1.5", want: 1.5},
+		{in: "12.34", want: 12.34},
+		{in: "150000.", want: 150000},
+		{in: "Rp 150.000", want: 150000},
+		{in: "IDR 150.000,00", want: 150000},
+		{in: "150.000,00 CR", want: 150000},
+		{in: "150.000,00 DB", want: -150000},
+		{in: "150.000,00DB", want: -150000},
+		{in: "150.000 D", want: -150000},
+		{in: "150.000 C", want: 150000},
+		{in: "-50.000", want: -50000},
+		{in: "-50.000 DB", want: 50000},
+		{in: "  75 000  ", want: 75000},
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "12a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseAmount(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseAmount(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAmount(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	july := time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "2025-07-01", want: july},
+		{in: "01/07/2025", want: july},
+		{in: "01-07-2025", want: july},
+		{in: "01.07.2025", want: july},
+		{in: "2025/07/01", want: july},
+		{in: "01/07/25", want: july},
+		{in: "01 Jul 2025", want: july},
+		{in: "01-Jul-2025", want: july},
+		{in: " 2025-07-01 ", want: july},
+		{in: "2025-07-01 10:30:00", want: july.Add(10*time.Hour + 30*time.Minute)},
+		{in: "01/07/2025 10:30:15", want: july.Add(10*time.Hour + 30*time.Minute + 15*time.Second)},
+		{in: "01/07/2025 10:30", want: july.Add(10*time.Hour + 30*time.Minute)},
+		{in: "07/31/2025", wantErr: true}, // Month first is not accepted
+		{in: "SALDO AWAL", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDate(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseDate(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseDate(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		filename string
+		content  string
+		want     string
+	}{
+		{filename: "mutasi.csv", content: ":61:", want: FormatCSV},
+		{filename: "STATEMENT.STA", want: FormatMT940},
+		{filename: "export.mt940", want: FormatMT940},
+		{filename: "export.940", want: FormatMT940},
+		{filename: "export.txt", content: ":20:X\n:61:250702C1,00NTRFREF\n", want: FormatMT940},
+		{filename: "export.txt", content: "Tanggal;Keterangan;Mutasi\n", want: FormatCSV},
+	}
+
+	for _, tt := range tests {
+		if got := DetectFormat(tt.filename, []byte(tt.content)); got != tt.want {
+			t.Errorf("DetectFormat(%q) = %q, want %q", tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	input := "Date,Description,Amount\n" +
+		"2025-07-02,Transfer ANI,150000\n" +
+		"2025-07-02,Transfer ANI,150000\n" +
+		"2025-07-03,Transfer BUDI,250000\n"
+
+	first, err := Parse(FormatCSV, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	second, err := Parse(FormatCSV, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	if len(first.Transactions) != 3 {
+		t.Fatalf("got %d transactions, want 3", len(first.Transactions))
+	}
+	seen := make(map[string]bool)
+	for i, tx := range first.Transactions {
+		if tx.Fingerprint == "" {
+			t.Errorf("transaction %d has no fingerprint", i)
+		}
+		if seen[tx.Fingerprint] {
+			t.Errorf("transaction %d repeats fingerprint %s", i, tx.Fingerprint)
+		}
+		seen[tx.Fingerprint] = true
+		if tx.Fingerprint != second.Transactions[i].Fingerprint {
+			t.Errorf("transaction %d fingerprint changed between imports", i)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		input   string
+		wantErr string
+	}{
+		{name: "unknown format", format: "xls", input: "x", wantErr: `invalid statement format "xls"`},
+		{name: "no transactions", format: FormatCSV, input: "Date,Description,Amount\n", wantErr: "no transactions found"},
+		{name: "no header", format: FormatCSV, input: "a,b,c\n1,2,3\n", wantErr: "no header row"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.format, strings.NewReader(tt.input))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: got error %v, want it to contain %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package bankstatement
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Header names recognised in CSV exports, English and Indonesian
+var (
+	dateHeaders        = []string{"tanggal", "date", "tgl"}
+	descriptionHeaders = []string{"keterangan", "description", "deskripsi", "uraian", "remark", "narrative"}
+	amountHeaders      = []string{"amount", "jumlah", "nominal", "mutasi"}
+	creditHeaders      = []string{"credit", "kredit"}
+	debitHeaders       = []string{"debit", "debet"}
+	typeHeaders        = []string{"db/cr", "d/k", "d/c", "dk", "type", "jenis"}
+	referenceHeaders   = []string{"reference", "referensi", "ref"}
+)
+
+var accountPattern = regexp.MustCompile(`(?i)(rekening|account)[^0-9]*([0-9][0-9 .-]{5,}[0-9])`)
+
+type csvColumns struct {
+	date, description, amount, credit, debit, kind, reference int
+}
+
+// ParseCSV reads a CSV export. The header row is found by its column names, so
+// preamble lines such as the account number or period are skipped. Amounts come
+// either from one signed amount column, optionally with a debit/credit column, or
+// from separate debit and credit columns. Rows without a date, such as opening and
+// closing balances, are skipped.
+func ParseCSV(r io.Reader) (*Statement, error) {
+	lines, err := scanLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &Statement{Format: FormatCSV}
+	delimiter := sniffDelimiter(lines)
+
+	var cols *csvColumns
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		record, err := readRecord(line, delimiter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid statement: line %d: %w", i+1, err)
+		}
+
+		if cols == nil {
+			if cols = findColumns(record); cols == nil {
+				if m := accountPattern.FindStringSubmatch(line); m != nil && statement.Account == "" {
+					statement.Account = strings.NewReplacer(" ", "", ".", "", "-", "").Replace(m[2])
+				}
+			}
+			continue
+		}
+
+		t, ok, err := cols.transaction(record)
+		if err != nil {
+			return nil, fmt.Errorf("invalid statement: line %d: %w", i+1, err)
+		}
+		if ok {
+			statement.Transactions = append(statement.Transactions, t)
+		}
+	}
+
+	if cols == nil {
+		return nil, fmt.Errorf("invalid statement: no header row with date, description and amount columns")
+	}
+	return statement, nil
+}
+
+// sniffDelimiter picks the separator that appears most often in the first lines
+func sniffDelimiter(lines []string) rune {
+	best, bestCount := ',', 0
+	for _, d := range []rune{',', ';', '\t', '|'} {
+		count := 0
+		for _, line := range lines[:min(len(lines), 20)] {
+			count += strings.Count(line, string(d))
+		}
+		if count > bestCount {
+			best, bestCount = d, count
+		}
+	}
+	return best
+}
+
+func readRecord(line string, delimiter rune) ([]string, error) {
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	record, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	return record, err
+}
+
+// findColumns maps a header row, or returns nil when the row is not one
+func findColumns(record []string) *csvColumns {
+	cols := &csvColumns{-1, -1, -1, -1, -1, -1, -1}
+	for i, cell := range record {
+		name := strings.ToLower(strings.TrimSpace(cell))
+		switch {
+		case name == "":
+		case cols.kind < 0 && matchesHeader(name, typeHeaders, true):
+			cols.kind = i
+		case cols.date < 0 && matchesHeader(name, dateHeaders, false):
+			cols.date = i
+		case cols.description < 0 && matchesHeader(name, descriptionHeaders, false):
+			cols.description = i
+		case cols.credit < 0 && matchesHeader(name, creditHeaders, false):
+			cols.credit = i
+		case cols.debit < 0 && matchesHeader(name, debitHeaders, false):
+			cols.debit = i
+		case cols.amount < 0 && matchesHeader(name, amountHeaders, false):
+			cols.amount = i
+		case cols.reference < 0 && matchesHeader(name, referenceHeaders, false):
+			cols.reference = i
+		}
+	}
+
+	if cols.date < 0 || cols.description < 0 || (cols.amount < 0 && cols.credit < 0) {
+		return nil
+	}
+	return cols
+}
+
+func matchesHeader(name string, candidates []string, exact bool) bool {
+	for _, c := range candidates {
+		if name == c || (!exact && strings.Contains(name, c)) {
+			return true
+		}
+	}
+	return false
+}
+
+func cell(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// transaction reads a data row; ok is false for rows that carry no transaction
+func (c *csvColumns) transaction(record []string) (Transaction, bool, error) {
+	date, err := ParseDate(cell(record, c.date))
+	if err != nil {
+		return Transaction{}, false, nil
+	}
+
+	var amount float64
+	switch {
+	case c.credit >= 0 && cell(record, c.credit) != "" && cell(record, c.credit) != "0":
+		amount, err = ParseAmount(cell(record, c.credit))
+	case c.debit >= 0 && cell(record, c.debit) != "":
+		amount, err = ParseAmount(cell(record, c.debit))
+		if amount > 0 {
+			amount = -amount
+		}
+	case c.amount >= 0:
+		amount, err = ParseAmount(cell(record, c.amount))
+		switch strings.ToUpper(cell(record, c.kind)) {
+		case "D", "DB", "DEBIT", "DEBET":
+			if amount > 0 {
+				amount = -amount
+			}
+		}
+	default:
+		return Transaction{}, false, nil
+	}
+	if err != nil {
+		return Transaction{}, false, err
+	}
+	if amount == 0 {
+		return Transaction{}, false, nil
+	}
+
+	return Transaction{
+		Date:        date,
+		Amount:      amount,
+		Description: cell(record, c.description),
+		Reference:   cell(record, c.reference),
+	}, true, nil
+}
@@ -0,0 +1,105 @@
+package bankstatement
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantAccount string
+		want        []Transaction
+	}{
+		{
+			name: "signed amount with debit/credit column and preamble",
+			input: "No. Rekening : 123-456-7890\n" +
+				"Periode : 01/07/2025 - 31/07/2025\n" +
+				"\n" +
+				"Tanggal;Keterangan;Mutasi;DB/CR;Saldo\n" +
+				"02/07/2025;TRSF E-BANKING CR BUDI;150.123,00;CR;1.150.123,00\n" +
+				"03/07/2025;BIAYA ADM;10.000,00;DB;1.140.123,00\n" +
+				"Saldo Akhir;;;;1.140.123,00\n",
+			wantAccount: "1234567890",
+			want: []Transaction{
+				{Date: time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC), Amount: 150123, Description: "TRSF E-BANKING CR BUDI"},
+				{Date: time.Date(2025, 7, 3, 0, 0, 0, 0, time.UTC), Amount: -10000, Description: "BIAYA ADM"},
+			},
+		},
+		{
+			name: "separate debit and credit columns",
+			input: "Date,Description,Debit,Credit,Balance,Reference\n" +
+				"2025-07-02,\"Transfer from ANI, batch 2\",,\"250,000.00\",\"1,250,000.00\",FT001\n" +
+				"2025-07-03,Card fee,\"5,000.00\",0,\"1,245,000.00\",FT002\n",
+			want: []Transaction{
+				{Date: time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC), Amount: 250000, Description: "Transfer from ANI, batch 2", Reference: "FT001"},
+				{Date: time.Date(2025, 7, 3, 0, 0, 0, 0, time.UTC), Amount: -5000, Description: "Card fee", Reference: "FT002"},
+			},
+		},
+		{
+			name: "tab separated with zero amount rows skipped",
+			input: "tgl\turaian\tnominal\r\n" +
+				"02-07-2025\tSETORAN\t75.000\r\n" +
+				"02-07-2025\tKOREKSI\t0\r\n",
+			want: []Transaction{
+				{Date: time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC), Amount: 75000, Description: "SETORAN"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		statement, err := ParseCSV(strings.NewReader(tt.input))
+		if err != nil {
+			t.Errorf("%s: ParseCSV returned error: %v", tt.name, err)
+			continue
+		}
+		if statement.Format != FormatCSV {
+			t.Errorf("%s: format = %q, want %q", tt.name, statement.Format, FormatCSV)
+		}
+		if statement.Account != tt.wantAccount {
+			t.Errorf("%s: account = %q, want %q", tt.name, statement.Account, tt.wantAccount)
+		}
+		if len(statement.Transactions) != len(tt.want) {
+			t.Errorf("%s: got %d transactions, want %d", tt.name, len(statement.Transactions), len(tt.want))
+			continue
+		}
+		for i, want := range tt.want {
+			if got := statement.Transactions[i]; got != want {
+				t.Errorf("%s: transaction %d = %+v, want %+v", tt.name, i, got, want)
+			}
+		}
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "invalid amount",
+			input:   "Date,Description,Amount\n2025-07-02,Transfer,12x\n",
+			wantErr: `invalid statement: line 2: invalid amount "12x"`,
+		},
+		{
+			name:    "line numbers count blank lines",
+			input:   "Date,Description,Amount\n\n2025-07-02,Transfer,100\n2025-07-03,Transfer,abc\n",
+			wantErr: "invalid statement: line 4:",
+		},
+		{
+			name:    "no header row",
+			input:   "02/07/2025,Transfer,100\n",
+			wantErr: "invalid statement: no header row",
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseCSV(strings.NewReader(tt.input))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+			t.Errorf("%s: got error %v, want it to start with %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package bankstatement
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// statementLine matches the :61: field: value date (YYMMDD), optional entry date
+// (MMDD), debit/credit mark (C, D, RC, RD), optional funds code, amount with a
+// decimal comma, transaction type and the account owner's reference, optionally
+// followed by the bank's reference after "//".
+var statementLine = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d{0,2})([A-Z]\w{3})([^/]*)(?://(.*))?$`)
+
+// ParseMT940 reads a SWIFT MT940 customer statement. The :86: information field
+// after each :61: line, including its continuation lines, becomes the description.
+func ParseMT940(r io.Reader) (*Statement, error) {
+	lines, err := scanLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &Statement{Format: FormatMT940}
+
+	var (
+		tag, value string
+		start      int // Line number of the field's tag, reported when the field is invalid
+		current    *Transaction
+	)
+	flush := func() error {
+		switch tag {
+		case "25":
+			if statement.Account == "" {
+				statement.Account = strings.TrimSpace(value)
+			}
+		case "61":
+			t, err := parseStatementLine(value)
+			if err != nil {
+				return err
+			}
+			statement.Transactions = append(statement.Transactions, t)
+			current = &statement.Transactions[len(statement.Transactions)-1]
+		case "86":
+			if current != nil {
+				current.Description = strings.Join(strings.Fields(value), " ")
+				current = nil
+			}
+		}
+		tag, value = "", ""
+		return nil
+	}
+
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, ":"):
+			if err := flush(); err != nil {
+				return nil, fmt.Errorf("invalid statement: line %d: %w", start, err)
+			}
+			end := strings.Index(line[1:], ":")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid statement: line %d: malformed tag", i+1)
+			}
+			tag, value, start = line[1:end+1], line[end+2:], i+1
+		case strings.HasPrefix(line, "-}"), strings.HasPrefix(line, "{"), strings.TrimSpace(line) == "-":
+			if err := flush(); err != nil {
+				return nil, fmt.Errorf("invalid statement: line %d: %w", start, err)
+			}
+		case tag != "":
+			// Continuation of the previous field
+			value += "\n" + line
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, fmt.Errorf("invalid statement: line %d: %w", start, err)
+	}
+
+	return statement, nil
+}
+
+func parseStatementLine(value string) (Transaction, error) {
+	// Only the first line of :61: carries the fixed layout; a second line holds
+	// supplementary details
+	first, details, _ := strings.Cut(value, "\n")
+	m := statementLine.FindStringSubmatch(strings.TrimSpace(first))
+	if m == nil {
+		return Transaction{}, fmt.Errorf("malformed :61: line %q", first)
+	}
+
+	date, err := time.Parse("060102", m[1])
+	if err != nil {
+		return Transaction{}, fmt.Errorf("invalid value date %q", m[1])
+	}
+	amount, err := ParseAmount(strings.Replace(m[5], ",", ".", 1))
+	if err != nil {
+		return Transaction{}, err
+	}
+	if m[3] == "D" || m[3] == "RC" {
+		amount = -amount
+	}
+
+	reference := strings.TrimSpace(m[7])
+	if reference == "" || reference == "NONREF" {
+		reference = strings.TrimSpace(m[8])
+	}
+
+	return Transaction{
+		Date:        date,
+		Amount:      amount,
+		Description: strings.TrimSpace(details),
+		Reference:   reference,
+	}, nil
+}
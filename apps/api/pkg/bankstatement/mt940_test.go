@@ -0,0 +1,126 @@
+package bankstatement
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleMT940 = `{1:F01BANKIDJAXXXX0000000000}{2:I940BANKIDJAXXXXN}{4:
+:20:STMT250702
+:25:1234567890
+:28C:00001/001
+:60F:C250701IDR1000000,00
+:61:2507020702C150123,00NTRFNONREF//FT123
+:86:TRSF E-BANKING CR
+BUDI SANTOSO
+:61:250703D10000,NCHGREF1
+:86:BIAYA ADM
+:61:250704RD2500,50NTRFREF2
+:62F:C250704IDR1142623,50
+-}`
+
+func TestParseMT940(t *testing.T) {
+	statement, err := ParseMT940(strings.NewReader(sampleMT940))
+	if err != nil {
+		t.Fatalf("ParseMT940 returned error: %v", err)
+	}
+
+	if statement.Format != FormatMT940 {
+		t.Errorf("format = %q, want %q", statement.Format, FormatMT940)
+	}
+	if statement.Account != "1234567890" {
+		t.Errorf("account = %q, want %q", statement.Account, "1234567890")
+	}
+
+	want := []Transaction{
+		{Date: time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC), Amount: 150123, Description: "TRSF E-BANKING CR BUDI SANTOSO", Reference: "FT123"},
+		{Date: time.Date(2025, 7, 3, 0, 0, 0, 0, time.UTC), Amount: -10000, Description: "BIAYA ADM", Reference: "REF1"},
+		{Date: time.Date(2025, 7, 4, 0, 0, 0, 0, time.UTC), Amount: 2500.5, Reference: "REF2"}, // Reversed debit
+	}
+	if len(statement.Transactions) != len(want) {
+		t.Fatalf("got %d transactions, want %d", len(statement.Transactions), len(want))
+	}
+	for i := range want {
+		if got := statement.Transactions[i]; got != want[i] {
+			t.Errorf("transaction %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestParseStatementLine(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Transaction
+		wantErr bool
+	}{
+		{
+			in:   "250702C150000,00NTRFINV001",
+			want: Transaction{Date: time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC), Amount: 150000, Reference: "INV001"},
+		},
+		{
+			in:   "2507020703CR150000,NTRFNONREF//BANKREF",
+			want: Transaction{Date: time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC), Amount: 150000, Reference: "BANKREF"},
+		},
+		{
+			in:   "250702RC75,5NTRFREV\nsupplementary details",
+			want: Transaction{Date: time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC), Amount: -75.5, Description: "supplementary details", Reference: "REV"},
+		},
+		{in: "250702X150000,00NTRFREF", wantErr: true},
+		{in: "250702C150000NTRFREF", wantErr: true},
+		{in: "251302C150000,00NTRFREF", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseStatementLine(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseStatementLine(%q) = %+v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseStatementLine(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseStatementLine(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseMT940Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "malformed statement line followed by a tag",
+			input:   ":20:X\n:25:1\n:61:garbage\n:86:desc\n",
+			wantErr: "invalid statement: line 3: malformed :61: line",
+		},
+		{
+			name:    "malformed statement line spanning lines before the trailer",
+			input:   ":20:X\n:61:garbage\nmore\n-}\n",
+			wantErr: "invalid statement: line 2: malformed :61: line",
+		},
+		{
+			name:    "malformed statement line at the end",
+			input:   ":20:X\n:25:1\n\n:61:garbage",
+			wantErr: "invalid statement: line 4: malformed :61: line",
+		},
+		{
+			name:    "tag without closing colon",
+			input:   ":20:X\n:61\n",
+			wantErr: "invalid statement: line 2: malformed tag",
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseMT940(strings.NewReader(tt.input))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+			t.Errorf("%s: got error %v, want it to start with %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package ical implements the subset of RFC 5545 (iCalendar) used by the API:
+// reading VEVENTs from imported holiday calendars and writing subscription feeds.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Event is a VEVENT
+type Event struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time // Exclusive, as in DTEND
+	AllDay  bool
+
+	// Only used when writing feeds
+	Description  string
+	Location     string
+	Status       string // TENTATIVE, CONFIRMED or CANCELLED
+	Sequence     int    // Must increase every time the event changes
+	LastModified time.Time
+}
+
+// Parse reads every VEVENT from an iCalendar stream
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var current *Event
+	for i, line := range lines {
+		name, params, value, ok := splitProperty(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			current = &Event{}
+		case name == "END" && value == "VEVENT":
+			if current == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN", i+1)
+			}
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: event %q has no DTSTART", i+1, current.Summary)
+			}
+			if current.End.IsZero() {
+				current.End = current.Start
+				if current.AllDay {
+					current.End = current.Start.AddDate(0, 0, 1)
+				}
+			}
+			events = append(events, *current)
+			current = nil
+		case current == nil:
+			continue
+		case name == "UID":
+			current.UID = value
+		case name == "SUMMARY":
+			current.Summary = unescapeText(value)
+		case name == "DTSTART", name == "DTEND":
+			t, allDay, err := parseDateTime(params, value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if name == "DTSTART" {
+				current.Start, current.AllDay = t, allDay
+			} else {
+				current.End = t
+			}
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("unterminated VEVENT %q", current.Summary)
+	}
+
+	return events, nil
+}
+
+// unfold joins continuation lines (lines starting with a space or tab)
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// splitProperty splits "NAME;PARAM=X:VALUE" into its parts
+func splitProperty(line string) (name string, params map[string]string, value string, ok bool) {
+	head, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", nil, "", false
+	}
+
+	segments := strings.Split(head, ";")
+	name = strings.ToUpper(segments[0])
+	params = make(map[string]string, len(segments)-1)
+	for _, segment := range segments[1:] {
+		if key, val, found := strings.Cut(segment, "="); found {
+			params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+		}
+	}
+
+	return name, params, value, true
+}
+
+func parseDateTime(params map[string]string, value string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.Parse("20060102", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+		}
+		return t, false, nil
+	}
+
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+	}
+	return t, false, nil
+}
+
+func unescapeText(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
@@ -0,0 +1,91 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %v", err)
+	}
+
+	input := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:nyepi-2025\r\n" +
+		"SUMMARY:Hari Suci Nyepi\\, Tahun Baru Saka\r\n" +
+		"DTSTART;VALUE=DATE:20250329\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:blackout-1\r\n" +
+		"SUMMARY:Wisuda \r\n" +
+		" periode II\r\n" +
+		"DTSTART;TZID=Asia/Jakarta:20250816T080000\r\n" +
+		"DTEND;TZID=Asia/Jakarta:20250816T120000\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:utc\r\n" +
+		"SUMMARY:Rapat\r\n" +
+		"DTSTART:20250901T020000Z\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	events, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	want := []Event{
+		{
+			UID:     "nyepi-2025",
+			Summary: "Hari Suci Nyepi, Tahun Baru Saka",
+			Start:   time.Date(2025, 3, 29, 0, 0, 0, 0, time.UTC),
+			End:     time.Date(2025, 3, 30, 0, 0, 0, 0, time.UTC), // All-day events without DTEND last one day
+			AllDay:  true,
+		},
+		{
+			UID:     "blackout-1",
+			Summary: "Wisuda periode II",
+			Start:   time.Date(2025, 8, 16, 8, 0, 0, 0, jakarta),
+			End:     time.Date(2025, 8, 16, 12, 0, 0, 0, jakarta),
+		},
+		{
+			UID:     "utc",
+			Summary: "Rapat",
+			Start:   time.Date(2025, 9, 1, 2, 0, 0, 0, time.UTC),
+			End:     time.Date(2025, 9, 1, 2, 0, 0, 0, time.UTC),
+		},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i := range want {
+		got := events[i]
+		if got.UID != want[i].UID || got.Summary != want[i].Summary || got.AllDay != want[i].AllDay ||
+			!got.Start.Equal(want[i].Start) || !got.End.Equal(want[i].End) {
+			t.Errorf("event %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "end without begin", input: "END:VEVENT\n", wantErr: "line 1: END:VEVENT without BEGIN"},
+		{name: "no start", input: "BEGIN:VEVENT\nSUMMARY:X\nEND:VEVENT\n", wantErr: `line 3: event "X" has no DTSTART`},
+		{name: "invalid date", input: "BEGIN:VEVENT\nDTSTART;VALUE=DATE:2025XX01\nEND:VEVENT\n", wantErr: `line 2: invalid date "2025XX01"`},
+		{name: "unterminated", input: "BEGIN:VEVENT\nSUMMARY:X\n", wantErr: `unterminated VEVENT "X"`},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.input))
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	utcLayout  = "20060102T150405Z"
+	dateLayout = "20060102"
+
+	// maxLineOctets is the RFC 5545 line length limit, excluding CRLF
+	maxLineOctets = 75
+)
+
+// Calendar is a VCALENDAR written as a subscription feed
+type Calendar struct {
+	Name   string
+	ProdID string
+	Events []Event
+}
+
+// Write serializes the calendar with CRLF line endings and folded long lines
+func Write(w io.Writer, cal Calendar) error {
+	bw := bufio.NewWriter(w)
+	now := time.Now().UTC().Format(utcLayout)
+
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:"+cal.ProdID)
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	writeLine(bw, "METHOD:PUBLISH")
+	if cal.Name != "" {
+		writeLine(bw, "X-WR-CALNAME:"+escapeText(cal.Name))
+	}
+
+	for _, e := range cal.Events {
+		writeLine(bw, "BEGIN:VEVENT")
+		writeLine(bw, "UID:"+e.UID)
+		writeLine(bw, "DTSTAMP:"+now)
+		if e.AllDay {
+			writeLine(bw, "DTSTART;VALUE=DATE:"+e.Start.Format(dateLayout))
+			writeLine(bw, "DTEND;VALUE=DATE:"+e.End.Format(dateLayout))
+		} else {
+			writeLine(bw, "DTSTART:"+e.Start.UTC().Format(utcLayout))
+			writeLine(bw, "DTEND:"+e.End.UTC().Format(utcLayout))
+		}
+		writeLine(bw, "SUMMARY:"+escapeText(e.Summary))
+		if e.Location != "" {
+			writeLine(bw, "LOCATION:"+escapeText(e.Location))
+		}
+		if e.Description != "" {
+			writeLine(bw, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		if e.Status != "" {
+			writeLine(bw, "STATUS:"+e.Status)
+		}
+		writeLine(bw, fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+		if !e.LastModified.IsZero() {
+			writeLine(bw, "LAST-MODIFIED:"+e.LastModified.UTC().Format(utcLayout))
+		}
+		writeLine(bw, "END:VEVENT")
+	}
+
+	writeLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+// writeLine folds lines longer than 75 octets without splitting UTF-8 sequences. The
+// space that starts a continuation line counts towards its limit.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line + "\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
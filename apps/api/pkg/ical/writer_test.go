@@ -0,0 +1,117 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestWriteLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		lines int
+	}{
+		{name: "short", line: "SUMMARY:TOEFL ITP", lines: 1},
+		{name: "exactly the limit", line: strings.Repeat("a", 75), lines: 1},
+		{name: "one over the limit", line: strings.Repeat("a", 76), lines: 2},
+		{name: "continuation fills up", line: strings.Repeat("a", 75+74), lines: 2},
+		{name: "continuation one over", line: strings.Repeat("a", 75+74+1), lines: 3},
+		{name: "two-byte runes across the cut", line: "DESCRIPTION:" + strings.Repeat("é", 100), lines: 3},
+		{name: "three-byte runes across the cut", line: "SUMMARY:" + strings.Repeat("漢", 60), lines: 3},
+		{name: "four-byte runes across the cut", line: "LOCATION:a" + strings.Repeat("🎓", 40), lines: 3},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		writeLine(w, tt.line)
+		w.Flush()
+
+		out := buf.String()
+		if !strings.HasSuffix(out, "\r\n") {
+			t.Errorf("%s: output %q does not end with CRLF", tt.name, out)
+			continue
+		}
+		physical := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+		if len(physical) != tt.lines {
+			t.Errorf("%s: got %d lines, want %d", tt.name, len(physical), tt.lines)
+		}
+
+		var unfolded strings.Builder
+		for i, line := range physical {
+			if len(line) > maxLineOctets {
+				t.Errorf("%s: line %d is %d octets, want at most %d", tt.name, i+1, len(line), maxLineOctets)
+			}
+			if i > 0 {
+				if !strings.HasPrefix(line, " ") {
+					t.Errorf("%s: continuation line %d %q does not start with a space", tt.name, i+1, line)
+					continue
+				}
+				line = line[1:]
+			}
+			if !utf8.ValidString(line) {
+				t.Errorf("%s: line %d %q splits a UTF-8 sequence", tt.name, i+1, line)
+			}
+			unfolded.WriteString(line)
+		}
+		if unfolded.String() != tt.line {
+			t.Errorf("%s: unfolded output = %q, want %q", tt.name, unfolded.String(), tt.line)
+		}
+	}
+}
+
+func TestWriteParse(t *testing.T) {
+	start := time.Date(2025, 8, 16, 1, 0, 0, 0, time.UTC)
+	cal := Calendar{
+		Name:   "TOEFL ITP; Semarang, Ungaran",
+		ProdID: "-//UNW//TOEFL//EN",
+		Events: []Event{
+			{
+				UID:         "schedule-1@toefl",
+				Summary:     "TOEFL ITP, Gedung " + strings.Repeat("Rektorat ", 12),
+				Start:       start,
+				End:         start.Add(2 * time.Hour),
+				Location:    "Ruang 301",
+				Description: "Bawa kartu ujian;\nDatang 30 menit lebih awal",
+				Status:      "CONFIRMED",
+				Sequence:    2,
+			},
+			{
+				UID:     "holiday-1@toefl",
+				Summary: "Hari Kemerdekaan",
+				Start:   time.Date(2025, 8, 17, 0, 0, 0, 0, time.UTC),
+				End:     time.Date(2025, 8, 18, 0, 0, 0, 0, time.UTC),
+				AllDay:  true,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, cal); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	for i, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line %d is %d octets, want at most %d", i+1, len(line), maxLineOctets)
+		}
+	}
+
+	events, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if len(events) != len(cal.Events) {
+		t.Fatalf("got %d events, want %d", len(events), len(cal.Events))
+	}
+	for i, want := range cal.Events {
+		got := events[i]
+		if got.UID != want.UID || got.Summary != want.Summary || got.AllDay != want.AllDay ||
+			!got.Start.Equal(want.Start) || !got.End.Equal(want.End) {
+			t.Errorf("event %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Package lottery implements the seeded draw used to allocate the seats of
+// oversubscribed sessions. A draw is deterministic: the same seed and the same
+// number of entries always produce the same order, so a stored seed lets anyone
+// reproduce a result.
+package lottery
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	mrand "math/rand/v2"
+)
+
+// Algorithm identifies the draw procedure; it is stored with every draw and must
+// change if Draw ever changes.
+const Algorithm = "pcg-dxsm-fisher-yates-v1"
+
+// NewSeed returns a random 128-bit seed, hex encoded
+func NewSeed() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate seed: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Draw returns a permutation of 0..n-1 derived from the seed. Callers order their
+// entries by a stable key before applying it.
+func Draw(seed string, n int) ([]int, error) {
+	b, err := hex.DecodeString(seed)
+	if err != nil || len(b) != 16 {
+		return nil, fmt.Errorf("invalid seed: expected 32 hex characters")
+	}
+	src := mrand.NewPCG(binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:]))
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j := int(uniform(src, uint64(i+1)))
+		order[i], order[j] = order[j], order[i]
+	}
+
+	return order, nil
+}
+
+// uniform returns an unbiased value in [0, n) by rejecting the values that would
+// make the modulo uneven
+func uniform(src *mrand.PCG, n uint64) uint64 {
+	threshold := -n % n // 2^64 mod n
+	for {
+		if v := src.Uint64(); v >= threshold {
+			return v % n
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package lottery
+
+import (
+	"math"
+	mrand "math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// The orders below were produced by the pcg-dxsm-fisher-yates-v1 algorithm. Stored
+// draws are verified by re-running them, so if this test fails the change to Draw
+// needs a new Algorithm name, not new expected values.
+func TestDrawGolden(t *testing.T) {
+	tests := []struct {
+		seed string
+		n    int
+		want []int
+	}{
+		{seed: "000102030405060708090a0b0c0d0e0f", n: 10, want: []int{6, 7, 4, 2, 0, 1, 9, 5, 8, 3}},
+		{
+			seed: "d3b07384d113edec49eaa6238ad5ff00",
+			n:    25,
+			want: []int{9, 13, 23, 5, 15, 6, 12, 22, 18, 19, 8, 2, 11, 14, 0, 16, 3, 24, 7, 4, 20, 17, 10, 21, 1},
+		},
+		{seed: "ffffffffffffffffffffffffffffffff", n: 3, want: []int{0, 1, 2}},
+		{seed: "D3B07384D113EDEC49EAA6238AD5FF00", n: 1, want: []int{0}},
+		{seed: "000102030405060708090a0b0c0d0e0f", n: 0, want: []int{}},
+	}
+
+	if Algorithm != "pcg-dxsm-fisher-yates-v1" {
+		t.Fatalf("Algorithm = %q; update the expected orders for the new algorithm", Algorithm)
+	}
+	for _, tt := range tests {
+		got, err := Draw(tt.seed, tt.n)
+		if err != nil {
+			t.Errorf("Draw(%q, %d) returned error: %v", tt.seed, tt.n, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Draw(%q, %d) = %v, want %v", tt.seed, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestDrawIsPermutation(t *testing.T) {
+	seed, err := NewSeed()
+	if err != nil {
+		t.Fatalf("NewSeed returned error: %v", err)
+	}
+
+	order, err := Draw(seed, 500)
+	if err != nil {
+		t.Fatalf("Draw returned error: %v", err)
+	}
+	again, err := Draw(seed, 500)
+	if err != nil {
+		t.Fatalf("Draw returned error: %v", err)
+	}
+	if !slices.Equal(order, again) {
+		t.Errorf("Draw(%q, 500) gave different orders for the same seed", seed)
+	}
+
+	sorted := slices.Sorted(slices.Values(order))
+	for i, v := range sorted {
+		if v != i {
+			t.Fatalf("Draw(%q, 500) is not a permutation of 0..499: %v", seed, order)
+		}
+	}
+}
+
+func TestDrawInvalidSeed(t *testing.T) {
+	for _, seed := range []string{"", "0001", "000102030405060708090a0b0c0d0e0f00", "zz0102030405060708090a0b0c0d0e0f"} {
+		if _, err := Draw(seed, 3); err == nil {
+			t.Errorf("Draw(%q, 3) succeeded, want an error", seed)
+		}
+	}
+}
+
+func TestUniform(t *testing.T) {
+	src := mrand.NewPCG(1, 2)
+	for _, n := range []uint64{1, 2, 3, 7, 10, 1000, 1<<63 + 1, math.MaxUint64} {
+		for i := 0; i < 2000; i++ {
+			if v := uniform(src, n); v >= n {
+				t.Fatalf("uniform(%d) = %d, want a value below %d", n, v, n)
+			}
+		}
+	}
+
+	// Every value of a small range comes up
+	seen := make([]bool, 7)
+	for i := 0; i < 1000; i++ {
+		seen[uniform(src, 7)] = true
+	}
+	for v, ok := range seen {
+		if !ok {
+			t.Errorf("uniform(7) never returned %d in 1000 draws", v)
+		}
+	}
+}
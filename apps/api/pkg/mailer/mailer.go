@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+)
+
+// Mailer sends plain-text email
+type Mailer interface {
+	Send(to []string, subject, body string) error
+}
+
+// SMTPMailer delivers mail through an SMTP relay using PLAIN auth
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPMailer{addr: host + ":" + port, auth: auth, from: from}
+}
+
+func (m *SMTPMailer) Send(to []string, subject, body string) error {
+	msg := "From: " + m.from + "\r\n" +
+		"To: " + strings.Join(to, ", ") + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + body
+
+	if err := smtp.SendMail(m.addr, m.auth, m.from, to, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// LogMailer writes mail to the log; used when SMTP is not configured
+type LogMailer struct{}
+
+func (LogMailer) Send(to []string, subject, body string) error {
+	log.Printf("email to %s: %s\n%s", strings.Join(to, ", "), subject, body)
+	return nil
+}
@@ -0,0 +1,74 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // Needed to decode photos before embedding them in a page
+	_ "image/png"
+)
+
+// Image is a picture that can be drawn on pages, embedded once per document
+type Image struct {
+	width, height int
+	colorSpace    string
+	filter        string
+	data          []byte
+}
+
+// NewImage reads a JPEG or PNG. JPEGs are embedded as they are; other images are
+// converted to RGB and compressed.
+func NewImage(data []byte) (*Image, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+
+	if format == "jpeg" {
+		colorSpace := "DeviceRGB"
+		switch config.ColorModel {
+		case color.GrayModel:
+			colorSpace = "DeviceGray"
+		case color.CMYKModel:
+			// Adobe CMYK JPEGs are stored inverted; re-encode them as RGB instead
+			return encode(data)
+		}
+		return &Image{width: config.Width, height: config.Height, colorSpace: colorSpace, filter: "DCTDecode", data: data}, nil
+	}
+
+	return encode(data)
+}
+
+// NewImageFrom embeds an image that was drawn in memory, such as a QR code
+func NewImageFrom(img image.Image) *Image {
+	b := img.Bounds()
+	pixels := make([]byte, 0, b.Dx()*b.Dy()*3)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, _ := img.At(x, y).RGBA()
+			pixels = append(pixels, byte(r>>8), byte(g>>8), byte(bl>>8))
+		}
+	}
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(pixels)
+	zw.Close()
+
+	return &Image{width: b.Dx(), height: b.Dy(), colorSpace: "DeviceRGB", filter: "FlateDecode", data: compressed.Bytes()}
+}
+
+func encode(data []byte) (*Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+	return NewImageFrom(img), nil
+}
+
+// Size returns the image's width and height in pixels
+func (img *Image) Size() (int, int) {
+	return img.width, img.height
+}
@@ -0,0 +1,244 @@
+// Package pdf writes simple PDF 1.4 documents: text in the standard Helvetica
+// fonts, lines, rectangles and images. Coordinates are in points with the origin
+// at the top-left corner of an A4 page; text is positioned by its baseline.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// A4 page size in points
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Font is one of the standard fonts every PDF reader provides
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+var fontNames = [...]string{"Helvetica", "Helvetica-Bold"}
+
+// Document is a PDF under construction
+type Document struct {
+	Title   string
+	Subject string
+	pages   []*Page
+	images  []*Image
+}
+
+// New starts an empty document
+func New(title string) *Document {
+	return &Document{Title: title}
+}
+
+// Page is one A4 page; drawing operations are appended to its content stream
+type Page struct {
+	doc     *Document
+	content bytes.Buffer
+}
+
+// AddPage appends a blank page
+func (d *Document) AddPage() *Page {
+	p := &Page{doc: d}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Text draws s with its baseline starting at (x, y)
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		font+1, num(size), num(x), num(PageHeight-y), escape(s))
+}
+
+// TextRight draws s so that it ends at x
+func (p *Page) TextRight(x, y float64, font Font, size float64, s string) {
+	p.Text(x-TextWidth(font, size, s), y, font, size, s)
+}
+
+// TextCenter draws s centred on x
+func (p *Page) TextCenter(x, y float64, font Font, size float64, s string) {
+	p.Text(x-TextWidth(font, size, s)/2, y, font, size, s)
+}
+
+// Paragraph draws s wrapped to width and returns the baseline after the last line
+func (p *Page) Paragraph(x, y, width float64, font Font, size, leading float64, s string) float64 {
+	for _, line := range Wrap(font, size, s, width) {
+		p.Text(x, y, font, size, line)
+		y += leading
+	}
+	return y
+}
+
+// Line strokes a line of the given width
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n",
+		num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// Rect strokes a rectangle whose top-left corner is (x, y)
+func (p *Page) Rect(x, y, w, h, lineWidth float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s %s %s re S\n",
+		num(lineWidth), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// FillRect fills a rectangle with a grey level from 0 (black) to 1 (white)
+func (p *Page) FillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(&p.content, "q %s g %s %s %s %s re f Q\n",
+		num(gray), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// Image draws img scaled into the box whose top-left corner is (x, y)
+func (p *Page) Image(img *Image, x, y, w, h float64) {
+	id := slices.Index(p.doc.images, img) + 1
+	if id == 0 {
+		p.doc.images = append(p.doc.images, img)
+		id = len(p.doc.images)
+	}
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /Im%d Do Q\n",
+		num(w), num(h), num(x), num(PageHeight-y-h), id)
+}
+
+// Write serializes the document
+func (d *Document) Write(w io.Writer) error {
+	pw := &writer{}
+	pw.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Object numbers: 1 catalog, 2 page tree, 3 info, 4-5 fonts, then images,
+	// then a page and its content stream for every page
+	const firstFont = 4
+	firstImage := firstFont + len(fontNames)
+	firstPage := firstImage + len(d.images)
+
+	pw.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	pw.object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	pw.object(3, fmt.Sprintf("<< /Title (%s) /Subject (%s) /Producer (UNW Language Center) /CreationDate (D:%s) >>",
+		escape(d.Title), escape(d.Subject), time.Now().UTC().Format("20060102150405Z")))
+
+	for i, name := range fontNames {
+		pw.object(firstFont+i, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+	}
+
+	for i, img := range d.images {
+		pw.stream(firstImage+i, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8 /Filter /%s",
+			img.width, img.height, img.colorSpace, img.filter), img.data)
+	}
+
+	var resources strings.Builder
+	resources.WriteString("<< /Font <<")
+	for i := range fontNames {
+		fmt.Fprintf(&resources, " /F%d %d 0 R", i+1, firstFont+i)
+	}
+	resources.WriteString(" >>")
+	if len(d.images) > 0 {
+		resources.WriteString(" /XObject <<")
+		for i := range d.images {
+			fmt.Fprintf(&resources, " /Im%d %d 0 R", i+1, firstImage+i)
+		}
+		resources.WriteString(" >>")
+	}
+	resources.WriteString(" >>")
+
+	for i, page := range d.pages {
+		n := firstPage + 2*i
+		pw.object(n, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R >>",
+			num(PageWidth), num(PageHeight), resources.String(), n+1))
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		zw.Write(page.content.Bytes())
+		zw.Close()
+		pw.stream(n+1, "/Filter /FlateDecode", compressed.Bytes())
+	}
+
+	xref := pw.buf.Len()
+	fmt.Fprintf(&pw.buf, "xref\n0 %d\n0000000000 65535 f \n", len(pw.offsets)+1)
+	for i := 1; i <= len(pw.offsets); i++ {
+		fmt.Fprintf(&pw.buf, "%010d 00000 n \n", pw.offsets[i])
+	}
+	fmt.Fprintf(&pw.buf, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(pw.offsets)+1, xref)
+
+	_, err := w.Write(pw.buf.Bytes())
+	return err
+}
+
+// Bytes serializes the document into memory
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writer records the offset of every object for the cross-reference table
+type writer struct {
+	buf     bytes.Buffer
+	offsets map[int]int
+}
+
+func (w *writer) object(n int, body string) {
+	w.begin(n)
+	fmt.Fprintf(&w.buf, "%s\nendobj\n", body)
+}
+
+func (w *writer) stream(n int, dict string, data []byte) {
+	w.begin(n)
+	fmt.Fprintf(&w.buf, "<< %s /Length %d >>\nstream\n", dict, len(data))
+	w.buf.Write(data)
+	w.buf.WriteString("\nendstream\nendobj\n")
+}
+
+func (w *writer) begin(n int) {
+	if w.offsets == nil {
+		w.offsets = make(map[int]int)
+	}
+	w.offsets[n] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n", n)
+}
+
+// num formats a coordinate without trailing zeros
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// escape encodes s in WinAnsi and escapes the string delimiters. Characters outside
+// Latin-1 are replaced with "?".
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r < 0x20 || r > 0xff || (r >= 0x7f && r < 0xa0):
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
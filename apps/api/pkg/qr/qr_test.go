@@ -0,0 +1,202 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEncodeVersion(t *testing.T) {
+	tests := []struct {
+		length  int
+		version int
+	}{
+		{length: 1, version: 1},
+		{length: 14, version: 1},
+		{length: 15, version: 2},
+		{length: 62, version: 4},
+		{length: 63, version: 5},
+		{length: 213, version: 10},
+	}
+
+	for _, tt := range tests {
+		code, err := Encode(bytes.Repeat([]byte("a"), tt.length))
+		if err != nil {
+			t.Errorf("Encode(%d bytes) returned error: %v", tt.length, err)
+			continue
+		}
+		if code.Version != tt.version {
+			t.Errorf("Encode(%d bytes) version = %d, want %d", tt.length, code.Version, tt.version)
+		}
+		if want := 4*tt.version + 17; code.Size() != want {
+			t.Errorf("Encode(%d bytes) size = %d, want %d", tt.length, code.Size(), want)
+		}
+	}
+
+	if _, err := Encode(bytes.Repeat([]byte("a"), 214)); err == nil {
+		t.Error("Encode(214 bytes) succeeded, want an error")
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	inputs := []string{
+		"",
+		"A",
+		"https://toefl.unw.ac.id/verify/ABCD-EFGH-JKLM",
+		"1024.7f3a9c0e5b1d2f4a6c8e0b2d4f6a8c0e1b3d5f7a9c2e4b6d8f0a1c3e5b7d9f",
+		strings.Repeat("0123456789", 21),
+	}
+
+	for _, input := range inputs {
+		code, err := Encode([]byte(input))
+		if err != nil {
+			t.Errorf("Encode(%q) returned error: %v", input, err)
+			continue
+		}
+		got, err := decode(code)
+		if err != nil {
+			t.Errorf("decode(Encode(%q)) returned error: %v", input, err)
+			continue
+		}
+		if string(got) != input {
+			t.Errorf("decode(Encode(%q)) = %q", input, got)
+		}
+	}
+}
+
+func TestEncodeFinderPatterns(t *testing.T) {
+	code, err := Encode([]byte("finder"))
+	if err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+
+	n := code.Size()
+	for _, corner := range [][2]int{{0, 0}, {n - 7, 0}, {0, n - 7}} {
+		for dy := 0; dy < 7; dy++ {
+			for dx := 0; dx < 7; dx++ {
+				ring := max(abs(dx-3), abs(dy-3))
+				if want := ring != 2; code.Dark(corner[0]+dx, corner[1]+dy) != want {
+					t.Errorf("finder at %v: module (%d, %d) dark = %v, want %v", corner, dx, dy, !want, want)
+				}
+			}
+		}
+	}
+}
+
+// decode reads a symbol back the way a scanner would: it finds the mask from the
+// format information, unmasks, collects the codewords in placement order,
+// de-interleaves them, checks each block's error correction and parses the
+// byte mode segment.
+func decode(c *Code) ([]byte, error) {
+	format := 0
+	for i := 0; i <= 5; i++ {
+		format |= bitAt(c, 8, i) << i
+	}
+	format |= bitAt(c, 8, 7) << 6
+	format |= bitAt(c, 8, 8) << 7
+	format |= bitAt(c, 7, 8) << 8
+	for i := 9; i < 15; i++ {
+		format |= bitAt(c, 14-i, 8) << i
+	}
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if formatBits(m) == format {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		return nil, fmt.Errorf("format information %015b matches no mask at level M", format)
+	}
+
+	// Unmask a copy so the symbol under test is left untouched
+	unmasked := &Code{Version: c.Version, size: c.size, function: c.function}
+	for _, row := range c.modules {
+		unmasked.modules = append(unmasked.modules, append([]bool(nil), row...))
+	}
+	unmasked.applyMask(mask)
+
+	var bits bitBuffer
+	upward := true
+	for right := c.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for i := 0; i < c.size; i++ {
+			y := i
+			if upward {
+				y = c.size - 1 - i
+			}
+			for _, x := range []int{right, right - 1} {
+				if !c.function[y][x] {
+					bits = append(bits, unmasked.modules[y][x])
+				}
+			}
+		}
+		upward = !upward
+	}
+	codewords := bits.bytes()
+
+	spec := blocks[c.Version]
+	dataBlocks := make([][]byte, len(spec.data))
+	next := 0
+	for i := 0; i < spec.data[len(spec.data)-1]; i++ {
+		for b, n := range spec.data {
+			if i < n {
+				dataBlocks[b] = append(dataBlocks[b], codewords[next])
+				next++
+			}
+		}
+	}
+	divisor := rsDivisor(spec.ecc)
+	var data []byte
+	for b, block := range dataBlocks {
+		ecc := make([]byte, spec.ecc)
+		for i := range ecc {
+			ecc[i] = codewords[next+i*len(spec.data)+b]
+		}
+		if want := rsRemainder(block, divisor); !bytes.Equal(ecc, want) {
+			return nil, fmt.Errorf("block %d error correction = %x, want %x", b, ecc, want)
+		}
+		data = append(data, block...)
+	}
+
+	var stream bitBuffer
+	for _, b := range data {
+		stream.append(int(b), 8)
+	}
+	read := func(n int) int {
+		v := 0
+		for i := 0; i < n; i++ {
+			v <<= 1
+			if stream[i] {
+				v |= 1
+			}
+		}
+		stream = stream[n:]
+		return v
+	}
+	if mode := read(4); mode != 0b0100 {
+		return nil, fmt.Errorf("mode = %04b, want byte mode", mode)
+	}
+	countBits := 8
+	if c.Version >= 10 {
+		countBits = 16
+	}
+	length := read(countBits)
+	if 8*length > len(stream) {
+		return nil, fmt.Errorf("length %d exceeds the symbol", length)
+	}
+	out := make([]byte, length)
+	for i := range out {
+		out[i] = byte(read(8))
+	}
+	return out, nil
+}
+
+func bitAt(c *Code, x, y int) int {
+	if c.Dark(x, y) {
+		return 1
+	}
+	return 0
+}
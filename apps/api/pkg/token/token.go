@@ -0,0 +1,93 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token has expired")
+)
+
+// header is fixed: only HMAC-SHA256 signed JWTs are issued and accepted
+var header = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Claims is the JWT payload used across the API
+type Claims struct {
+	Subject   string `json:"sub"`
+	Name      string `json:"name,omitempty"`
+	Role      string `json:"role,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+// Sign encodes the claims as an HS256 JWT
+func Sign(claims Claims, secret string) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + signature(unsigned, secret), nil
+}
+
+// Parse verifies the signature and expiry of an HS256 JWT and returns its claims
+func Parse(raw, secret string) (*Claims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 || parts[0] != header {
+		return nil, ErrInvalidToken
+	}
+
+	expected := signature(parts[0]+"."+parts[1], secret)
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.ExpiresAt != 0 && time.Now().Unix() > claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+
+	return &claims, nil
+}
+
+func signature(unsigned, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignValue appends a truncated HMAC-SHA256 signature to value, short enough to print
+// in a QR code. The signature is always the last dot-separated part.
+func SignValue(value, secret string) string {
+	return value + "." + signature(value, secret)[:22]
+}
+
+// VerifyValue checks a string produced by SignValue and returns the signed value
+func VerifyValue(signed, secret string) (string, error) {
+	i := strings.LastIndex(signed, ".")
+	if i < 0 {
+		return "", ErrInvalidToken
+	}
+	value := signed[:i]
+	if !hmac.Equal([]byte(SignValue(value, secret)), []byte(signed)) {
+		return "", ErrInvalidToken
+	}
+	return value, nil
+}
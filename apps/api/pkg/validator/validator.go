@@ -19,6 +19,7 @@ func New() *validator.Validate {
 
 func RegisterCustomValidators(v *validator.Validate) {
 	v.RegisterValidation("notpastdate", validateNotPastDate)
+	v.RegisterValidation("clocktime", validateClockTime)
 }
 
 func validateNotPastDate(fl validator.FieldLevel) bool {
@@ -32,3 +33,9 @@ func validateNotPastDate(fl validator.FieldLevel) bool {
 
 	return !input.Before(now)
 }
+
+// validateClockTime checks a 24-hour HH:MM string, e.g. 08:00 or 13:30
+func validateClockTime(fl validator.FieldLevel) bool {
+	_, err := time.Parse("15:04", fl.Field().String())
+	return err == nil
+}